@@ -6,14 +6,65 @@ package git
 
 // Blame contains information of a Git file blame.
 type Blame struct {
+	// The commit of each record, which is only loaded by Repository.BlameFile.
 	lines []*Commit
+
+	// The blame information of each line.
+	records []*BlameLine
+	repo    *Repository
 }
 
 // Line returns the commit by given line number (1-based). It returns nil when
 // no such line.
 func (b *Blame) Line(i int) *Commit {
-	if i <= 0 || len(b.lines) < i {
+	if len(b.records) == 0 {
+		return nil
+	}
+
+	// Records are consecutive lines in the order of line numbers.
+	i -= b.records[0].Number
+	if i < 0 || len(b.records) <= i {
+		return nil
+	}
+	if b.lines != nil {
+		return b.lines[i]
+	}
+
+	c, err := b.repo.CatFileCommit(b.records[i].CommitID.String())
+	if err != nil {
 		return nil
 	}
-	return b.lines[i-1]
+	return c
+}
+
+// Lines returns the blame information of each line in the order of line
+// numbers.
+func (b *Blame) Lines() []*BlameLine {
+	return b.records
+}
+
+// BlameLine is the blame information of a line.
+type BlameLine struct {
+	// The line number (1-based) in the file.
+	Number int
+	// The ID of the commit that last changed the line.
+	CommitID *SHA1
+	// The author of the commit, which is shared by all lines of the same commit.
+	Author *Signature
+	// The path of the file in the commit, which differs from the blamed file
+	// when the file was renamed or the line was moved from another file.
+	Path string
+	// The content of the line without the trailing newline.
+	Content string
+}
+
+// BlameChunk is a range of consecutive lines that are last changed by the same
+// commit.
+type BlameChunk struct {
+	// The line number (1-based) of the first line in the chunk.
+	StartLine int
+	// The number of lines in the chunk.
+	NumLines int
+	// The commit that last changed the lines.
+	Commit *Commit
 }
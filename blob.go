@@ -18,7 +18,7 @@ type Blob struct {
 // can be very slow and memory consuming for huge content.
 func (b *Blob) Bytes() ([]byte, error) {
 	stdout := new(bytes.Buffer)
-	stderr := new(bytes.Buffer)
+	stderr := newTailBuffer(stderrLimit)
 
 	// Preallocate memory to save ~50% memory usage on big files.
 	stdout.Grow(int(b.Size()))
@@ -64,7 +64,10 @@ func (c *Command) AddArgs(args ...string) *Command {
 	return c
 }
 
-// AddEnvs appends given environment variables to the command.
+// AddEnvs appends given environment variables to the command. The environment
+// of the current process is always inherited, e.g. GIT_OBJECT_DIRECTORY and
+// GIT_ALTERNATE_OBJECT_DIRECTORIES that Git sets for the pre-receive hook to
+// read objects from the quarantine directory.
 func (c *Command) AddEnvs(envs ...string) *Command {
 	c.envs = append(c.envs, envs...)
 	return c
@@ -264,6 +267,59 @@ func (c *Command) RunInDirPipelineWithTimeout(timeout time.Duration, stdout, std
 	return c.RunInDirPipeline(stdout, stderr, dir)
 }
 
+// commandReader is the reader of stdout of a running command.
+type commandReader struct {
+	*io.PipeReader
+	cancel context.CancelFunc
+	done   <-chan struct{}
+}
+
+// Close stops the command if it is still running and waits for it to exit.
+func (r *commandReader) Close() error {
+	r.cancel()
+	err := r.PipeReader.Close()
+	<-r.done
+	return err
+}
+
+// RunInDirReader executes the command in given directory and returns a reader
+// of its stdout, so that large output can be consumed incrementally without
+// being buffered in memory. The error of the command (combined with stderr) is
+// returned by Read in place of io.EOF once the command exits. The reader must
+// be closed, which stops the command if it has not finished yet. Because the
+// command is blocked on writing stdout until it is read, the timeout of the
+// command covers the time taken by the caller to consume the output.
+func (c *Command) RunInDirReader(dir string) io.ReadCloser {
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+
+	cmd := *c
+	cmd.ctx = ctx
+
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		stderr := newTailBuffer(stderrLimit)
+		err := cmd.RunInDirPipeline(pw, stderr, dir)
+		if err != nil {
+			_ = pw.CloseWithError(concatenateError(err, stderr.String()))
+			return
+		}
+		_ = pw.Close()
+	}()
+
+	return &commandReader{
+		PipeReader: pr,
+		cancel:     cancel,
+		done:       done,
+	}
+}
+
 // RunInDirWithTimeout executes the command in given directory and timeout
 // duration. It returns stdout in []byte and error (combined with stderr).
 //
@@ -280,7 +336,7 @@ func (c *Command) RunInDirWithTimeout(timeout time.Duration, dir string) ([]byte
 // duration. It returns stdout and error (combined with stderr).
 func (c *Command) RunInDir(dir string) ([]byte, error) {
 	stdout := new(bytes.Buffer)
-	stderr := new(bytes.Buffer)
+	stderr := newTailBuffer(stderrLimit)
 	if err := c.RunInDirPipeline(stdout, stderr, dir); err != nil {
 		return nil, concatenateError(err, stderr.String())
 	}
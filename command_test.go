@@ -5,6 +5,7 @@
 package git
 
 import (
+	"io/ioutil"
 	"testing"
 	"time"
 
@@ -63,3 +64,133 @@ func TestCommand_RunWithTimeout(t *testing.T) {
 	_, err := NewCommand("version").WithTimeout(time.Nanosecond).Run()
 	assert.Equal(t, ErrExecTimeout, err)
 }
+
+func TestCommand_RunInDirReader(t *testing.T) {
+	t.Run("read all", func(t *testing.T) {
+		stdout := NewCommand("rev-parse", "--is-inside-git-dir").RunInDirReader(testrepo.Path())
+		defer func() { _ = stdout.Close() }()
+
+		p, err := ioutil.ReadAll(stdout)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "true\n", string(p))
+	})
+
+	t.Run("error with stderr", func(t *testing.T) {
+		stdout := NewCommand("rev-parse", "--verify", "404").RunInDirReader(testrepo.Path())
+		defer func() { _ = stdout.Close() }()
+
+		_, err := ioutil.ReadAll(stdout)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "Needed a single revision")
+	})
+
+	t.Run("close without reading", func(t *testing.T) {
+		// The command is blocked on writing stdout until it is stopped.
+		stdout := NewCommand("log", "--all", "--patch").RunInDirReader(testrepo.Path())
+
+		done := make(chan struct{})
+		go func() {
+			_ = stdout.Close()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for the command to be stopped")
+		}
+	})
+
+	t.Run("timeout covers reading", func(t *testing.T) {
+		// The command is blocked on writing stdout until it is read, so a slow
+		// reader makes the command time out.
+		stdout := NewCommand("log", "--all", "--patch").
+			WithTimeout(100 * time.Millisecond).
+			RunInDirReader(testrepo.Path())
+		defer func() { _ = stdout.Close() }()
+
+		time.Sleep(500 * time.Millisecond)
+		_, err := ioutil.ReadAll(stdout)
+		assert.Equal(t, ErrExecTimeout, err)
+	})
+}
+
+func TestTailBuffer(t *testing.T) {
+	tests := []struct {
+		limit  int
+		writes []string
+		expStr string
+	}{
+		{
+			limit:  0,
+			writes: []string{"hello", " ", "world"},
+			expStr: "hello world",
+		},
+		{
+			limit:  5,
+			writes: []string{"hel", "lo"},
+			expStr: "hello",
+		},
+		{
+			limit:  5,
+			writes: []string{"hello", " ", "world"},
+			expStr: "world",
+		},
+		{
+			limit:  5,
+			writes: []string{"hi", "hello world"},
+			expStr: "world",
+		},
+		{
+			limit:  5,
+			writes: []string{"hello wor", "ld"},
+			expStr: "world",
+		},
+	}
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			b := newTailBuffer(test.limit)
+			for _, w := range test.writes {
+				n, err := b.Write([]byte(w))
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, len(w), n)
+			}
+			assert.Equal(t, test.expStr, b.String())
+		})
+	}
+}
+
+func TestCommand_RunInDir_stderr(t *testing.T) {
+	_, err := NewCommand("rev-parse", "--verify", "404").RunInDir(testrepo.Path())
+	cmdErr, ok := err.(*CommandError)
+	if !ok {
+		t.Fatalf("expect *CommandError but got %T", err)
+	}
+	assert.Equal(t, "exit status 128", cmdErr.Err.Error())
+	assert.Equal(t, "fatal: Needed a single revision\n", cmdErr.Stderr)
+	assert.Equal(t, "exit status 128 - fatal: Needed a single revision\n", err.Error())
+
+	defer SetStderrLimit(stderrLimit)
+	SetStderrLimit(9)
+
+	_, err = NewCommand("rev-parse", "--verify", "404").RunInDir(testrepo.Path())
+	assert.Equal(t, "revision\n", err.(*CommandError).Stderr)
+}
+
+func TestIsExitCode(t *testing.T) {
+	// Exit code 1 without any error output.
+	_, err := NewCommand("config", "--get", "core.notexist").RunInDir(testrepo.Path())
+	assert.True(t, isExitCode(err, 1))
+	assert.False(t, isExitCode(err, 128))
+
+	// Exit code 1 with error output.
+	_, err = NewCommand("config", "--get", "404").RunInDir(testrepo.Path())
+	assert.False(t, isExitCode(err, 1))
+
+	assert.False(t, isExitCode(nil, 1))
+	assert.False(t, isExitCode(ErrExecTimeout, 1))
+}
@@ -23,6 +23,12 @@ type Commit struct {
 	Committer *Signature
 	// The full commit message.
 	Message string
+	// The stats of changes made by the commit. It is nil unless loaded, e.g. by
+	// CommitByRevisionOptions.LoadStats.
+	Stats *CommitStats
+	// The note attached to the commit. It is empty unless loaded, e.g. by
+	// LogOptions.ShowNotes.
+	Note string
 
 	parents []*SHA1
 	*Tree
@@ -32,6 +38,32 @@ type Commit struct {
 	submodulesErr  error
 }
 
+// CommitStats contains the stats of changes made by a commit.
+type CommitStats struct {
+	// The number of files changed.
+	FilesChanged int
+	// The number of lines inserted.
+	Insertions int
+	// The number of lines deleted.
+	Deletions int
+}
+
+// clone returns a shallow copy of the commit, so that fields that are loaded on
+// demand (e.g. Stats) can be set without mutating the cached commit, which is
+// shared by concurrent callers.
+func (c *Commit) clone() *Commit {
+	return &Commit{
+		ID:        c.ID,
+		Author:    c.Author,
+		Committer: c.Committer,
+		Message:   c.Message,
+		Stats:     c.Stats,
+		Note:      c.Note,
+		parents:   c.parents,
+		Tree:      c.Tree,
+	}
+}
+
 // Summary returns first line of commit message.
 func (c *Commit) Summary() string {
 	return strings.Split(c.Message, "\n")[0]
@@ -118,6 +150,26 @@ func (c *Commit) Ancestors(opts ...LogOptions) ([]*Commit, error) {
 	return c.repo.Log(c.ID.String(), opt)
 }
 
+// ReadFile returns the content of the file with given path (relative to the
+// repository root) in the state of this commit. It returns an
+// ErrObjectNotExist if the path does not exist, or an ErrNotBlob if the path is
+// not a file.
+func (c *Commit) ReadFile(path string, opts ...CatFileBlobOptions) ([]byte, error) {
+	var opt CatFileBlobOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	p, err := c.repo.ReadFile(c.ID.String(), path, ReadFileOptions{
+		Timeout:        opt.Timeout, //nolint
+		CommandOptions: opt.CommandOptions,
+	})
+	if err == ErrFileNotExist {
+		return nil, ErrObjectNotExist
+	}
+	return p, err
+}
+
 type limitWriter struct {
 	W io.Writer
 	N int64
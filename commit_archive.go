@@ -5,8 +5,11 @@
 package git
 
 import (
+	"compress/gzip"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // ArchiveFormat is the format of an archive.
@@ -18,14 +21,97 @@ const (
 	ArchiveTarGz ArchiveFormat = "tar.gz"
 )
 
+// CreateArchiveOptions contains optional arguments for creating an archive.
+//
+// Docs: https://git-scm.com/docs/git-archive
+type CreateArchiveOptions struct {
+	// The relative path of a directory or file to only include in the archive.
+	// Entries keep their full paths relative to the root of the repository.
+	Path string
+	// Indicates whether to pin the settings that affect the content of the
+	// archive but may vary between users and Git versions, i.e. the umask of tar
+	// entries and the gzip compression, so that the same commit always produces
+	// the same archive. Modification times of entries are always the commit time.
+	Reproducible bool
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
 // CreateArchive creates given format of archive to the destination.
-func (c *Commit) CreateArchive(format ArchiveFormat, dst string) error {
+func (c *Commit) CreateArchive(format ArchiveFormat, dst string, opts ...CreateArchiveOptions) error {
+	var opt CreateArchiveOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	// Git used "gzip -cn" until 2.38 which has the built-in implementation as
+	// the default, compress with the standard library instead to not depend on
+	// either of them.
+	compress := opt.Reproducible && format == ArchiveTarGz
+	archiveFormat := format
+	if compress {
+		archiveFormat = "tar"
+	}
+
+	cmd := NewCommand()
+	if opt.Reproducible {
+		cmd.AddArgs("-c", "tar.umask=0022")
+	}
+
 	prefix := filepath.Base(strings.TrimSuffix(c.repo.path, ".git")) + "/"
-	_, err := NewCommand("archive",
-		"--prefix="+prefix,
-		"--format="+string(format),
-		"-o", dst,
-		c.ID.String(),
-	).RunInDir(c.repo.path)
-	return err
+	cmd.AddArgs("archive").
+		AddOptions(opt.CommandOptions).
+		AddArgs(
+			"--prefix="+prefix,
+			"--format="+string(archiveFormat),
+		)
+	if !compress {
+		cmd.AddArgs("-o", dst)
+	}
+	cmd.AddArgs(c.ID.String())
+	if opt.Path != "" {
+		cmd.AddArgs("--", escapePath(opt.Path))
+	}
+
+	if opt.Timeout != 0 {
+		cmd = cmd.WithTimeout(opt.Timeout)
+	}
+
+	if !compress {
+		_, err := cmd.RunInDir(c.repo.path)
+		return err
+	}
+	return createGzipArchive(cmd, c.repo.path, dst)
+}
+
+// createGzipArchive runs the command that writes a tar archive to its stdout and
+// compresses the output to the destination. The gzip header carries neither a
+// file name nor a modification time, so the output only depends on the input.
+func createGzipArchive(cmd *Command, dir, dst string) (err error) {
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			_ = os.Remove(dst)
+		}
+	}()
+
+	gw := gzip.NewWriter(f)
+	stderr := newTailBuffer(stderrLimit)
+	err = cmd.RunInDirPipeline(gw, stderr, dir)
+	if err != nil {
+		_ = gw.Close()
+		return concatenateError(err, stderr.String())
+	}
+	return gw.Close()
 }
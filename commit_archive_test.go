@@ -5,6 +5,10 @@
 package git
 
 import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -38,3 +42,93 @@ func TestCommit_CreateArchive(t *testing.T) {
 		})
 	}
 }
+
+func TestCommit_CreateArchive_options(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	err = commitFile(r, "sub/dir/a.txt", "a", "Add sub/dir/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := r.CatFileCommit("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readEntries := func(path string) map[string]time.Time {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = f.Close() }()
+
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		entries := make(map[string]time.Time)
+		tr := tar.NewReader(gz)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if hdr.Typeflag == tar.TypeXGlobalHeader {
+				continue
+			}
+			entries[hdr.Name] = hdr.ModTime
+		}
+		return entries
+	}
+
+	prefix := filepath.Base(r.Path()) + "/"
+	createArchive := func(opt CreateArchiveOptions) string {
+		dst := tempPath()
+		t.Cleanup(func() {
+			_ = os.Remove(dst)
+		})
+
+		err := c.CreateArchive(ArchiveTarGz, dst, opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return dst
+	}
+
+	t.Run("path", func(t *testing.T) {
+		entries := readEntries(createArchive(CreateArchiveOptions{Path: "sub"}))
+
+		names := make([]string, 0, len(entries))
+		for name, modTime := range entries {
+			names = append(names, name)
+			assert.Equal(t, c.Committer.When.Unix(), modTime.Unix(), name)
+		}
+		assert.ElementsMatch(t,
+			[]string{prefix, prefix + "sub/", prefix + "sub/dir/", prefix + "sub/dir/a.txt"},
+			names,
+		)
+	})
+
+	t.Run("reproducible", func(t *testing.T) {
+		first, err := ioutil.ReadFile(createArchive(CreateArchiveOptions{Reproducible: true}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Make sure it is not affected by the time of creation
+		time.Sleep(time.Second)
+
+		second, err := ioutil.ReadFile(createArchive(CreateArchiveOptions{Reproducible: true}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, first, second)
+	})
+}
@@ -53,7 +53,8 @@ func (c *Commit) Submodules() (Submodules, error) {
 				continue
 			}
 
-			fields := strings.Split(scanner.Text(), "=")
+			// The value (e.g. URL) may contain "=" as well.
+			fields := strings.SplitN(scanner.Text(), "=", 2)
 			switch strings.TrimSpace(fields[0]) {
 			case "path":
 				path = strings.TrimSpace(fields[1])
@@ -81,11 +82,16 @@ func (c *Commit) Submodules() (Submodules, error) {
 	return c.submodules, c.submodulesErr
 }
 
-// Submodule returns submodule by given name. It returns an ErrSubmoduleNotExist
-// if the path does not exist as a submodule.
+// Submodule returns submodule by given path, including the commit ID recorded
+// for the path in this commit and the URL configured in ".gitmodules". It
+// returns an ErrSubmoduleNotExist if the path does not exist as a submodule,
+// including when this commit has no ".gitmodules" at all.
 func (c *Commit) Submodule(path string) (*Submodule, error) {
 	mods, err := c.Submodules()
 	if err != nil {
+		if err == ErrRevisionNotExist {
+			return nil, ErrSubmoduleNotExist
+		}
 		return nil, err
 	}
 
@@ -5,6 +5,8 @@
 package git
 
 import (
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -27,3 +29,63 @@ func TestCommit_Submodule(t *testing.T) {
 	_, err = c.Submodule("404")
 	assert.Equal(t, ErrSubmoduleNotExist, err)
 }
+
+func TestCommit_Submodule_edgeCases(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	t.Run("no .gitmodules", func(t *testing.T) {
+		err := commitFile(r, "file.txt", "content", "Add a file")
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := r.CatFileCommit("HEAD")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = c.Submodule("file.txt")
+		assert.Equal(t, ErrSubmoduleNotExist, err)
+	})
+
+	// Record a gitlink with a URL that contains "="
+	const subCommit = "6b08f76a5313fa3d26859515b30aa17a5faa2807"
+	_, err = NewCommand("update-index", "--add", "--cacheinfo", "160000,"+subCommit+",vendor/lib").RunInDir(r.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(r.Path(), ".gitmodules"), []byte(`[submodule "vendor/lib"]
+	path = vendor/lib
+	url = https://example.com/lib.git?ref=main
+`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Do not add all because the gitlink has no directory in the working tree
+	err = r.Add(AddOptions{Pathspecs: []string{".gitmodules"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.Commit(&Signature{Name: "alice", Email: "alice@example.com"}, "Add a submodule")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := r.CatFileCommit("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mod, err := c.Submodule("vendor/lib")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "vendor/lib", mod.Name)
+	assert.Equal(t, "https://example.com/lib.git?ref=main", mod.URL)
+	assert.Equal(t, subCommit, mod.Commit)
+
+	_, err = c.Submodule("file.txt")
+	assert.Equal(t, ErrSubmoduleNotExist, err)
+}
@@ -533,6 +533,67 @@ func TestCommit_Ancestors(t *testing.T) {
 	}
 }
 
+func TestCommit_ReadFile(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	err = commitFile(r, "dir/file.txt", "old content", "Add a file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldCommit, err := r.CatFileCommit("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = commitFile(r, "dir/file.txt", "new content", "Update the file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newCommit, err := r.CatFileCommit("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		commit     *Commit
+		path       string
+		expContent string
+		expErr     error
+	}{
+		{
+			commit:     oldCommit,
+			path:       "dir/file.txt",
+			expContent: "old content",
+		},
+		{
+			commit:     newCommit,
+			path:       "dir/file.txt",
+			expContent: "new content",
+		},
+		{
+			commit: newCommit,
+			path:   "dir/404.txt",
+			expErr: ErrObjectNotExist,
+		},
+		{
+			commit: newCommit,
+			path:   "dir",
+			expErr: ErrNotBlob,
+		},
+	}
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			p, err := test.commit.ReadFile(test.path)
+			assert.Equal(t, test.expErr, err)
+			assert.Equal(t, test.expContent, string(p))
+		})
+	}
+}
+
 func TestCommit_IsImageFile(t *testing.T) {
 	t.Run("not a blob", func(t *testing.T) {
 		c, err := testrepo.CatFileCommit("4e59b72440188e7c2578299fc28ea425fbe9aece")
@@ -41,14 +41,22 @@ const (
 type DiffLine struct {
 	Type      DiffLineType // The type of the line
 	Content   string       // The content of the line
-	LeftLine  int          // The left line number
-	RightLine int          // The right line number
+	LeftLine  int          // The left (old) line number, 0 for an added line
+	RightLine int          // The right (new) line number, 0 for a deleted line
 }
 
-// DiffSection represents a section in diff.
+// DiffSection represents a section (i.e. a hunk) in diff.
 type DiffSection struct {
 	Lines []*DiffLine // lines in the section
 
+	// The line ranges of the section as in its header "@@ -OldStart,OldLines
+	// +NewStart,NewLines @@". A range with no lines (e.g. "-5,0") starts after
+	// the line it names, since the section only adds or deletes lines there.
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+
 	numAdditions int
 	numDeletions int
 }
@@ -368,6 +376,48 @@ checkType:
 	return file, nil
 }
 
+// parseSectionRange parses a line range in the section header, e.g. "-1,7" or
+// "+3" where the number of lines is omitted when it is 1.
+func parseSectionRange(s string, prefix byte) (start, lines int, err error) {
+	if len(s) < 2 || s[0] != prefix {
+		return 0, 0, fmt.Errorf("malformed range %q", s)
+	}
+
+	fields := strings.SplitN(s[1:], ",", 2)
+	start, err = strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("parse start %q: %v", s, err)
+	}
+
+	lines = 1
+	if len(fields) > 1 {
+		lines, err = strconv.Atoi(fields[1])
+		if err != nil {
+			return 0, 0, fmt.Errorf("parse number of lines %q: %v", s, err)
+		}
+	}
+	return start, lines, nil
+}
+
+// parseSectionHeader parses line ranges from the section header, e.g.
+// "@@ -1,7 +1,8 @@ func main() {".
+func parseSectionHeader(line string) (oldStart, oldLines, newStart, newLines int, err error) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 || fields[0] != "@@" || fields[3] != "@@" {
+		return 0, 0, 0, 0, fmt.Errorf("malformed section header %q", line)
+	}
+
+	oldStart, oldLines, err = parseSectionRange(fields[1], '-')
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	newStart, newLines, err = parseSectionRange(fields[2], '+')
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	return oldStart, oldLines, newStart, newLines, nil
+}
+
 func (p *diffParser) parseSection() (_ *DiffSection, isIncomplete bool, _ error) {
 	line := string(p.buffer)
 	p.buffer = nil
@@ -381,18 +431,13 @@ func (p *diffParser) parseSection() (_ *DiffSection, isIncomplete bool, _ error)
 		},
 	}
 
-	// Parse line number, e.g. @@ -0,0 +1,3 @@
-	var leftLine, rightLine int
-	ss := strings.Split(line, "@@")
-	ranges := strings.Split(ss[1][1:], " ")
-	leftLine, _ = strconv.Atoi(strings.Split(ranges[0], ",")[0][1:])
-	if len(ranges) > 1 {
-		rightLine, _ = strconv.Atoi(strings.Split(ranges[1], ",")[0])
-	} else {
-		rightLine = leftLine
+	var err error
+	section.OldStart, section.OldLines, section.NewStart, section.NewLines, err = parseSectionHeader(line)
+	if err != nil {
+		return nil, false, err
 	}
+	leftLine, rightLine := section.OldStart, section.NewStart
 
-	var err error
 	for !p.isEOF {
 		if err = p.readLine(); err != nil {
 			return nil, false, err
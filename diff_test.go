@@ -214,6 +214,10 @@ index 0000000..6b08f76
 										RightLine: 3,
 									},
 								},
+								OldStart:     0,
+								OldLines:     0,
+								NewStart:     1,
+								NewLines:     3,
 								numAdditions: 3,
 								numDeletions: 0,
 							},
@@ -245,6 +249,10 @@ index 0000000..6b08f76
 										RightLine: 1,
 									},
 								},
+								OldStart:     0,
+								OldLines:     0,
+								NewStart:     1,
+								NewLines:     1,
 								numAdditions: 1,
 								numDeletions: 0,
 							},
@@ -333,6 +341,10 @@ index ee791be..9997571 100644
 										RightLine: 7,
 									},
 								},
+								OldStart:     1,
+								OldLines:     7,
+								NewStart:     1,
+								NewLines:     7,
 								numAdditions: 1,
 								numDeletions: 1,
 							},
@@ -480,6 +492,10 @@ index b6fc4c620b67d95f953a5c1c1230aaab5db5a1b0..ab80bda5dd90d8b42be25ac2c7a071b7
 										RightLine: 3,
 									},
 								},
+								OldStart:     1,
+								OldLines:     1,
+								NewStart:     1,
+								NewLines:     3,
 								numAdditions: 3,
 								numDeletions: 1,
 							},
@@ -571,6 +587,10 @@ index ce53c7e..56a156b 100644
 										RightLine: 7,
 									},
 								},
+								OldStart:     2,
+								OldLines:     9,
+								NewStart:     2,
+								NewLines:     9,
 								numAdditions: 2,
 								numDeletions: 2,
 							},
@@ -670,6 +690,10 @@ index 335db7ea..51d7543e 100644
 										RightLine: 6,
 									},
 								},
+								OldStart:     1,
+								OldLines:     9,
+								NewStart:     1,
+								NewLines:     6,
 								numAdditions: 0,
 								numDeletions: 3,
 							},
@@ -725,6 +749,10 @@ index 0000000..6abde17
 										RightLine: 2,
 									},
 								},
+								OldStart:     0,
+								OldLines:     0,
+								NewStart:     1,
+								NewLines:     3,
 								numAdditions: 2,
 								numDeletions: 0,
 							},
@@ -789,6 +817,10 @@ index 0000000..6b08f76
 										RightLine: 2,
 									},
 								},
+								OldStart:     0,
+								OldLines:     0,
+								NewStart:     1,
+								NewLines:     3,
 								numAdditions: 2,
 								numDeletions: 0,
 							},
@@ -844,3 +876,138 @@ new mode 100755`,
 		})
 	}
 }
+
+func Test_parseSectionHeader(t *testing.T) {
+	tests := []struct {
+		line        string
+		expOldStart int
+		expOldLines int
+		expNewStart int
+		expNewLines int
+	}{
+		{line: "@@ -1,7 +1,8 @@", expOldStart: 1, expOldLines: 7, expNewStart: 1, expNewLines: 8},
+		{line: "@@ -2,9 +2,9 @@ import { IonicModule } from '@ionic/angular'", expOldStart: 2, expOldLines: 9, expNewStart: 2, expNewLines: 9},
+		{line: "@@ -0,0 +1 @@", expOldStart: 0, expOldLines: 0, expNewStart: 1, expNewLines: 1},
+		{line: "@@ -3 +3 @@", expOldStart: 3, expOldLines: 1, expNewStart: 3, expNewLines: 1},
+		{line: "@@ -10,0 +11,2 @@ func main() {", expOldStart: 10, expOldLines: 0, expNewStart: 11, expNewLines: 2},
+		{line: "@@ -15 +16,0 @@", expOldStart: 15, expOldLines: 1, expNewStart: 16, expNewLines: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			oldStart, oldLines, newStart, newLines, err := parseSectionHeader(test.line)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, test.expOldStart, oldStart)
+			assert.Equal(t, test.expOldLines, oldLines)
+			assert.Equal(t, test.expNewStart, newStart)
+			assert.Equal(t, test.expNewLines, newLines)
+		})
+	}
+
+	for _, line := range []string{
+		"@@",
+		"@@ -1,7 @@",
+		"@@ +1,7 -1,7 @@",
+		"@@ -a,7 +1,7 @@",
+		"@@ -1,b +1,7 @@",
+		"@@@ -1,2 -1,2 +1,3 @@@",
+	} {
+		_, _, _, _, err := parseSectionHeader(line)
+		assert.Error(t, err, line)
+	}
+}
+
+func TestStreamParseDiff_lineNumbers(t *testing.T) {
+	input := `diff --git a/main.go b/main.go
+index 0000000..1111111 100644
+--- a/main.go
++++ b/main.go
+@@ -2 +2 @@ package main
+-import "fmt"
++import "log"
+@@ -10,0 +11,2 @@ func main() {
++	log.Println("a")
++	log.Println("b")
+@@ -15 +17,0 @@ func main() {
+-	fmt.Println("c")
+@@ -20,4 +21,3 @@ func main() {
+ 	x := 1
+-	y := 2
+ 	z := 3
+ 	_ = x + z`
+
+	done := make(chan SteamParseDiffResult)
+	go StreamParseDiff(strings.NewReader(input), done, 0, 0, 0)
+	result := <-done
+	if result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	type line struct {
+		typ       DiffLineType
+		leftLine  int
+		rightLine int
+	}
+	type section struct {
+		oldStart, oldLines, newStart, newLines int
+		lines                                  []line
+	}
+	expSections := []section{
+		{
+			oldStart: 2, oldLines: 1, newStart: 2, newLines: 1,
+			lines: []line{
+				{typ: DiffLineSection},
+				{typ: DiffLineDelete, leftLine: 2},
+				{typ: DiffLineAdd, rightLine: 2},
+			},
+		},
+		{
+			oldStart: 10, oldLines: 0, newStart: 11, newLines: 2,
+			lines: []line{
+				{typ: DiffLineSection},
+				{typ: DiffLineAdd, rightLine: 11},
+				{typ: DiffLineAdd, rightLine: 12},
+			},
+		},
+		{
+			oldStart: 15, oldLines: 1, newStart: 17, newLines: 0,
+			lines: []line{
+				{typ: DiffLineSection},
+				{typ: DiffLineDelete, leftLine: 15},
+			},
+		},
+		{
+			oldStart: 20, oldLines: 4, newStart: 21, newLines: 3,
+			lines: []line{
+				{typ: DiffLineSection},
+				{typ: DiffLinePlain, leftLine: 20, rightLine: 21},
+				{typ: DiffLineDelete, leftLine: 21},
+				{typ: DiffLinePlain, leftLine: 22, rightLine: 22},
+				{typ: DiffLinePlain, leftLine: 23, rightLine: 23},
+			},
+		},
+	}
+
+	if !assert.Len(t, result.Diff.Files, 1) {
+		return
+	}
+	sections := make([]section, len(result.Diff.Files[0].Sections))
+	for i, s := range result.Diff.Files[0].Sections {
+		sections[i] = section{
+			oldStart: s.OldStart,
+			oldLines: s.OldLines,
+			newStart: s.NewStart,
+			newLines: s.NewLines,
+		}
+		for _, l := range s.Lines {
+			sections[i].lines = append(sections[i].lines, line{
+				typ:       l.Type,
+				leftLine:  l.LeftLine,
+				rightLine: l.RightLine,
+			})
+		}
+	}
+	assert.Equal(t, expSections, sections)
+}
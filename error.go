@@ -6,16 +6,116 @@ package git
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 )
 
 var (
-	ErrParentNotExist       = errors.New("parent does not exist")
-	ErrSubmoduleNotExist    = errors.New("submodule does not exist")
-	ErrRevisionNotExist     = errors.New("revision does not exist")
-	ErrRemoteNotExist       = errors.New("remote does not exist")
-	ErrURLNotExist          = errors.New("URL does not exist")
-	ErrExecTimeout          = errors.New("execution was timed out")
-	ErrNoMergeBase          = errors.New("no merge based was found")
-	ErrNotBlob              = errors.New("the entry is not a blob")
-	ErrNotDeleteNonPushURLs = errors.New("will not delete all non-push URLs")
+	ErrParentNotExist        = errors.New("parent does not exist")
+	ErrSubmoduleNotExist     = errors.New("submodule does not exist")
+	ErrRevisionNotExist      = errors.New("revision does not exist")
+	ErrRemoteNotExist        = errors.New("remote does not exist")
+	ErrURLNotExist           = errors.New("URL does not exist")
+	ErrExecTimeout           = errors.New("execution was timed out")
+	ErrNoMergeBase           = errors.New("no merge based was found")
+	ErrNotBlob               = errors.New("the entry is not a blob")
+	ErrNotCommit             = errors.New("the object is not a commit")
+	ErrNotDeleteNonPushURLs  = errors.New("will not delete all non-push URLs")
+	ErrDetachedHead          = errors.New("HEAD is not on a branch")
+	ErrInvalidHookName       = errors.New("invalid hook name")
+	ErrObjectNotExist        = errors.New("object does not exist")
+	ErrStaleInfo             = errors.New("remote reference has been updated since last seen")
+	ErrRemoteBranchNotExist  = errors.New("remote branch does not exist")
+	ErrNoUpstream            = errors.New("branch has no upstream")
+	ErrBranchExisted         = errors.New("branch already exists")
+	ErrBranchNotExist        = errors.New("branch does not exist")
+	ErrRemoteAuthRequired    = errors.New("remote requires authentication")
+	ErrRemoteNotFound        = errors.New("remote repository not found")
+	ErrRemoteUnreachable     = errors.New("remote is unreachable")
+	ErrTagExisted            = errors.New("tag already exists")
+	ErrFileNotTracked        = errors.New("file is not tracked")
+	ErrFileExisted           = errors.New("file already exists")
+	ErrNoStashEntries        = errors.New("no stash entries")
+	ErrUnsupportedGitVersion = errors.New("unsupported Git version")
+	ErrNotObjectDirectory    = errors.New("not an object directory")
+	ErrAlternateNotExist     = errors.New("alternate does not exist")
+	ErrFileNotExist          = errors.New("file does not exist")
+	ErrFileTooLarge          = errors.New("file is too large")
+	ErrMergeConflict         = errors.New("merge has conflicts")
+	ErrPartialApply          = errors.New("patch applied partially with rejects")
+	ErrNotFastForward        = errors.New("not possible to fast-forward")
+	ErrNothingToCommit       = errors.New("nothing to commit")
+	ErrRefUpdateRejected     = errors.New("reference is not at the expected value")
+	ErrConfigKeyNotExist     = errors.New("config key does not exist")
+	ErrNoTagsFound           = errors.New("no tags can describe the revision")
 )
+
+// CommandError is returned when a command failed with output to stderr.
+type CommandError struct {
+	// The underlying error, e.g. *exec.ExitError.
+	Err error
+	// The stderr of the command, which only has the last bytes when it exceeds
+	// the limit (see SetStderrLimit).
+	Stderr string
+}
+
+func (err *CommandError) Error() string {
+	return fmt.Sprintf("%v - %s", err.Err, err.Stderr)
+}
+
+// Unwrap returns the underlying error.
+func (err *CommandError) Unwrap() error {
+	return err.Err
+}
+
+// MergeConflictError is returned when a merge stopped because of conflicts.
+type MergeConflictError struct {
+	// The paths that have conflicts, sorted by path.
+	Conflicts []string
+}
+
+func (err *MergeConflictError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrMergeConflict, strings.Join(err.Conflicts, ", "))
+}
+
+// Unwrap returns ErrMergeConflict.
+func (err *MergeConflictError) Unwrap() error {
+	return ErrMergeConflict
+}
+
+// PartialApplyError is returned when a patch was applied with rejects, i.e.
+// some of the hunks could not be applied and were written to ".rej" files
+// instead.
+type PartialApplyError struct {
+	// The paths of the ".rej" files relative to the root of the working tree.
+	RejectFiles []string
+}
+
+func (err *PartialApplyError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrPartialApply, strings.Join(err.RejectFiles, ", "))
+}
+
+// Unwrap returns ErrPartialApply.
+func (err *PartialApplyError) Unwrap() error {
+	return ErrPartialApply
+}
+
+// RemoteError is returned when accessing a remote repository failed for a known
+// reason.
+type RemoteError struct {
+	// The reason of the failure, i.e. ErrRemoteAuthRequired, ErrRemoteNotFound
+	// or ErrRemoteUnreachable.
+	Reason error
+	// The error of the command, which is usually a *CommandError that has the
+	// stderr of the command.
+	Err error
+}
+
+func (err *RemoteError) Error() string {
+	return fmt.Sprintf("%v: %v", err.Reason, err.Err)
+}
+
+// Unwrap returns the reason of the failure.
+func (err *RemoteError) Unwrap() error {
+	return err.Reason
+}
@@ -28,6 +28,17 @@ func SetPrefix(prefix string) {
 	logPrefix = prefix
 }
 
+// stderrLimit is the maximum number of bytes of stderr to be kept in errors of
+// commands.
+var stderrLimit = 64 << 10
+
+// SetStderrLimit sets the maximum number of bytes of stderr to be kept in
+// errors of commands, only the last bytes are kept when stderr exceeds the
+// limit. A non-positive limit means no limit.
+func SetStderrLimit(limit int) {
+	stderrLimit = limit
+}
+
 func log(format string, args ...interface{}) {
 	if logOutput == nil {
 		return
@@ -5,6 +5,9 @@
 package git
 
 import (
+	"bufio"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path"
@@ -21,6 +24,45 @@ const (
 	HookPostReceive HookName = "post-receive"
 )
 
+// knownHooks contains names of all hooks that are recognized by Git.
+//
+// Docs: https://git-scm.com/docs/githooks
+var knownHooks = map[HookName]bool{
+	"applypatch-msg":        true,
+	"pre-applypatch":        true,
+	"post-applypatch":       true,
+	"pre-commit":            true,
+	"pre-merge-commit":      true,
+	"prepare-commit-msg":    true,
+	"commit-msg":            true,
+	"post-commit":           true,
+	"pre-rebase":            true,
+	"post-checkout":         true,
+	"post-merge":            true,
+	"pre-push":              true,
+	HookPreReceive:          true,
+	HookUpdate:              true,
+	"proc-receive":          true,
+	HookPostReceive:         true,
+	"post-update":           true,
+	"reference-transaction": true,
+	"push-to-checkout":      true,
+	"pre-auto-gc":           true,
+	"post-rewrite":          true,
+	"sendemail-validate":    true,
+	"fsmonitor-watchman":    true,
+	"p4-changelist":         true,
+	"p4-prepare-changelist": true,
+	"p4-post-changelist":    true,
+	"p4-pre-submit":         true,
+	"post-index-change":     true,
+}
+
+// IsKnownHook returns true if given name is a hook recognized by Git.
+func IsKnownHook(name HookName) bool {
+	return knownHooks[name]
+}
+
 var (
 	// ServerSideHooks contains a list of Git hooks that are supported on the server
 	// side.
@@ -205,10 +247,12 @@ done`,
 
 // Hook contains information of a Git hook.
 type Hook struct {
-	name     HookName
-	path     string // The absolute file path of the hook.
-	isSample bool   // Indicates whether this hook is read from the sample.
-	content  string // The content of the hook.
+	name         HookName
+	path         string // The absolute file path of the hook.
+	isSample     bool   // Indicates whether this hook is read from the sample.
+	content      string // The content of the hook.
+	isInstalled  bool   // Indicates whether this hook exists on filesystem.
+	isExecutable bool   // Indicates whether this hook has the executable bit.
 }
 
 // Name returns the name of the Git hook.
@@ -226,6 +270,18 @@ func (h *Hook) IsSample() bool {
 	return h.isSample
 }
 
+// IsInstalled returns true if the hook exists on filesystem. It is only
+// reported by Repository.ListHooks.
+func (h *Hook) IsInstalled() bool {
+	return h.isInstalled
+}
+
+// IsExecutable returns true if the hook has the executable bit, i.e. it would be
+// run by Git. It is only reported by Repository.ListHooks.
+func (h *Hook) IsExecutable() bool {
+	return h.isExecutable
+}
+
 // Content returns the content of the Git hook.
 func (h *Hook) Content() string {
 	return h.content
@@ -246,3 +302,97 @@ func (h *Hook) Update(content string) error {
 	h.isSample = false
 	return nil
 }
+
+// HookRefUpdate is a reference update that Git passes to the pre-receive and
+// post-receive hooks.
+type HookRefUpdate struct {
+	// The old commit ID of the reference, which is EmptyID when the reference is
+	// created.
+	OldID string
+	// The new commit ID of the reference, which is EmptyID when the reference is
+	// deleted.
+	NewID string
+	// The full name of the reference, e.g. "refs/heads/master".
+	RefName string
+}
+
+// IsCreation returns true if the reference is created by the update.
+func (u *HookRefUpdate) IsCreation() bool {
+	return u.OldID == EmptyID
+}
+
+// IsDeletion returns true if the reference is deleted by the update.
+func (u *HookRefUpdate) IsDeletion() bool {
+	return u.NewID == EmptyID
+}
+
+// ParseHookRefUpdates parses the reference updates from the standard input of
+// the pre-receive and post-receive hooks, i.e. lines in the form of
+// "<old-value> <new-value> <ref-name>". Empty lines are skipped.
+func ParseHookRefUpdates(r io.Reader) ([]HookRefUpdate, error) {
+	updates := []HookRefUpdate{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Split(line, " ")
+		if len(fields) != 3 || fields[2] == "" {
+			return nil, fmt.Errorf("malformed reference update: %q", line)
+		}
+		for _, id := range fields[:2] {
+			if _, err := NewIDFromString(id); err != nil {
+				return nil, fmt.Errorf("parse commit ID %q: %v", id, err)
+			}
+		}
+
+		updates = append(updates, HookRefUpdate{
+			OldID:   fields[0],
+			NewID:   fields[1],
+			RefName: fields[2],
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return updates, nil
+}
+
+// Environment variables that Git sets for the pre-receive hook, which point to
+// the quarantine directory of pushed objects that are not yet accepted.
+//
+// Docs: https://git-scm.com/docs/git-receive-pack#_quarantine_environment
+const (
+	EnvObjectDirectory            = "GIT_OBJECT_DIRECTORY"
+	EnvAlternateObjectDirectories = "GIT_ALTERNATE_OBJECT_DIRECTORIES"
+	EnvQuarantinePath             = "GIT_QUARANTINE_PATH"
+)
+
+// QuarantineEnvs returns the environment variables to read objects from given
+// object directory in addition to the alternate object directories, e.g. the
+// quarantine directory and the object directory of the repository. The result
+// can be passed as CommandOptions.Envs.
+func QuarantineEnvs(objectDir string, alternateDirs ...string) []string {
+	envs := []string{EnvObjectDirectory + "=" + objectDir}
+	if len(alternateDirs) > 0 {
+		envs = append(envs, EnvAlternateObjectDirectories+"="+strings.Join(alternateDirs, string(os.PathListSeparator)))
+	}
+	return envs
+}
+
+// HookQuarantineEnvs returns the environment variables of the quarantine
+// directory that are set for the current process, i.e. when running inside the
+// pre-receive hook. Git commands run by this package inherit them already, the
+// result is for passing them on to commands that do not, e.g. those with a
+// cleared environment or running in another process.
+func HookQuarantineEnvs() []string {
+	envs := make([]string, 0, 3)
+	for _, key := range []string{EnvObjectDirectory, EnvAlternateObjectDirectories, EnvQuarantinePath} {
+		if value, ok := os.LookupEnv(key); ok {
+			envs = append(envs, key+"="+value)
+		}
+	}
+	return envs
+}
@@ -7,6 +7,8 @@ package git
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -49,3 +51,150 @@ func TestHook_Update(t *testing.T) {
 	}
 	assert.Equal(t, "test content", string(p))
 }
+
+func TestParseHookRefUpdates(t *testing.T) {
+	const (
+		id1 = "1111111111111111111111111111111111111111"
+		id2 = "2222222222222222222222222222222222222222"
+	)
+	input := EmptyID + " " + id1 + " refs/heads/feature\n" +
+		id1 + " " + id2 + " refs/heads/master\r\n" +
+		"\n" +
+		id2 + " " + EmptyID + " refs/tags/v1.0.0\n"
+	updates, err := ParseHookRefUpdates(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []HookRefUpdate{
+		{OldID: EmptyID, NewID: id1, RefName: "refs/heads/feature"},
+		{OldID: id1, NewID: id2, RefName: "refs/heads/master"},
+		{OldID: id2, NewID: EmptyID, RefName: "refs/tags/v1.0.0"},
+	}, updates)
+
+	assert.True(t, updates[0].IsCreation())
+	assert.False(t, updates[0].IsDeletion())
+	assert.False(t, updates[1].IsCreation())
+	assert.False(t, updates[1].IsDeletion())
+	assert.False(t, updates[2].IsCreation())
+	assert.True(t, updates[2].IsDeletion())
+
+	t.Run("empty input", func(t *testing.T) {
+		updates, err := ParseHookRefUpdates(strings.NewReader(""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Empty(t, updates)
+	})
+
+	for _, input := range []string{
+		id1 + " " + id2 + "\n",
+		id1 + " " + id2 + " \n",
+		id1 + "  " + id2 + " refs/heads/master\n",
+		"bad " + id2 + " refs/heads/master\n",
+		id1 + " bad refs/heads/master\n",
+	} {
+		_, err = ParseHookRefUpdates(strings.NewReader(input))
+		assert.Error(t, err, input)
+	}
+}
+
+func TestQuarantineEnvs(t *testing.T) {
+	assert.Equal(t, []string{"GIT_OBJECT_DIRECTORY=/quarantine"}, QuarantineEnvs("/quarantine"))
+	assert.Equal(t,
+		[]string{
+			"GIT_OBJECT_DIRECTORY=/quarantine",
+			"GIT_ALTERNATE_OBJECT_DIRECTORIES=/objects" + string(os.PathListSeparator) + "/alternates",
+		},
+		QuarantineEnvs("/quarantine", "/objects", "/alternates"),
+	)
+}
+
+func TestRepository_quarantine(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	// Write a commit to a quarantine directory like Git does for objects pushed
+	// to the pre-receive hook.
+	objectDir := filepath.Join(r.Path(), ".git", "objects")
+	quarantineDir := filepath.Join(objectDir, "incoming-test")
+	err = os.MkdirAll(quarantineDir, os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	envs := QuarantineEnvs(quarantineDir, objectDir)
+
+	run := func(stdin string, args ...string) string {
+		stdout := new(strings.Builder)
+		stderr := new(strings.Builder)
+		err := NewCommand(args...).AddEnvs(envs...).RunInDirWithOptions(r.Path(), RunInDirOptions{
+			Stdin:  strings.NewReader(stdin),
+			Stdout: stdout,
+			Stderr: stderr,
+		})
+		if err != nil {
+			t.Fatal(concatenateError(err, stderr.String()))
+		}
+		return strings.TrimSpace(stdout.String())
+	}
+	blobID := run("quarantined", "hash-object", "-w", "--stdin")
+	treeID := run("100644 blob "+blobID+"\tquarantine.txt\n", "mktree")
+	commitID := run("", "commit-tree", treeID, "-p", "master", "-m", "Add quarantine.txt")
+
+	t.Run("not visible by default", func(t *testing.T) {
+		_, err := r.CatFileCommit(commitID)
+		assert.Error(t, err)
+	})
+
+	t.Run("explicit environment variables", func(t *testing.T) {
+		c, err := r.CatFileCommit(commitID, CatFileCommitOptions{
+			CommandOptions: CommandOptions{Envs: envs},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "Add quarantine.txt", c.Summary())
+
+		blob, err := r.CatFileBlob(blobID, CatFileBlobOptions{
+			CommandOptions: CommandOptions{Envs: envs},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, blobID, blob.ID().String())
+
+		status, err := r.ChangedFiles("master", commitID, ChangedFilesOptions{
+			CommandOptions: CommandOptions{Envs: envs},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Contains(t, status.Added, "quarantine.txt")
+	})
+
+	t.Run("inherited environment variables", func(t *testing.T) {
+		for _, env := range envs {
+			kv := strings.SplitN(env, "=", 2)
+			err := os.Setenv(kv[0], kv[1])
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = os.Unsetenv(kv[0]) }()
+		}
+		assert.Equal(t, envs, HookQuarantineEnvs())
+
+		// Use a fresh handle so that the commit is not served from the cache.
+		r, err := Open(r.Path())
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := r.CatFileCommit(commitID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "Add quarantine.txt", c.Summary())
+	})
+	assert.Empty(t, HookQuarantineEnvs())
+}
@@ -0,0 +1,139 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Progress is a progress update reported by Git, e.g. "Receiving objects:  45%
+// (450/1000), 1.20 MiB | 2.00 MiB/s".
+type Progress struct {
+	// The phase of the operation, e.g. "Counting objects" or "Receiving objects".
+	Phase string
+	// Indicates whether the progress is reported by the remote side.
+	Remote bool
+	// The percentage of completion, it is only meaningful when Total is not zero.
+	Percent int
+	// The number of items that have been processed.
+	Current int64
+	// The total number of items, which is zero when unknown.
+	Total int64
+	// Indicates whether the phase is done.
+	Done bool
+}
+
+// ProgressFunc is called with each progress update reported by Git.
+type ProgressFunc func(Progress)
+
+// parseProgress parses a line of progress output, e.g. "remote: Counting
+// objects: 100% (10/10), done." or "remote: Enumerating objects: 10, done.". It
+// returns false if the line is not a progress update.
+func parseProgress(line string) (Progress, bool) {
+	var p Progress
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "remote: ") {
+		p.Remote = true
+		line = line[len("remote: "):]
+	}
+
+	i := strings.Index(line, ": ")
+	if i <= 0 {
+		return p, false
+	}
+	p.Phase = line[:i]
+	rest := strings.TrimSpace(line[i+2:])
+	p.Done = strings.HasSuffix(rest, ", done.")
+
+	if i := strings.Index(rest, "%"); i > 0 {
+		percent, err := strconv.Atoi(strings.TrimSpace(rest[:i]))
+		if err != nil {
+			return p, false
+		}
+		p.Percent = percent
+
+		// The counts are in the form of "(<current>/<total>)".
+		rest = strings.TrimSpace(rest[i+1:])
+		end := strings.Index(rest, ")")
+		if !strings.HasPrefix(rest, "(") || end < 0 {
+			return p, false
+		}
+		counts := strings.SplitN(rest[1:end], "/", 2)
+		if len(counts) != 2 {
+			return p, false
+		}
+		p.Current, err = strconv.ParseInt(counts[0], 10, 64)
+		if err != nil {
+			return p, false
+		}
+		p.Total, err = strconv.ParseInt(counts[1], 10, 64)
+		if err != nil {
+			return p, false
+		}
+		return p, true
+	}
+
+	// Phases without a known total only report the count, e.g. "10, done.".
+	if end := strings.IndexAny(rest, ", "); end >= 0 {
+		rest = rest[:end]
+	}
+	current, err := strconv.ParseInt(rest, 10, 64)
+	if err != nil {
+		return p, false
+	}
+	p.Current = current
+	return p, true
+}
+
+// progressWriter parses the progress output written to it, where updates of the
+// same phase are separated by carriage returns, and calls the function with
+// each progress update. The output is also kept for reporting errors.
+type progressWriter struct {
+	fn     ProgressFunc
+	buf    []byte
+	stderr *tailBuffer
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	_, _ = w.stderr.Write(p)
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexAny(w.buf, "\r\n")
+		if i < 0 {
+			break
+		}
+		if progress, ok := parseProgress(string(w.buf[:i])); ok {
+			w.fn(progress)
+		}
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// runWithProgress runs the command in given directory with progress reporting
+// enabled by the "--progress" flag that has been added to the command. The
+// function is called with each progress update.
+func runWithProgress(cmd *Command, timeout time.Duration, dir string, fn ProgressFunc) error {
+	if timeout != 0 {
+		cmd = cmd.WithTimeout(timeout)
+	}
+
+	w := &progressWriter{
+		fn:     fn,
+		stderr: newTailBuffer(stderrLimit),
+	}
+	err := cmd.RunInDirWithOptions(dir, RunInDirOptions{
+		Stdout: new(bytes.Buffer),
+		Stderr: w,
+	})
+	if err != nil {
+		return concatenateError(err, w.stderr.String())
+	}
+	return nil
+}
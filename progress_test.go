@@ -0,0 +1,165 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseProgress(t *testing.T) {
+	tests := []struct {
+		line        string
+		expProgress Progress
+		expOK       bool
+	}{
+		{
+			line: "Receiving objects:  45% (450/1000), 1.20 MiB | 2.00 MiB/s   ",
+			expProgress: Progress{
+				Phase:   "Receiving objects",
+				Percent: 45,
+				Current: 450,
+				Total:   1000,
+			},
+			expOK: true,
+		},
+		{
+			line: "remote: Counting objects: 100% (13/13), done.",
+			expProgress: Progress{
+				Phase:   "Counting objects",
+				Remote:  true,
+				Percent: 100,
+				Current: 13,
+				Total:   13,
+				Done:    true,
+			},
+			expOK: true,
+		},
+		{
+			line: "remote: Enumerating objects: 13, done.",
+			expProgress: Progress{
+				Phase:   "Enumerating objects",
+				Remote:  true,
+				Current: 13,
+				Done:    true,
+			},
+			expOK: true,
+		},
+		{
+			line:  "Cloning into 'repo'...",
+			expOK: false,
+		},
+		{
+			line:  "warning: You appear to have cloned an empty repository.",
+			expOK: false,
+		},
+		{
+			line:  "Receiving objects: 45% 450/1000",
+			expOK: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			progress, ok := parseProgress(test.line)
+			assert.Equal(t, test.expOK, ok)
+			if test.expOK {
+				assert.Equal(t, test.expProgress, progress)
+			}
+		})
+	}
+}
+
+func Test_progressWriter(t *testing.T) {
+	var phases []string
+	w := &progressWriter{
+		fn: func(p Progress) {
+			phases = append(phases, p.Phase)
+		},
+		stderr: newTailBuffer(stderrLimit),
+	}
+
+	// Updates may be split across writes.
+	for _, p := range []string{
+		"Cloning into 'repo'...\nremote: Counting objects:  50% (1/2)\r",
+		"remote: Counting obj",
+		"ects: 100% (2/2), done.\nReceiving objects: 100% (2/2)",
+	} {
+		_, _ = w.Write([]byte(p))
+	}
+	assert.Equal(t, []string{"Counting objects", "Counting objects"}, phases)
+	assert.Contains(t, w.stderr.String(), "Cloning into 'repo'...")
+}
+
+func TestClone_progress(t *testing.T) {
+	path := tempPath()
+	defer func() {
+		_ = os.RemoveAll(path)
+	}()
+
+	// A local path does not transfer objects, use the file protocol instead.
+	var progresses []Progress
+	err := Clone("file://"+testrepo.Path(), path, CloneOptions{
+		Bare: true,
+		ProgressFunc: func(p Progress) {
+			progresses = append(progresses, p)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var received *Progress
+	for i := range progresses {
+		if progresses[i].Phase == "Receiving objects" {
+			received = &progresses[i]
+		}
+	}
+	if assert.NotNil(t, received) {
+		assert.Equal(t, 100, received.Percent)
+		assert.Equal(t, received.Total, received.Current)
+	}
+
+	t.Run("error", func(t *testing.T) {
+		err := Clone("file://"+testrepo.Path()+"404", tempPath(), CloneOptions{
+			ProgressFunc: func(Progress) {},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestRepository_Fetch_progress(t *testing.T) {
+	upstream, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	r, cleanup2, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup2()
+
+	err = commitFile(upstream, "progress.txt", "progress", "Add progress.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var phases []string
+	err = r.Fetch(FetchOptions{
+		Remote:   "file://" + upstream.Path(),
+		Refspecs: []string{"refs/heads/master:refs/remotes/upstream/master"},
+		ProgressFunc: func(p Progress) {
+			phases = append(phases, p.Phase)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, phases, "Counting objects")
+	assert.True(t, r.HasReference("refs/remotes/upstream/master"))
+}
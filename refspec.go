@@ -0,0 +1,71 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MirrorRefspec is the refspec that is used by a "--mirror=fetch" remote, which
+// maps all references of the remote to the same local references.
+const MirrorRefspec = "+refs/*:refs/*"
+
+// RemoteTrackingRefspec returns the default fetch refspec of the remote with
+// given name, which maps branches of the remote to its remote-tracking
+// branches, e.g. "+refs/heads/*:refs/remotes/origin/*".
+func RemoteTrackingRefspec(remote string) string {
+	return "+" + RefsHeads + "*:refs/remotes/" + remote + "/*"
+}
+
+// ResolveRefspec splits given refspec (e.g. "+refs/heads/*:refs/remotes/origin/*")
+// into its source, destination and whether the update is forced. The source
+// could be empty for a refspec that deletes the destination on push (e.g.
+// ":refs/heads/feature"), and the destination could be empty when the refspec
+// has no colon. It returns an error if the refspec is malformed, including when
+// the wildcards of the two sides do not balance.
+//
+// Docs: https://git-scm.com/docs/git-fetch#Documentation/git-fetch.txt-ltrefspecgt
+func ResolveRefspec(refspec string) (src, dst string, force bool, err error) {
+	spec := refspec
+	if strings.HasPrefix(spec, "+") {
+		force = true
+		spec = spec[1:]
+	}
+	if spec == "" {
+		return "", "", false, fmt.Errorf("invalid refspec %q: empty", refspec)
+	} else if strings.HasPrefix(spec, "^") {
+		return "", "", false, fmt.Errorf("invalid refspec %q: negative refspec is not supported", refspec)
+	}
+
+	src = spec
+	if i := strings.IndexByte(spec, ':'); i >= 0 {
+		src, dst = spec[:i], spec[i+1:]
+		if strings.IndexByte(dst, ':') >= 0 {
+			return "", "", false, fmt.Errorf("invalid refspec %q: more than one colon", refspec)
+		}
+	}
+	if src == "" && dst == "" {
+		return "", "", false, fmt.Errorf("invalid refspec %q: both sides are empty", refspec)
+	}
+
+	for _, side := range []string{src, dst} {
+		if strings.ContainsAny(side, " ~^?[\\\t\n") ||
+			strings.Contains(side, "..") ||
+			strings.Contains(side, "@{") {
+			return "", "", false, fmt.Errorf("invalid refspec %q: invalid character in %q", refspec, side)
+		} else if strings.Count(side, "*") > 1 {
+			return "", "", false, fmt.Errorf("invalid refspec %q: more than one wildcard in %q", refspec, side)
+		}
+	}
+
+	// A pattern source must be mapped to a pattern destination, and vice versa.
+	if dst != "" && src != "" && strings.Contains(src, "*") != strings.Contains(dst, "*") {
+		return "", "", false, fmt.Errorf("invalid refspec %q: wildcards do not balance", refspec)
+	} else if src == "" && strings.Contains(dst, "*") {
+		return "", "", false, fmt.Errorf("invalid refspec %q: wildcard without source", refspec)
+	}
+	return src, dst, force, nil
+}
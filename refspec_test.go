@@ -0,0 +1,121 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoteTrackingRefspec(t *testing.T) {
+	assert.Equal(t, "+refs/heads/*:refs/remotes/origin/*", RemoteTrackingRefspec("origin"))
+}
+
+func TestResolveRefspec(t *testing.T) {
+	tests := []struct {
+		refspec  string
+		expSrc   string
+		expDst   string
+		expForce bool
+		expErr   bool
+	}{
+		{
+			refspec:  "+refs/heads/*:refs/remotes/origin/*",
+			expSrc:   "refs/heads/*",
+			expDst:   "refs/remotes/origin/*",
+			expForce: true,
+		},
+		{
+			refspec:  MirrorRefspec,
+			expSrc:   "refs/*",
+			expDst:   "refs/*",
+			expForce: true,
+		},
+		{
+			refspec: "refs/heads/master:refs/heads/main",
+			expSrc:  "refs/heads/master",
+			expDst:  "refs/heads/main",
+		},
+		{
+			refspec: "master",
+			expSrc:  "master",
+		},
+		{
+			refspec: "master:",
+			expSrc:  "master",
+		},
+		{
+			refspec: ":refs/heads/feature",
+			expDst:  "refs/heads/feature",
+		},
+		{
+			refspec: "refs/heads/release-*:refs/remotes/origin/release-*",
+			expSrc:  "refs/heads/release-*",
+			expDst:  "refs/remotes/origin/release-*",
+		},
+
+		{
+			refspec: "",
+			expErr:  true,
+		},
+		{
+			refspec: "+",
+			expErr:  true,
+		},
+		{
+			refspec: ":",
+			expErr:  true,
+		},
+		{
+			refspec: "a:b:c",
+			expErr:  true,
+		},
+		{
+			refspec: "refs/heads/*:refs/remotes/origin/master",
+			expErr:  true,
+		},
+		{
+			refspec: "refs/heads/master:refs/remotes/origin/*",
+			expErr:  true,
+		},
+		{
+			refspec: "refs/*/*:refs/*/*",
+			expErr:  true,
+		},
+		{
+			refspec: ":refs/heads/*",
+			expErr:  true,
+		},
+		{
+			refspec: "refs/heads/a b:refs/heads/c",
+			expErr:  true,
+		},
+		{
+			refspec: "refs/heads/a..b",
+			expErr:  true,
+		},
+		{
+			refspec: "^refs/heads/master",
+			expErr:  true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.refspec, func(t *testing.T) {
+			src, dst, force, err := ResolveRefspec(test.refspec)
+			if test.expErr {
+				assert.Error(t, err)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, test.expSrc, src)
+			assert.Equal(t, test.expDst, dst)
+			assert.Equal(t, test.expForce, force)
+		})
+	}
+}
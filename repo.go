@@ -7,14 +7,18 @@ package git
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
+
+	goversion "github.com/mcuadros/go-version"
 )
 
 // Repository contains information of a Git repository.
@@ -24,6 +28,8 @@ type Repository struct {
 	cachedCommits *objectCache
 	cachedTags    *objectCache
 	cachedTrees   *objectCache
+	// The cache of reachability queries, nil unless enabled.
+	cachedReachability *objectCache
 }
 
 // Path returns the path of the repository.
@@ -31,23 +37,53 @@ func (r *Repository) Path() string {
 	return r.path
 }
 
+// In returns a handle of the repository that runs commands in the directory,
+// e.g. a linked worktree or a subdirectory of the working tree, while sharing
+// the object caches with the original handle. A relative directory is resolved
+// against the path of the repository. The cache of reachability queries is also
+// shared, so updating references through either handle invalidates it for both,
+// while the queries are cached per directory because revisions like "HEAD"
+// resolve differently in each worktree.
+func (r *Repository) In(dir string) (*Repository, error) {
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(r.path, dir)
+	}
+	if !isDir(dir) {
+		return nil, os.ErrNotExist
+	}
+
+	scoped := *r
+	scoped.path = filepath.Clean(dir)
+	return &scoped, nil
+}
+
 const LogFormatHashOnly = `format:%H`
 
 // parsePrettyFormatLogToList returns a list of commits parsed from given logs
 // that are formatted in LogFormatHashOnly.
 func (r *Repository) parsePrettyFormatLogToList(timeout time.Duration, logs []byte) ([]*Commit, error) {
-	if len(logs) == 0 {
-		return []*Commit{}, nil
-	}
+	return r.parsePrettyFormatLog(timeout, bytes.NewReader(logs))
+}
 
-	var err error
-	ids := bytes.Split(logs, []byte{'\n'})
-	commits := make([]*Commit, len(ids))
-	for i, id := range ids {
-		commits[i], err = r.CatFileCommit(string(id), CatFileCommitOptions{Timeout: timeout})
+// parsePrettyFormatLog returns a list of commits parsed from given reader of
+// logs that are formatted in LogFormatHashOnly, one commit ID at a time.
+func (r *Repository) parsePrettyFormatLog(timeout time.Duration, logs io.Reader) ([]*Commit, error) {
+	commits := make([]*Commit, 0)
+	scanner := bufio.NewScanner(logs)
+	for scanner.Scan() {
+		id := scanner.Text()
+		if id == "" {
+			continue
+		}
+
+		c, err := r.CatFileCommit(id, CatFileCommitOptions{Timeout: timeout}) //nolint
 		if err != nil {
 			return nil, err
 		}
+		commits = append(commits, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
 	}
 	return commits, nil
 }
@@ -117,8 +153,13 @@ type CloneOptions struct {
 	Quiet bool
 	// The branch to checkout for the working tree when Bare=false.
 	Branch string
+	// Indicates whether to only clone the history of the branch to checkout (or
+	// the default branch), which is implied when Depth is set.
+	SingleBranch bool
 	// The number of revisions to clone.
 	Depth uint64
+	// The function to be called with each progress update reported by Git.
+	ProgressFunc ProgressFunc
 	// The timeout duration before giving up for each shell command execution. The
 	// default timeout duration will be used when not supplied.
 	//
@@ -153,20 +194,94 @@ func Clone(url, dst string, opts ...CloneOptions) error {
 	if !opt.Bare && opt.Branch != "" {
 		cmd.AddArgs("-b", opt.Branch)
 	}
+	if opt.SingleBranch {
+		cmd.AddArgs("--single-branch")
+	}
 	if opt.Depth > 0 {
 		cmd.AddArgs("--depth", strconv.FormatUint(opt.Depth, 10))
 	}
 
+	if opt.ProgressFunc != nil {
+		cmd.AddArgs("--progress", url, dst)
+		return runWithProgress(cmd, opt.Timeout, "", opt.ProgressFunc)
+	}
+
 	_, err = cmd.AddArgs(url, dst).RunWithTimeout(opt.Timeout)
 	return err
 }
 
+// CloneSharedOptions contains optional arguments for cloning a local repository
+// with shared objects.
+//
+// Docs: https://git-scm.com/docs/git-clone#Documentation/git-clone.txt---shared
+type CloneSharedOptions struct {
+	// Indicates whether the repository should be cloned as a mirror.
+	Mirror bool
+	// Indicates whether the repository should be cloned in bare format.
+	Bare bool
+	// Indicates whether to copy the borrowed objects into the new repository
+	// after cloning, so that it no longer depends on the source repository.
+	Dissociate bool
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// CloneShared clones the local repository in the source path to the
+// destination, which borrows the objects from the source repository via
+// alternates instead of copying them, e.g. to create a fork. The new repository
+// becomes corrupt when objects it still borrows are removed from the source
+// repository, e.g. by garbage collection after a force push, unless the
+// Dissociate option is set.
+func CloneShared(src, dst string, opts ...CloneSharedOptions) error {
+	var opt CloneSharedOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	err := os.MkdirAll(path.Dir(dst), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	cmd := NewCommand("clone", "--shared").AddOptions(opt.CommandOptions)
+	if opt.Mirror {
+		cmd.AddArgs("--mirror")
+	}
+	if opt.Bare {
+		cmd.AddArgs("--bare")
+	}
+	if opt.Dissociate {
+		cmd.AddArgs("--dissociate")
+	}
+
+	// 🚨 SECURITY: Prevent including unintended options in the path to the Git command.
+	_, err = cmd.AddArgs("--end-of-options", src, dst).RunWithTimeout(opt.Timeout)
+	return err
+}
+
 // FetchOptions contains optional arguments for fetching repository updates.
 //
 // Docs: https://git-scm.com/docs/git-fetch
 type FetchOptions struct {
+	// The remote to fetch from, the default remote is used when not supplied.
+	Remote string
+	// The refspecs to fetch instead of the configured ones of the remote. It
+	// requires Remote to be set.
+	Refspecs []string
 	// Indicates whether to prune during fetching.
 	Prune bool
+	// Indicates whether to fetch all tags in addition to the refspecs.
+	Tags bool
+	// The number of revisions to fetch from the tip of each remote branch, which
+	// deepens or shortens the history of a shallow repository.
+	Depth uint64
+	// The function to be called with each progress update reported by Git.
+	ProgressFunc ProgressFunc
 	// The timeout duration before giving up for each shell command execution. The
 	// default timeout duration will be used when not supplied.
 	//
@@ -178,20 +293,190 @@ type FetchOptions struct {
 
 // Fetch fetches updates for the repository.
 func (r *Repository) Fetch(opts ...FetchOptions) error {
+	defer r.ClearReachabilityCache()
+
 	var opt FetchOptions
 	if len(opts) > 0 {
 		opt = opts[0]
 	}
 
+	if opt.Remote == "" && len(opt.Refspecs) > 0 {
+		return errors.New("remote is required for refspecs")
+	}
+
 	cmd := NewCommand("fetch").AddOptions(opt.CommandOptions)
 	if opt.Prune {
 		cmd.AddArgs("--prune")
 	}
+	if opt.Tags {
+		cmd.AddArgs("--tags")
+	}
+	if opt.Depth > 0 {
+		cmd.AddArgs("--depth", strconv.FormatUint(opt.Depth, 10))
+	}
+	if opt.ProgressFunc != nil {
+		cmd.AddArgs("--progress")
+	}
+	if opt.Remote != "" {
+		// 🚨 SECURITY: Prevent including unintended options in the path to the Git command.
+		cmd.AddArgs("--end-of-options", opt.Remote)
+		cmd.AddArgs(opt.Refspecs...)
+	}
 
+	if opt.ProgressFunc != nil {
+		return runWithProgress(cmd, opt.Timeout, r.path, opt.ProgressFunc)
+	}
 	_, err := cmd.RunInDirWithTimeout(opt.Timeout, r.path)
 	return err
 }
 
+// FetchMirrorOptions contains optional arguments for syncing a mirror from its
+// upstream.
+//
+// Docs: https://git-scm.com/docs/git-fetch
+type FetchMirrorOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// FetchMirror fetches updates from the remote that is added as a mirror (i.e.
+// RemoteAddOptions.MirrorFetch), updating local references to exactly match the
+// remote. References that have been deleted from the remote are also pruned
+// locally.
+func (r *Repository) FetchMirror(remote string, opts ...FetchMirrorOptions) error {
+	defer r.ClearReachabilityCache()
+
+	var opt FetchMirrorOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	_, err := NewCommand("fetch", "--prune").
+		AddOptions(opt.CommandOptions).
+		AddArgs(remote).
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	return err
+}
+
+// FetchPruneOptions contains optional arguments for pruning remote-tracking
+// references while fetching.
+//
+// Docs: https://git-scm.com/docs/git-fetch#_pruning
+type FetchPruneOptions struct {
+	// Indicates whether to only report the references that would be pruned
+	// without actually fetching or pruning anything.
+	DryRun bool
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// parseFetchPruned returns the references reported as pruned in the output of
+// "git fetch --prune", i.e. lines like " - [deleted] (none) -> origin/feature"
+// or " * [would prune] origin/feature".
+func parseFetchPruned(output []byte) []string {
+	var refs []string
+	for _, line := range bytesToStrings(output) {
+		line = strings.TrimSpace(line)
+		for _, marker := range []string{"[would prune]", "[deleted]"} {
+			i := strings.Index(line, marker)
+			if i < 0 {
+				continue
+			}
+
+			ref := strings.TrimSpace(line[i+len(marker):])
+			if j := strings.LastIndex(ref, "-> "); j >= 0 {
+				ref = strings.TrimSpace(ref[j+len("-> "):])
+			}
+			if ref != "" {
+				refs = append(refs, ref)
+			}
+			break
+		}
+	}
+	return refs
+}
+
+// FetchPrune fetches updates from the remote and prunes remote-tracking
+// references that no longer exist on the remote. It returns the pruned
+// references in their short form as displayed by Git, e.g. "origin/feature".
+// When FetchPruneOptions.DryRun is set, nothing is changed and the returned
+// references are the ones that would be pruned.
+func (r *Repository) FetchPrune(remote string, opts ...FetchPruneOptions) ([]string, error) {
+	defer r.ClearReachabilityCache()
+
+	var opt FetchPruneOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	cmd := NewCommand("fetch", "--prune").AddOptions(opt.CommandOptions)
+	if opt.DryRun {
+		cmd.AddArgs("--dry-run")
+	}
+	if remote != "" {
+		cmd.AddArgs(remote)
+	}
+	if opt.Timeout != 0 {
+		cmd = cmd.WithTimeout(opt.Timeout)
+	}
+
+	// The pruned references are reported to stderr, which is kept in full for
+	// parsing while only its tail goes into the error.
+	stdout := new(bytes.Buffer)
+	output := new(bytes.Buffer)
+	stderr := newTailBuffer(stderrLimit)
+	err := cmd.RunInDirPipeline(stdout, io.MultiWriter(output, stderr), r.path)
+	if err != nil {
+		return nil, concatenateError(err, stderr.String())
+	}
+	return parseFetchPruned(output.Bytes()), nil
+}
+
+// PrefetchOptions contains optional arguments for prefetching from a remote.
+//
+// Docs: https://git-scm.com/docs/git-maintenance#Documentation/git-maintenance.txt-prefetch
+type PrefetchOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// Prefetch fetches objects and branches from the remote into
+// "refs/prefetch/<remote>/", the same namespace used by the prefetch task of
+// "git maintenance", so that a later fetch has less to download. Unlike Fetch,
+// it never updates remote-tracking references or fetches tags, and branches
+// that no longer exist on the remote are pruned from the namespace.
+func (r *Repository) Prefetch(remote string, opts ...PrefetchOptions) error {
+	defer r.ClearReachabilityCache()
+
+	var opt PrefetchOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	// An empty "--refmap" prevents Git from also updating remote-tracking
+	// references that are matched by the configured refspecs of the remote.
+	_, err := NewCommand("fetch", "--no-tags", "--prune", "--refmap=").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--end-of-options", remote, "+"+RefsHeads+"*:refs/prefetch/"+remote+"/*").
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	return err
+}
+
 // PullOptions contains optional arguments for pulling repository updates.
 //
 // Docs: https://git-scm.com/docs/git-pull
@@ -215,6 +500,8 @@ type PullOptions struct {
 
 // Pull pulls updates for the repository.
 func (r *Repository) Pull(opts ...PullOptions) error {
+	defer r.ClearReachabilityCache()
+
 	var opt PullOptions
 	if len(opts) > 0 {
 		opt = opts[0]
@@ -242,6 +529,9 @@ func (r *Repository) Pull(opts ...PullOptions) error {
 //
 // Docs: https://git-scm.com/docs/git-push
 type PushOptions struct {
+	// Indicates whether to force push only if the remote-tracking branch is still
+	// at the same commit as the branch on the remote.
+	ForceWithLease bool
 	// The timeout duration before giving up for each shell command execution. The
 	// default timeout duration will be used when not supplied.
 	//
@@ -259,8 +549,16 @@ func Push(repoPath, remote, branch string, opts ...PushOptions) error {
 		opt = opts[0]
 	}
 
-	cmd := NewCommand("push").AddOptions(opt.CommandOptions).AddArgs(remote, branch)
+	cmd := NewCommand("push").AddOptions(opt.CommandOptions)
+	if opt.ForceWithLease {
+		cmd.AddArgs("--force-with-lease")
+	}
+	cmd.AddArgs(remote, branch)
+
 	_, err := cmd.RunInDirWithTimeout(opt.Timeout, repoPath)
+	if err != nil && strings.Contains(err.Error(), "(stale info)") {
+		return ErrStaleInfo
+	}
 	return err
 }
 
@@ -271,9 +569,115 @@ func RepoPush(repoPath, remote, branch string, opts ...PushOptions) error {
 
 // Push pushes local changes to given remote and branch for the repository.
 func (r *Repository) Push(remote, branch string, opts ...PushOptions) error {
+	defer r.ClearReachabilityCache()
 	return Push(r.path, remote, branch, opts...)
 }
 
+// ForcePushWithLease force pushes the local branch to the same branch of the
+// remote, only if the branch on the remote is still at the expected commit. An
+// empty expected commit means the branch should not exist on the remote. It
+// returns ErrStaleInfo if the branch on the remote has been moved.
+func (r *Repository) ForcePushWithLease(remote, branch, expectedRemoteSHA string, opts ...PushOptions) error {
+	defer r.ClearReachabilityCache()
+
+	var opt PushOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	_, err := NewCommand("push").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--force-with-lease="+RefsHeads+branch+":"+expectedRemoteSHA, remote, branch).
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil && strings.Contains(err.Error(), "(stale info)") {
+		return ErrStaleInfo
+	}
+	return err
+}
+
+// DeleteRemoteBranchOptions contains optional arguments for deleting a branch
+// on a remote.
+//
+// Docs: https://git-scm.com/docs/git-push#Documentation/git-push.txt---delete
+type DeleteRemoteBranchOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// DeleteRemoteBranch deletes the branch on the remote, along with its
+// remote-tracking branch. It returns ErrRemoteNotExist if the remote does not
+// exist, or ErrRemoteBranchNotExist if the branch does not exist on the remote.
+func (r *Repository) DeleteRemoteBranch(remote, branch string, opts ...DeleteRemoteBranchOptions) error {
+	defer r.ClearReachabilityCache()
+
+	var opt DeleteRemoteBranchOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	_, err := NewCommand("push").
+		AddOptions(opt.CommandOptions).
+		AddArgs(remote, "--delete", branch).
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		if strings.Contains(err.Error(), "remote ref does not exist") {
+			return ErrRemoteBranchNotExist
+		} else if strings.Contains(err.Error(), "does not appear to be a git repository") {
+			return ErrRemoteNotExist
+		}
+		return err
+	}
+	return nil
+}
+
+// PushMirrorOptions contains optional arguments for mirroring references to a
+// remote.
+//
+// Docs: https://git-scm.com/docs/git-push#Documentation/git-push.txt---mirror
+type PushMirrorOptions struct {
+	// Indicates whether to only show what would be changed without actually
+	// updating the remote.
+	DryRun bool
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// PushMirror pushes all references of the repository in given path to the
+// remote, making the remote exactly match local references. References that do
+// not exist locally are deleted from the remote.
+func PushMirror(repoPath, remote string, opts ...PushMirrorOptions) error {
+	var opt PushMirrorOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	cmd := NewCommand("push", "--mirror").AddOptions(opt.CommandOptions)
+	if opt.DryRun {
+		cmd.AddArgs("--dry-run")
+	}
+
+	_, err := cmd.AddArgs(remote).RunInDirWithTimeout(opt.Timeout, repoPath)
+	return err
+}
+
+// PushMirror pushes all references of the repository to the remote, making the
+// remote exactly match local references. References that do not exist locally
+// are deleted from the remote.
+func (r *Repository) PushMirror(remote string, opts ...PushMirrorOptions) error {
+	defer r.ClearReachabilityCache()
+	return PushMirror(r.path, remote, opts...)
+}
+
 // CheckoutOptions contains optional arguments for checking out to a branch.
 //
 // Docs: https://git-scm.com/docs/git-checkout
@@ -316,6 +720,7 @@ func RepoCheckout(repoPath, branch string, opts ...CheckoutOptions) error {
 
 // Checkout checks out to given branch for the repository.
 func (r *Repository) Checkout(branch string, opts ...CheckoutOptions) error {
+	defer r.ClearReachabilityCache()
 	return Checkout(r.path, branch, opts...)
 }
 
@@ -357,6 +762,7 @@ func RepoReset(repoPath, rev string, opts ...ResetOptions) error {
 
 // Reset resets working tree to given revision for the repository.
 func (r *Repository) Reset(rev string, opts ...ResetOptions) error {
+	defer r.ClearReachabilityCache()
 	return Reset(r.path, rev, opts...)
 }
 
@@ -365,6 +771,8 @@ func (r *Repository) Reset(rev string, opts ...ResetOptions) error {
 //
 // Docs: https://git-scm.com/docs/git-mv
 type MoveOptions struct {
+	// Indicates whether to overwrite the destination if it exists.
+	Force bool
 	// The timeout duration before giving up for each shell command execution. The
 	// default timeout duration will be used when not supplied.
 	//
@@ -375,15 +783,30 @@ type MoveOptions struct {
 }
 
 // Move moves a file, a directory, or a symlink file or directory from source to
-// destination for the repository in given path.
+// destination for the repository in given path. It returns ErrFileNotTracked
+// if the source is not tracked, or ErrFileExisted if the destination exists
+// and MoveOptions.Force is not set.
 func Move(repoPath, src, dst string, opts ...MoveOptions) error {
 	var opt MoveOptions
 	if len(opts) > 0 {
 		opt = opts[0]
 	}
 
-	_, err := NewCommand("mv").AddOptions(opt.CommandOptions).AddArgs(src, dst).RunInDirWithTimeout(opt.Timeout, repoPath)
-	return err
+	cmd := NewCommand("mv").AddOptions(opt.CommandOptions)
+	if opt.Force {
+		cmd.AddArgs("--force")
+	}
+	_, err := cmd.AddArgs("--", src, dst).RunInDirWithTimeout(opt.Timeout, repoPath)
+	if err != nil {
+		if strings.Contains(err.Error(), "not under version control") ||
+			strings.Contains(err.Error(), "bad source") {
+			return ErrFileNotTracked
+		} else if strings.Contains(err.Error(), "destination exists") {
+			return ErrFileExisted
+		}
+		return err
+	}
+	return nil
 }
 
 // Deprecated: Use Move instead.
@@ -392,7 +815,9 @@ func RepoMove(repoPath, src, dst string, opts ...MoveOptions) error {
 }
 
 // Move moves a file, a directory, or a symlink file or directory from source to
-// destination for the repository.
+// destination for the repository. It returns ErrFileNotTracked if the source is
+// not tracked, or ErrFileExisted if the destination exists and
+// MoveOptions.Force is not set.
 func (r *Repository) Move(src, dst string, opts ...MoveOptions) error {
 	return Move(r.path, src, dst, opts...)
 }
@@ -449,6 +874,11 @@ func (r *Repository) Add(opts ...AddOptions) error {
 type CommitOptions struct {
 	// Author is the author of the changes if that's not the same as committer.
 	Author *Signature
+	// Indicates whether to allow making a commit that has no changes.
+	AllowEmpty bool
+	// Indicates whether to replace the tip commit of the current branch by a new
+	// commit, instead of adding a new one on top of it.
+	Amend bool
 	// The timeout duration before giving up for each shell command execution. The
 	// default timeout duration will be used when not supplied.
 	//
@@ -459,7 +889,8 @@ type CommitOptions struct {
 }
 
 // CreateCommit commits local changes with given author, committer and message
-// for the repository in given path.
+// for the repository in given path. It returns ErrNothingToCommit if there are
+// no changes staged.
 func CreateCommit(repoPath string, committer *Signature, message string, opts ...CommitOptions) error {
 	var opt CommitOptions
 	if len(opts) > 0 {
@@ -472,14 +903,19 @@ func CreateCommit(repoPath string, committer *Signature, message string, opts ..
 	if opt.Author == nil {
 		opt.Author = committer
 	}
-	cmd = cmd.AddArgs(fmt.Sprintf("--author='%s <%s>'", opt.Author.Name, opt.Author.Email)).
-		AddArgs("-m", message).
+	cmd.AddEnvs("GIT_AUTHOR_NAME="+opt.Author.Name, "GIT_AUTHOR_EMAIL="+opt.Author.Email).
 		AddOptions(opt.CommandOptions)
+	if opt.AllowEmpty {
+		cmd.AddArgs("--allow-empty")
+	}
+	if opt.Amend {
+		cmd.AddArgs("--amend")
+	}
 
-	_, err := cmd.RunInDirWithTimeout(opt.Timeout, repoPath)
-	// No stderr but exit status 1 means nothing to commit.
-	if err != nil && err.Error() == "exit status 1" {
-		return nil
+	_, err := cmd.AddArgs("-m", message).RunInDirWithTimeout(opt.Timeout, repoPath)
+	// No stderr but exit code 1 means nothing to commit.
+	if isExitCode(err, 1) {
+		return ErrNothingToCommit
 	}
 	return err
 }
@@ -490,8 +926,9 @@ func RepoCommit(repoPath string, committer *Signature, message string, opts ...C
 }
 
 // Commit commits local changes with given author, committer and message for the
-// repository.
+// repository. It returns ErrNothingToCommit if there are no changes staged.
 func (r *Repository) Commit(committer *Signature, message string, opts ...CommitOptions) error {
+	defer r.ClearReachabilityCache()
 	return CreateCommit(r.path, committer, message, opts...)
 }
 
@@ -546,7 +983,7 @@ func ShowNameStatus(repoPath, rev string, opts ...ShowNameStatusOptions) (*NameS
 		done <- struct{}{}
 	}()
 
-	stderr := new(bytes.Buffer)
+	stderr := newTailBuffer(stderrLimit)
 	cmd := NewCommand("show", "--name-status", "--pretty=format:''").
 		AddOptions(opt.CommandOptions).
 		AddArgs(rev)
@@ -574,6 +1011,12 @@ func (r *Repository) ShowNameStatus(rev string, opts ...ShowNameStatusOptions) (
 //
 // Docs: https://git-scm.com/docs/git-rev-parse
 type RevParseOptions struct {
+	// Indicates whether to return the shortest unique abbreviation of the object
+	// ID instead of the full length one.
+	Short bool
+	// Indicates whether to return the short name of the reference that the
+	// revision refers to instead of the object ID, e.g. "master" for "HEAD".
+	AbbrevRef bool
 	// The timeout duration before giving up for each shell command execution. The
 	// default timeout duration will be used when not supplied.
 	//
@@ -584,17 +1027,22 @@ type RevParseOptions struct {
 }
 
 // RevParse returns full length (40) commit ID by given revision in the
-// repository.
+// repository, e.g. "HEAD~3", "master@{yesterday}" or an abbreviated commit ID.
+// It returns ErrRevisionNotExist if the revision cannot be resolved.
 func (r *Repository) RevParse(rev string, opts ...RevParseOptions) (string, error) {
 	var opt RevParseOptions
 	if len(opts) > 0 {
 		opt = opts[0]
 	}
 
-	commitID, err := NewCommand("rev-parse").
-		AddOptions(opt.CommandOptions).
-		AddArgs(rev).
-		RunInDirWithTimeout(opt.Timeout, r.path)
+	cmd := NewCommand("rev-parse", "--verify").AddOptions(opt.CommandOptions)
+	if opt.Short {
+		cmd.AddArgs("--short")
+	}
+	if opt.AbbrevRef {
+		cmd.AddArgs("--abbrev-ref")
+	}
+	commitID, err := cmd.AddArgs(rev).RunInDirWithTimeout(opt.Timeout, r.path)
 	if err != nil {
 		if strings.Contains(err.Error(), "exit status 128") {
 			return "", ErrRevisionNotExist
@@ -604,6 +1052,218 @@ func (r *Repository) RevParse(rev string, opts ...RevParseOptions) (string, erro
 	return strings.TrimSpace(string(commitID)), nil
 }
 
+// RevParseManyOptions contains optional arguments for parsing revisions in
+// batch.
+//
+// Docs: https://git-scm.com/docs/git-cat-file#_batch_output
+type RevParseManyOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// RevParseMany resolves given revisions to full length object IDs in a single
+// Git process. Revisions that do not resolve (or are ambiguous) are returned in
+// the unresolved list rather than failing the batch.
+func (r *Repository) RevParseMany(revs []string, opts ...RevParseManyOptions) (resolved map[string]string, unresolved []string, err error) {
+	var opt RevParseManyOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	resolved = make(map[string]string, len(revs))
+	unresolved = make([]string, 0)
+
+	// Each input line is a single revision, so revisions that are empty or span
+	// multiple lines could never resolve.
+	stdin := new(bytes.Buffer)
+	inputs := make([]string, 0, len(revs))
+	for _, rev := range revs {
+		if rev == "" || strings.ContainsAny(rev, "\r\n") {
+			unresolved = append(unresolved, rev)
+			continue
+		}
+		inputs = append(inputs, rev)
+		stdin.WriteString(rev)
+		stdin.WriteByte('\n')
+	}
+	if len(inputs) == 0 {
+		return resolved, unresolved, nil
+	}
+
+	stdout := new(bytes.Buffer)
+	stderr := newTailBuffer(stderrLimit)
+	cmd := NewCommand("cat-file").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--batch-check=%(objectname)")
+	if opt.Timeout != 0 {
+		cmd = cmd.WithTimeout(opt.Timeout)
+	}
+	err = cmd.RunInDirWithOptions(r.path, RunInDirOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+	if err != nil {
+		return nil, nil, concatenateError(err, stderr.String())
+	}
+
+	lines := bytesToStrings(stdout.Bytes())
+	if len(lines) != len(inputs) {
+		return nil, nil, fmt.Errorf("expect %d lines of output but got %d", len(inputs), len(lines))
+	}
+
+	// An object ID never contains spaces, while a revision that does not resolve
+	// is reported as "<rev> missing" or "<rev> ambiguous".
+	for i, line := range lines {
+		if strings.Contains(line, " ") {
+			unresolved = append(unresolved, inputs[i])
+			continue
+		}
+		resolved[inputs[i]] = line
+	}
+	return resolved, unresolved, nil
+}
+
+// ObjectInfo contains the type and size of an object.
+type ObjectInfo struct {
+	// The type of the object.
+	Type ObjectType
+	// The size of the object in bytes.
+	Size int64
+}
+
+// ObjectInfosOptions contains optional arguments for getting information of
+// objects.
+//
+// Docs: https://git-scm.com/docs/git-cat-file#Documentation/git-cat-file.txt---batch-checkltformatgt
+type ObjectInfosOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// ObjectInfos returns the type and size of each given object in a single Git
+// process, keyed by the object ID as given. Objects that do not exist are
+// returned in the missing list rather than failing the batch.
+func (r *Repository) ObjectInfos(ids []string, opts ...ObjectInfosOptions) (infos map[string]*ObjectInfo, missing []string, err error) {
+	var opt ObjectInfosOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	infos = make(map[string]*ObjectInfo, len(ids))
+	missing = make([]string, 0)
+
+	stdin := new(bytes.Buffer)
+	inputs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id == "" || strings.ContainsAny(id, "\r\n") {
+			missing = append(missing, id)
+			continue
+		}
+		inputs = append(inputs, id)
+		stdin.WriteString(id)
+		stdin.WriteByte('\n')
+	}
+	if len(inputs) == 0 {
+		return infos, missing, nil
+	}
+
+	stdout := new(bytes.Buffer)
+	stderr := newTailBuffer(stderrLimit)
+	cmd := NewCommand("cat-file").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--batch-check=%(objecttype) %(objectsize)")
+	if opt.Timeout != 0 {
+		cmd = cmd.WithTimeout(opt.Timeout)
+	}
+	err = cmd.RunInDirWithOptions(r.path, RunInDirOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+	if err != nil {
+		return nil, nil, concatenateError(err, stderr.String())
+	}
+
+	lines := bytesToStrings(stdout.Bytes())
+	if len(lines) != len(inputs) {
+		return nil, nil, fmt.Errorf("expect %d lines of output but got %d", len(inputs), len(lines))
+	}
+
+	// An object that does not exist is reported as "<id> missing" or "<id>
+	// ambiguous" instead of the format.
+	for i, line := range lines {
+		if strings.HasSuffix(line, " missing") || strings.HasSuffix(line, " ambiguous") {
+			missing = append(missing, inputs[i])
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, nil, fmt.Errorf("malformed output: %q", line)
+		}
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse size %q: %v", fields[1], err)
+		}
+		infos[inputs[i]] = &ObjectInfo{
+			Type: ObjectType(fields[0]),
+			Size: size,
+		}
+	}
+	return infos, missing, nil
+}
+
+// ObjectsExistOptions contains optional arguments for checking existence of
+// objects.
+//
+// Docs: https://git-scm.com/docs/git-cat-file#Documentation/git-cat-file.txt---batch-checkltformatgt
+type ObjectsExistOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// ObjectsExist checks whether each given object exists in a single Git process,
+// keyed by the object ID as given.
+func (r *Repository) ObjectsExist(ids []string, opts ...ObjectsExistOptions) (map[string]bool, error) {
+	var opt ObjectsExistOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	infos, missing, err := r.ObjectInfos(ids, ObjectInfosOptions{
+		Timeout:        opt.Timeout, //nolint
+		CommandOptions: opt.CommandOptions,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	exists := make(map[string]bool, len(ids))
+	for id := range infos {
+		exists[id] = true
+	}
+	for _, id := range missing {
+		exists[id] = false
+	}
+	return exists, nil
+}
+
 // CountObject contains disk usage report of a repository.
 type CountObject struct {
 	Count         int64
@@ -687,6 +1347,9 @@ func (r *Repository) CountObjects(opts ...CountObjectsOptions) (*CountObject, er
 //
 // Docs: https://git-scm.com/docs/git-fsck
 type FsckOptions struct {
+	// Indicates whether to only check the connectivity of reachable objects,
+	// without checking the content of blobs, which is much faster.
+	ConnectivityOnly bool
 	// The timeout duration before giving up for each shell command execution. The
 	// default timeout duration will be used when not supplied.
 	//
@@ -705,6 +1368,9 @@ func Fsck(repoPath string, opts ...FsckOptions) error {
 	}
 
 	cmd := NewCommand("fsck").AddOptions(opt.CommandOptions)
+	if opt.ConnectivityOnly {
+		cmd.AddArgs("--connectivity-only")
+	}
 	_, err := cmd.RunInDirWithTimeout(opt.Timeout, repoPath)
 	return err
 }
@@ -719,3 +1385,230 @@ func RepoFsck(repoPath string, opts ...FsckOptions) error {
 func (r *Repository) Fsck(opts ...FsckOptions) error {
 	return Fsck(r.path, opts...)
 }
+
+// FsckObject is an object reported by "git fsck".
+type FsckObject struct {
+	Type ObjectType
+	ID   string
+}
+
+// FsckReport is the result of verifying the objects of a repository.
+type FsckReport struct {
+	// The objects that are not reachable from any reference, which are harmless
+	// and will be removed by garbage collection eventually.
+	Dangling []FsckObject
+	// The objects that are reachable but do not exist in the database.
+	Missing []FsckObject
+	// The messages of other problems, e.g. corrupt objects and broken links.
+	Errors []string
+	// The messages of warnings, e.g. objects with non-standard formats, which do
+	// not affect the health of the repository.
+	Warnings []string
+}
+
+// IsHealthy returns true if there are neither missing objects nor errors.
+func (r *FsckReport) IsHealthy() bool {
+	return len(r.Missing) == 0 && len(r.Errors) == 0
+}
+
+// parseFsckReport parses the output of "git fsck", where dangling and missing
+// objects are reported to stdout in the form of "<kind> <type> <id>". Notices
+// are skipped, warnings are collected separately, and any other line is taken
+// as an error.
+func parseFsckReport(stdout, stderr []byte) *FsckReport {
+	report := &FsckReport{
+		Dangling: []FsckObject{},
+		Missing:  []FsckObject{},
+		Errors:   []string{},
+		Warnings: []string{},
+	}
+	addMessage := func(line string) {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "", strings.HasPrefix(line, "notice:"):
+		case strings.HasPrefix(line, "warning:"), strings.HasPrefix(line, "warning in "):
+			report.Warnings = append(report.Warnings, line)
+		default:
+			report.Errors = append(report.Errors, line)
+		}
+	}
+	for _, line := range bytesToStrings(stdout) {
+		fields := strings.Fields(line)
+		if len(fields) == 3 {
+			obj := FsckObject{
+				Type: ObjectType(fields[1]),
+				ID:   fields[2],
+			}
+			switch fields[0] {
+			case "dangling":
+				report.Dangling = append(report.Dangling, obj)
+				continue
+			case "missing":
+				report.Missing = append(report.Missing, obj)
+				continue
+			}
+		}
+		addMessage(line)
+	}
+	for _, line := range bytesToStrings(stderr) {
+		addMessage(line)
+	}
+	return report
+}
+
+// FsckWithReport verifies the connectivity and validity of all objects in the
+// database for the repository, and returns the problems found as a report. A
+// non-nil error means the verification itself could not be completed, while
+// problems of the repository are only reported in the report.
+func (r *Repository) FsckWithReport(opts ...FsckOptions) (*FsckReport, error) {
+	var opt FsckOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	cmd := NewCommand("fsck", "--full", "--no-progress").AddOptions(opt.CommandOptions)
+	if opt.ConnectivityOnly {
+		cmd.AddArgs("--connectivity-only")
+	}
+
+	// The problems are reported to stderr, which is kept in full for parsing
+	// while only its tail goes into the error.
+	stdout := new(bytes.Buffer)
+	output := new(bytes.Buffer)
+	stderr := newTailBuffer(stderrLimit)
+	err := cmd.RunInDirPipelineWithTimeout(opt.Timeout, stdout, io.MultiWriter(output, stderr), r.path)
+	if err != nil {
+		// A non-zero exit status means problems have been found.
+		if _, ok := err.(*exec.ExitError); !ok {
+			return nil, concatenateError(err, stderr.String())
+		}
+	}
+	return parseFsckReport(stdout.Bytes(), output.Bytes()), nil
+}
+
+// RepackOptions contains optional arguments for repacking the objects.
+//
+// Docs: https://git-scm.com/docs/git-repack
+type RepackOptions struct {
+	// Indicates whether to pack everything into a single pack.
+	All bool
+	// Indicates whether to remove the packs that become redundant after
+	// repacking.
+	DeleteRedundant bool
+	// The factor to only combine packs into a geometric progression of their
+	// object counts, e.g. 2, which repacks incrementally rather than everything at
+	// once. It requires Git 2.32 or later.
+	Geometric int
+	// Indicates whether to write a multi-pack index covering the packs after
+	// repacking. It requires Git 2.34 or later.
+	WriteMidx bool
+	// Indicates whether to restrict deltas to objects within the same island, as
+	// configured by SetDeltaIslands. It requires Git 2.20 or later.
+	DeltaIslands bool
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// Repack packs the loose objects and combines the existing packs of the
+// repository. It returns ErrUnsupportedGitVersion if an option is not supported
+// by the Git version in use.
+func (r *Repository) Repack(opts ...RepackOptions) error {
+	var opt RepackOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	if opt.Geometric > 0 || opt.WriteMidx || opt.DeltaIslands {
+		version, err := BinVersion()
+		if err != nil {
+			return err
+		}
+		if opt.DeltaIslands && goversion.Compare(version, "2.20", "<") {
+			return ErrUnsupportedGitVersion
+		}
+		if opt.Geometric > 0 && goversion.Compare(version, "2.32", "<") {
+			return ErrUnsupportedGitVersion
+		}
+		if opt.WriteMidx && goversion.Compare(version, "2.34", "<") {
+			return ErrUnsupportedGitVersion
+		}
+	}
+
+	cmd := NewCommand("repack").AddOptions(opt.CommandOptions)
+	if opt.All {
+		cmd.AddArgs("-a")
+	}
+	if opt.DeleteRedundant {
+		cmd.AddArgs("-d")
+	}
+	if opt.Geometric > 0 {
+		cmd.AddArgs("--geometric=" + strconv.Itoa(opt.Geometric))
+	}
+	if opt.WriteMidx {
+		cmd.AddArgs("--write-midx")
+	}
+	if opt.DeltaIslands {
+		cmd.AddArgs("--delta-islands")
+	}
+	_, err := cmd.RunInDirWithTimeout(opt.Timeout, r.path)
+	return err
+}
+
+// DefaultGCTimeout is the default timeout duration for garbage collection,
+// which takes much longer than most commands on large repositories.
+const DefaultGCTimeout = 10 * time.Minute
+
+// GCOptions contains optional arguments for garbage collection.
+//
+// Docs: https://git-scm.com/docs/git-gc
+type GCOptions struct {
+	// Indicates whether to optimize the repository more aggressively at the
+	// expense of taking much more time.
+	Aggressive bool
+	// The age of unreachable loose objects to be pruned, e.g. 24*time.Hour prunes
+	// those older than one day. Git's default (two weeks) is used when not set.
+	Prune time.Duration
+	// Indicates whether to only run garbage collection when Git considers it
+	// necessary, e.g. there are too many loose objects.
+	Auto bool
+	// The timeout duration before giving up for each shell command execution.
+	// DefaultGCTimeout will be used when neither this nor CommandOptions.Timeout
+	// is supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// GC cleans up unnecessary files and optimizes the repository. CountObjects
+// helps to decide whether it is worthwhile.
+func (r *Repository) GC(opts ...GCOptions) error {
+	var opt GCOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	cmd := NewCommand("gc", "--quiet").AddOptions(opt.CommandOptions)
+	if opt.Aggressive {
+		cmd.AddArgs("--aggressive")
+	}
+	if opt.Prune > 0 {
+		cmd.AddArgs(fmt.Sprintf("--prune=%d.seconds.ago", int64(opt.Prune/time.Second)))
+	}
+	if opt.Auto {
+		cmd.AddArgs("--auto")
+	}
+
+	timeout := opt.Timeout
+	if timeout == 0 && opt.CommandOptions.Timeout == 0 {
+		timeout = DefaultGCTimeout
+	}
+	_, err := cmd.RunInDirWithTimeout(timeout, r.path)
+	return err
+}
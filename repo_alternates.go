@@ -0,0 +1,128 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// alternatesPath returns the path of the file that lists the alternate object
+// directories of the repository.
+func (r *Repository) alternatesPath() (string, error) {
+	dir, err := r.commonDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "objects", "info", "alternates"), nil
+}
+
+// parseAlternates parses the content of the "objects/info/alternates" file,
+// which has one path on each line. Empty lines and comments are skipped.
+func parseAlternates(data []byte) []string {
+	alternates := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		alternates = append(alternates, line)
+	}
+	return alternates
+}
+
+// Alternates returns the list of object directories that the repository
+// borrows objects from, e.g. the source repository of a shared clone. The paths
+// are returned as written, where a relative path is relative to the object
+// directory of the repository. It returns an empty list if there is none.
+func (r *Repository) Alternates() ([]string, error) {
+	fpath, err := r.alternatesPath()
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	return parseAlternates(p), nil
+}
+
+// writeAlternates writes the list of alternates to the file, which is removed
+// when the list is empty.
+func writeAlternates(fpath string, alternates []string) error {
+	if len(alternates) == 0 {
+		err := os.Remove(fpath)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	err := os.MkdirAll(filepath.Dir(fpath), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fpath, []byte(strings.Join(alternates, "\n")+"\n"), 0644)
+}
+
+// AddAlternate adds the object directory (e.g. "/path/to/repo.git/objects") to
+// the list of alternates of the repository. A relative path is relative to the
+// object directory of the repository. It returns ErrNotObjectDirectory if the
+// path is not an object directory. It does nothing if the path is already in
+// the list.
+func (r *Repository) AddAlternate(path string) error {
+	fpath, err := r.alternatesPath()
+	if err != nil {
+		return err
+	}
+
+	dir := path
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(filepath.Dir(fpath)), dir)
+	}
+	if !isDir(filepath.Join(dir, "pack")) || !isDir(filepath.Join(dir, "info")) {
+		return ErrNotObjectDirectory
+	}
+
+	alternates, err := r.Alternates()
+	if err != nil {
+		return err
+	}
+	for _, alt := range alternates {
+		if alt == path {
+			return nil
+		}
+	}
+	return writeAlternates(fpath, append(alternates, path))
+}
+
+// RemoveAlternate removes the path from the list of alternates of the
+// repository. The path must be given as returned by Alternates. It returns
+// ErrAlternateNotExist if the path is not in the list. Objects that are only
+// available from the alternate become missing, copy them first if needed, e.g.
+// with Repack.
+func (r *Repository) RemoveAlternate(path string) error {
+	fpath, err := r.alternatesPath()
+	if err != nil {
+		return err
+	}
+
+	alternates, err := r.Alternates()
+	if err != nil {
+		return err
+	}
+	for i, alt := range alternates {
+		if alt == path {
+			return writeAlternates(fpath, append(alternates[:i], alternates[i+1:]...))
+		}
+	}
+	return ErrAlternateNotExist
+}
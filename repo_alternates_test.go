@@ -0,0 +1,95 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseAlternates(t *testing.T) {
+	data := "/path/to/a.git/objects\n\n# comment\n../../b.git/objects\n"
+	assert.Equal(t, []string{"/path/to/a.git/objects", "../../b.git/objects"}, parseAlternates([]byte(data)))
+	assert.Empty(t, parseAlternates(nil))
+}
+
+func TestRepository_Alternates(t *testing.T) {
+	path := tempPath()
+	defer func() {
+		_ = os.RemoveAll(path)
+	}()
+
+	err := CloneShared(testrepo.Path(), path, CloneSharedOptions{Bare: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alternates, err := r.Alternates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, alternates, 1) {
+		assert.Equal(t, filepath.Join(testrepo.Path(), "objects"), alternates[0])
+	}
+	origin := alternates[0]
+
+	t.Run("not an object directory", func(t *testing.T) {
+		assert.Equal(t, ErrNotObjectDirectory, r.AddAlternate(testrepo.Path()))
+		assert.Equal(t, ErrNotObjectDirectory, r.AddAlternate("404"))
+	})
+
+	t.Run("add", func(t *testing.T) {
+		other, cleanup, err := setupTempRepo()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cleanup()
+		otherObjects := filepath.Join(other.Path(), ".git", "objects")
+
+		err = r.AddAlternate(otherObjects)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Adding the same path again does nothing.
+		err = r.AddAlternate(otherObjects)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		alternates, err := r.Alternates()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, []string{origin, otherObjects}, alternates)
+
+		err = r.RemoveAlternate(otherObjects)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		assert.Equal(t, ErrAlternateNotExist, r.RemoveAlternate("404"))
+
+		err := r.RemoveAlternate(origin)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		alternates, err := r.Alternates()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Empty(t, alternates)
+		assert.False(t, isExist(filepath.Join(path, "objects", "info", "alternates")))
+	})
+}
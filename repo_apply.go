@@ -0,0 +1,98 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"time"
+)
+
+// ApplyPatchOptions contains optional arguments for applying a patch.
+//
+// Docs: https://git-scm.com/docs/git-apply
+type ApplyPatchOptions struct {
+	// Indicates whether to apply the patch to both the index and the working
+	// tree.
+	Index bool
+	// Indicates whether to apply the patch in reverse, i.e. to un-apply it.
+	Reverse bool
+	// Indicates whether to apply the hunks that apply cleanly and leave the
+	// rejected hunks in corresponding ".rej" files, instead of failing without
+	// touching anything.
+	Reject bool
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// parseApplyRejects returns the paths of ".rej" files reported in the output
+// of "git apply --reject", e.g. "Applying patch README.txt with 1 reject...".
+func parseApplyRejects(stderr []byte) []string {
+	const prefix = "Applying patch "
+	var files []string
+	for _, line := range bytesToStrings(stderr) {
+		if !strings.HasPrefix(line, prefix) {
+			continue
+		}
+
+		i := strings.LastIndex(line, " with ")
+		if i < len(prefix) || !strings.Contains(line[i:], " reject") {
+			continue
+		}
+		files = append(files, line[len(prefix):i]+".rej")
+	}
+	return files
+}
+
+// ApplyPatch applies the patch to the working tree of the repository. When
+// ApplyPatchOptions.Reject is set and some hunks were rejected, it returns a
+// *PartialApplyError with the paths of the ".rej" files.
+func (r *Repository) ApplyPatch(patch []byte, opts ...ApplyPatchOptions) error {
+	var opt ApplyPatchOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	cmd := NewCommand("apply").AddOptions(opt.CommandOptions)
+	if opt.Index {
+		cmd.AddArgs("--index")
+	}
+	if opt.Reverse {
+		cmd.AddArgs("--reverse")
+	}
+	if opt.Reject {
+		cmd.AddArgs("--reject")
+	}
+	cmd.AddArgs("-")
+	if opt.Timeout != 0 {
+		cmd = cmd.WithTimeout(opt.Timeout)
+	}
+
+	// The rejected hunks are reported to stderr, which is kept in full for
+	// parsing while only its tail goes into the error.
+	output := new(bytes.Buffer)
+	stderr := newTailBuffer(stderrLimit)
+	err := cmd.RunInDirWithOptions(r.path, RunInDirOptions{
+		Stdin:  bytes.NewReader(patch),
+		Stdout: new(bytes.Buffer),
+		Stderr: io.MultiWriter(output, stderr),
+	})
+	if err == nil {
+		return nil
+	}
+
+	if opt.Reject && err != ErrExecTimeout {
+		if files := parseApplyRejects(output.Bytes()); len(files) > 0 {
+			return &PartialApplyError{RejectFiles: files}
+		}
+	}
+	return concatenateError(err, stderr.String())
+}
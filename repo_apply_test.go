@@ -0,0 +1,120 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseApplyRejects(t *testing.T) {
+	stderr := `Checking patch a.txt...
+error: patch failed: a.txt:1
+Checking patch dir/with space.txt...
+Checking patch b.txt...
+Applying patch a.txt with 1 reject...
+Rejected hunk #1.
+Applying patch dir/with space.txt with 2 rejects...
+Rejected hunk #1.
+Rejected hunk #2.
+Applied patch b.txt cleanly.
+`
+	assert.Equal(t,
+		[]string{"a.txt.rej", "dir/with space.txt.rej"},
+		parseApplyRejects([]byte(stderr)),
+	)
+}
+
+func TestRepository_ApplyPatch(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	for _, file := range []string{"a.txt", "b.txt"} {
+		err = ioutil.WriteFile(filepath.Join(r.Path(), file), []byte("1\n2\n3\n"), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = commitFile(r, "c.txt", "1\n2\n3\n", "Add files")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	patch := []byte(`diff --git a/a.txt b/a.txt
+--- a/a.txt
++++ b/a.txt
+@@ -1,3 +1,3 @@
+ 1
+-2
++two
+ 3
+diff --git a/b.txt b/b.txt
+--- a/b.txt
++++ b/b.txt
+@@ -1,3 +1,3 @@
+ 1
+-2
++two
+ 3
+`)
+	readFile := func(name string) string {
+		p, err := ioutil.ReadFile(filepath.Join(r.Path(), name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(p)
+	}
+
+	t.Run("apply and reverse", func(t *testing.T) {
+		err := r.ApplyPatch(patch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "1\ntwo\n3\n", readFile("a.txt"))
+		assert.Equal(t, "1\ntwo\n3\n", readFile("b.txt"))
+
+		err = r.ApplyPatch(patch, ApplyPatchOptions{Reverse: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "1\n2\n3\n", readFile("a.txt"))
+		assert.Equal(t, "1\n2\n3\n", readFile("b.txt"))
+	})
+
+	t.Run("conflict without reject", func(t *testing.T) {
+		err := ioutil.WriteFile(filepath.Join(r.Path(), "a.txt"), []byte("1\nchanged\n3\n"), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = r.ApplyPatch(patch)
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, ErrPartialApply))
+
+		// Nothing is applied when any of the hunks fails.
+		assert.Equal(t, "1\n2\n3\n", readFile("b.txt"))
+	})
+
+	t.Run("conflict with reject", func(t *testing.T) {
+		err := r.ApplyPatch(patch, ApplyPatchOptions{Reject: true})
+		var partialErr *PartialApplyError
+		if !assert.True(t, errors.As(err, &partialErr)) {
+			return
+		}
+		assert.Equal(t, []string{"a.txt.rej"}, partialErr.RejectFiles)
+		assert.True(t, errors.Is(err, ErrPartialApply))
+
+		assert.Equal(t, "1\nchanged\n3\n", readFile("a.txt"))
+		assert.Equal(t, "1\ntwo\n3\n", readFile("b.txt"))
+		assert.Contains(t, readFile("a.txt.rej"), "+two")
+	})
+}
@@ -0,0 +1,175 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// BatchReader reads objects from a long-lived "git cat-file --batch" process,
+// which avoids spawning a Git process for every object. It is safe for
+// concurrent use by multiple goroutines.
+type BatchReader struct {
+	mu     sync.Mutex
+	stdin  *io.PipeWriter
+	stdout *bufio.Reader
+	err    error // The error that makes the process unusable.
+
+	closeStdout func() error
+	cancel      context.CancelFunc
+	done        <-chan struct{}
+}
+
+// NewBatchReaderOptions contains optional arguments for starting a batch
+// reader.
+//
+// Docs: https://git-scm.com/docs/git-cat-file#Documentation/git-cat-file.txt---batch
+type NewBatchReaderOptions struct {
+	// The additional options to be passed to the underlying git. The process is
+	// not subject to any timeout unless CommandOptions.Timeout is set.
+	CommandOptions
+}
+
+// errBatchReaderClosed is returned when reading from a closed batch reader.
+var errBatchReaderClosed = errors.New("batch reader is closed")
+
+// NewBatchReader starts a "git cat-file --batch" process in the repository.
+// The returned batch reader must be closed to stop the process.
+func (r *Repository) NewBatchReader(opts ...NewBatchReaderOptions) (*BatchReader, error) {
+	var opt NewBatchReaderOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	ctx := opt.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+
+	cmd := NewCommand("cat-file").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--batch").
+		WithContext(ctx)
+	if opt.Timeout == 0 {
+		// The process lives until the batch reader is closed.
+		cmd.SetTimeout(-1)
+	}
+
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		stderr := newTailBuffer(stderrLimit)
+		err := cmd.RunInDirWithOptions(r.path, RunInDirOptions{
+			Stdin:  stdinReader,
+			Stdout: stdoutWriter,
+			Stderr: stderr,
+		})
+		if err != nil {
+			err = concatenateError(err, stderr.String())
+		} else {
+			err = errBatchReaderClosed
+		}
+		_ = stdinReader.CloseWithError(err)
+		_ = stdoutWriter.CloseWithError(err)
+	}()
+
+	return &BatchReader{
+		stdin:       stdinWriter,
+		stdout:      bufio.NewReader(stdoutReader),
+		closeStdout: stdoutReader.Close,
+		cancel:      cancel,
+		done:        done,
+	}, nil
+}
+
+// Read returns the type and content of the object with given ID, which can be
+// anything that Git can resolve to an object. It returns ErrObjectNotExist if
+// the object does not exist, and the process is kept for subsequent reads.
+func (b *BatchReader) Read(id string) (ObjectType, []byte, error) {
+	if id == "" || strings.ContainsAny(id, "\r\n") {
+		return "", nil, ErrObjectNotExist
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.err != nil {
+		return "", nil, b.err
+	}
+
+	typ, data, err := b.read(id)
+	if err != nil && err != ErrObjectNotExist {
+		// The output is out of sync with the input, nothing can be read anymore.
+		b.err = err
+	}
+	return typ, data, err
+}
+
+// read sends the object ID to the process and reads the response in the form
+// of "<id> <type> <size>\n<content>\n", or "<id> missing\n" when the object
+// does not exist.
+func (b *BatchReader) read(id string) (ObjectType, []byte, error) {
+	_, err := io.WriteString(b.stdin, id+"\n")
+	if err != nil {
+		return "", nil, err
+	}
+
+	header, err := b.stdout.ReadString('\n')
+	if err != nil {
+		return "", nil, err
+	}
+	header = strings.TrimSuffix(header, "\n")
+	if strings.HasSuffix(header, " missing") || strings.HasSuffix(header, " ambiguous") {
+		return "", nil, ErrObjectNotExist
+	}
+
+	fields := strings.Fields(header)
+	if len(fields) != 3 {
+		return "", nil, fmt.Errorf("malformed header: %q", header)
+	}
+	size, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return "", nil, fmt.Errorf("parse size %q: %v", fields[2], err)
+	}
+
+	// The content is followed by a line feed.
+	data := make([]byte, size+1)
+	_, err = io.ReadFull(b.stdout, data)
+	if err != nil {
+		return "", nil, err
+	}
+	return ObjectType(fields[1]), data[:size], nil
+}
+
+// Close stops the process and waits for it to exit. Subsequent reads return an
+// error.
+func (b *BatchReader) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.err == errBatchReaderClosed {
+		return nil
+	}
+	b.err = errBatchReaderClosed
+
+	// Closing stdin makes the process exit on its own, the context is canceled
+	// as well in case it is stuck on writing to stdout.
+	err := b.stdin.Close()
+	_ = b.closeStdout()
+	b.cancel()
+	<-b.done
+	return err
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchReader(t *testing.T) {
+	b, err := testrepo.NewBatchReader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		assert.Nil(t, b.Close())
+	}()
+
+	tests := []struct {
+		rev     string
+		expType ObjectType
+	}{
+		{rev: "master", expType: ObjectCommit},
+		{rev: "master^{tree}", expType: ObjectTree},
+		{rev: "master:README.txt", expType: ObjectBlob},
+		{rev: "v1.1.0", expType: ObjectTag},
+	}
+	for _, test := range tests {
+		t.Run(test.rev, func(t *testing.T) {
+			id, err := testrepo.RevParse(test.rev)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expData, err := NewCommand("cat-file", string(test.expType), id).RunInDir(testrepo.Path())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			typ, data, err := b.Read(id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, test.expType, typ)
+			assert.Equal(t, string(expData), string(data))
+		})
+	}
+
+	t.Run("object does not exist", func(t *testing.T) {
+		for _, id := range []string{EmptyID, "", "multiple\nlines"} {
+			_, _, err := b.Read(id)
+			assert.Equal(t, ErrObjectNotExist, err)
+		}
+
+		// The process is still usable afterwards.
+		typ, _, err := b.Read("master")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, ObjectCommit, typ)
+	})
+
+	t.Run("concurrent reads", func(t *testing.T) {
+		blobID, err := testrepo.RevParse("master:README.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		expData, err := NewCommand("cat-file", "blob", blobID).RunInDir(testrepo.Path())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				_, data, err := b.Read(blobID)
+				assert.Nil(t, err)
+				assert.Equal(t, string(expData), string(data))
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestBatchReader_Close(t *testing.T) {
+	b, err := testrepo.NewBatchReader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, b.Close())
+	assert.Nil(t, b.Close())
+
+	_, _, err = b.Read("master")
+	assert.Equal(t, errBatchReaderClosed, err)
+}
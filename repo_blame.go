@@ -5,13 +5,34 @@
 package git
 
 import (
-	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
+
+	goversion "github.com/mcuadros/go-version"
 )
 
 // BlameOptions contains optional arguments for blaming a file.
 // Docs: https://git-scm.com/docs/git-blame
 type BlameOptions struct {
+	// The list of revisions to ignore, e.g. commits that only reformatted code,
+	// so that lines are blamed to the commits that changed them before. It is
+	// ignored when the Git version is below 2.23.
+	IgnoreRevs []string
+	// The relative path of the file that lists revisions to ignore, e.g.
+	// ".git-blame-ignore-revs". It is ignored when the Git version is below 2.23.
+	IgnoreRevsFile string
+	// The line number (1-based) of the first line to blame, the blame starts
+	// from the first line when not positive.
+	StartLine int
+	// The line number (1-based) of the last line to blame, the blame ends at the
+	// last line when not positive.
+	EndLine int
+	// Indicates whether to detect lines that are moved or copied within and
+	// across files, so that they are blamed to the commits that originally
+	// added them.
+	FollowMoves bool
 	// The timeout duration before giving up for each shell command execution. The
 	// default timeout duration will be used when not supplied.
 	//
@@ -21,41 +42,244 @@ type BlameOptions struct {
 	CommandOptions
 }
 
+// ignoreRevsArgs returns the arguments to ignore revisions when the Git version
+// supports it.
+func (opt BlameOptions) ignoreRevsArgs() ([]string, error) {
+	if len(opt.IgnoreRevs) == 0 && opt.IgnoreRevsFile == "" {
+		return nil, nil
+	}
+
+	version, err := BinVersion()
+	if err != nil {
+		return nil, err
+	} else if goversion.Compare(version, "2.23", "<") {
+		return nil, nil
+	}
+
+	args := make([]string, 0, len(opt.IgnoreRevs)+1)
+	for _, rev := range opt.IgnoreRevs {
+		args = append(args, "--ignore-rev="+rev)
+	}
+	if opt.IgnoreRevsFile != "" {
+		args = append(args, "--ignore-revs-file="+opt.IgnoreRevsFile)
+	}
+	return args, nil
+}
+
+// extraArgs returns the arguments for the line range and detecting moved lines.
+func (opt BlameOptions) extraArgs() []string {
+	var args []string
+	if opt.StartLine > 0 || opt.EndLine > 0 {
+		start := "1"
+		if opt.StartLine > 0 {
+			start = strconv.Itoa(opt.StartLine)
+		}
+		end := ""
+		if opt.EndLine > 0 {
+			end = strconv.Itoa(opt.EndLine)
+		}
+		args = append(args, "-L", start+","+end)
+	}
+	if opt.FollowMoves {
+		args = append(args, "-M", "-C")
+	}
+	return args
+}
+
 // BlameFile returns blame results of the file with the given revision of the
-// repository.
+// repository, where the commit of each line is loaded up front.
 func (r *Repository) BlameFile(rev, file string, opts ...BlameOptions) (*Blame, error) {
 	var opt BlameOptions
 	if len(opts) > 0 {
 		opt = opts[0]
 	}
 
-	stdout, err := NewCommand("blame").
-		AddOptions(opt.CommandOptions).
-		AddArgs("-l", "-s", rev, "--", file).
-		RunInDirWithTimeout(opt.Timeout, r.path)
+	blame, err := r.Blame(rev, file, opt)
 	if err != nil {
 		return nil, err
 	}
 
-	lines := bytes.Split(stdout, []byte{'\n'})
-	blame := &Blame{
-		lines: make([]*Commit, 0, len(lines)),
-	}
-	for _, line := range lines {
-		if len(line) < 40 {
-			break
+	commits := make(map[string]*Commit)
+	blame.lines = make([]*Commit, 0, len(blame.records))
+	for _, record := range blame.records {
+		id := record.CommitID.String()
+		commit, ok := commits[id]
+		if !ok {
+			commit, err = r.CatFileCommit(id, CatFileCommitOptions{Timeout: opt.Timeout}) //nolint
+			if err != nil {
+				return nil, err
+			}
+			commits[id] = commit
 		}
-		id := line[:40]
+		blame.lines = append(blame.lines, commit)
+	}
+	return blame, nil
+}
+
+// BlameSummary returns blame results of the file with the given revision of the
+// repository, where consecutive lines that are last changed by the same commit
+// are grouped into a chunk. The returned list is in the order of line numbers.
+func (r *Repository) BlameSummary(rev, file string, opts ...BlameOptions) ([]*BlameChunk, error) {
+	var opt BlameOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
 
-		// Earliest commit is indicated by a leading "^"
-		if id[0] == '^' {
-			id = id[1:]
+	blame, err := r.Blame(rev, file, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make([]*BlameChunk, 0)
+	var last *BlameChunk
+	for _, line := range blame.records {
+		if last != nil &&
+			last.Commit.ID.Equal(line.CommitID) &&
+			last.StartLine+last.NumLines == line.Number {
+			last.NumLines++
+			continue
 		}
-		commit, err := r.CatFileCommit(string(id), CatFileCommitOptions{Timeout: opt.Timeout}) //nolint
+
+		commit, err := r.CatFileCommit(line.CommitID.String(), CatFileCommitOptions{Timeout: opt.Timeout}) //nolint
 		if err != nil {
 			return nil, err
 		}
-		blame.lines = append(blame.lines, commit)
+		last = &BlameChunk{
+			StartLine: line.Number,
+			NumLines:  1,
+			Commit:    commit,
+		}
+		chunks = append(chunks, last)
 	}
-	return blame, nil
+	return chunks, nil
+}
+
+// parseBlameLines parses the output of "git blame --porcelain". Each line starts
+// with a header of "<commit> <original line> <final line>[ <lines>]", followed
+// by the information of the commit when it is the first time the commit is
+// shown, and then the content of the line that starts with a TAB.
+func parseBlameLines(data []byte) ([]*BlameLine, error) {
+	type commitInfo struct {
+		author *Signature
+		path   string
+	}
+	commits := make(map[string]*commitInfo)
+
+	records := []*BlameLine{}
+	var cur *BlameLine
+	var info *commitInfo
+	for _, line := range bytesToStrings(data) {
+		if cur == nil {
+			if line == "" {
+				continue
+			}
+
+			fields := strings.Fields(line)
+			if len(fields) < 3 || len(fields) > 4 {
+				return nil, fmt.Errorf("malformed header: %q", line)
+			}
+			id, err := NewIDFromString(fields[0])
+			if err != nil {
+				return nil, fmt.Errorf("parse commit ID %q: %v", line, err)
+			}
+			number, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return nil, fmt.Errorf("parse final line number %q: %v", line, err)
+			}
+
+			cur = &BlameLine{
+				Number:   number,
+				CommitID: id,
+			}
+			info = commits[fields[0]]
+			if info == nil {
+				info = &commitInfo{author: &Signature{}}
+				commits[fields[0]] = info
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, "\t") {
+			cur.Author = info.author
+			cur.Path = info.path
+			cur.Content = line[1:]
+			records = append(records, cur)
+			cur = nil
+			continue
+		}
+
+		key, value := line, ""
+		if i := strings.IndexByte(line, ' '); i >= 0 {
+			key, value = line[:i], line[i+1:]
+		}
+		switch key {
+		case "author":
+			info.author.Name = value
+		case "author-mail":
+			info.author.Email = strings.TrimSuffix(strings.TrimPrefix(value, "<"), ">")
+		case "author-time":
+			sec, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parse author time %q: %v", line, err)
+			}
+			info.author.When = time.Unix(sec, 0)
+		case "author-tz":
+			loc, err := parseTimezone(value)
+			if err != nil {
+				return nil, err
+			}
+			info.author.When = info.author.When.In(loc)
+		case "filename":
+			info.path = value
+		}
+	}
+	if cur != nil {
+		return nil, fmt.Errorf("no content for line %d", cur.Number)
+	}
+	return records, nil
+}
+
+// parseTimezone parses the timezone in the form of "+0800".
+func parseTimezone(tz string) (*time.Location, error) {
+	t, err := time.Parse("-0700", tz)
+	if err != nil {
+		return nil, fmt.Errorf("parse timezone %q: %v", tz, err)
+	}
+	_, offset := t.Zone()
+	return time.FixedZone("", offset), nil
+}
+
+// Blame returns the blame information of each line of the file with the given
+// revision of the repository, including the commit ID and the author, using a
+// single Git command.
+func (r *Repository) Blame(rev, file string, opts ...BlameOptions) (*Blame, error) {
+	var opt BlameOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	ignoreRevsArgs, err := opt.ignoreRevsArgs()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, err := NewCommand("blame").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--porcelain").
+		AddArgs(ignoreRevsArgs...).
+		AddArgs(opt.extraArgs()...).
+		AddArgs(rev, "--", file).
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := parseBlameLines(stdout)
+	if err != nil {
+		return nil, err
+	}
+	return &Blame{
+		records: records,
+		repo:    r,
+	}, nil
 }
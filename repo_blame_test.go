@@ -6,8 +6,11 @@ package git
 
 import (
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 
+	goversion "github.com/mcuadros/go-version"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -38,3 +41,347 @@ func TestRepository_BlameFile(t *testing.T) {
 		})
 	}
 }
+
+func TestRepository_BlameFile_lineRange(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	var ids []string
+	for _, content := range []string{
+		"1\n2\n3\n",
+		"1\n2 changed\n3\n",
+	} {
+		err = commitFile(r, "blame.txt", content, "Change blame.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		id, err := r.RevParse("HEAD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+
+	blame, err := r.BlameFile("HEAD", "blame.txt", BlameOptions{StartLine: 2, EndLine: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, blame.Line(1))
+	assert.Equal(t, ids[1], blame.Line(2).ID.String())
+	assert.Equal(t, ids[0], blame.Line(3).ID.String())
+	assert.Nil(t, blame.Line(4))
+	assert.Len(t, blame.Lines(), 2)
+}
+
+func TestRepository_BlameSummary(t *testing.T) {
+	t.Run("bad file", func(t *testing.T) {
+		_, err := testrepo.BlameSummary("HEAD", "404.txt")
+		assert.Error(t, err)
+	})
+
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	var ids []string
+	for _, content := range []string{
+		"1\n2\n3\n",
+		"1\n2 changed\n3\n",
+		"1\n2 changed\n3\n4\n5\n",
+	} {
+		err = commitFile(r, "blame.txt", content, "Change blame.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		id, err := r.RevParse("HEAD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+
+	chunks, err := r.BlameSummary("HEAD", "blame.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type chunk struct {
+		startLine int
+		numLines  int
+		id        string
+	}
+	got := make([]chunk, len(chunks))
+	for i, c := range chunks {
+		got[i] = chunk{
+			startLine: c.StartLine,
+			numLines:  c.NumLines,
+			id:        c.Commit.ID.String(),
+		}
+	}
+
+	exp := []chunk{
+		{startLine: 1, numLines: 1, id: ids[0]},
+		{startLine: 2, numLines: 1, id: ids[1]},
+		{startLine: 3, numLines: 1, id: ids[0]},
+		{startLine: 4, numLines: 2, id: ids[2]},
+	}
+	assert.Equal(t, exp, got)
+}
+
+func TestRepository_Blame_ignoreRevs(t *testing.T) {
+	version, err := BinVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if goversion.Compare(version, "2.23", "<") {
+		t.Skip("Git version does not support ignoring revisions")
+	}
+
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	var ids []string
+	for _, content := range []string{
+		"a\nb\nc\n",
+		"A\nB\nC\n", // Reformatting
+	} {
+		err = commitFile(r, "blame.txt", content, "Change blame.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		id, err := r.RevParse("HEAD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+
+	err = ioutil.WriteFile(filepath.Join(r.Path(), ".git-blame-ignore-revs"), []byte("# Reformatting\n"+ids[1]+"\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		opt   BlameOptions
+		expID string
+	}{
+		{
+			name:  "none",
+			expID: ids[1],
+		},
+		{
+			name:  "revisions",
+			opt:   BlameOptions{IgnoreRevs: []string{ids[1]}},
+			expID: ids[0],
+		},
+		{
+			name:  "file",
+			opt:   BlameOptions{IgnoreRevsFile: ".git-blame-ignore-revs"},
+			expID: ids[0],
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			blame, err := r.BlameFile("HEAD", "blame.txt", test.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 1; i <= 3; i++ {
+				assert.Equal(t, test.expID, blame.Line(i).ID.String(), "line %d", i)
+			}
+
+			chunks, err := r.BlameSummary("HEAD", "blame.txt", test.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if assert.Len(t, chunks, 1) {
+				assert.Equal(t, 3, chunks[0].NumLines)
+				assert.Equal(t, test.expID, chunks[0].Commit.ID.String())
+			}
+		})
+	}
+}
+
+func Test_parseBlameLines(t *testing.T) {
+	const (
+		id1 = "755fd577edcfd9209d0ac072eed3b022cbe4d39b"
+		id2 = "a13dba1e469944772490909daa58c53ac8fa4b0d"
+	)
+	data := id1 + ` 1 1 2
+author alice
+author-mail <alice@example.com>
+author-time 1581602099
+author-tz +0800
+committer alice
+summary Add README.txt
+filename README.txt
+	first line
+` + id1 + ` 2 2
+	
+` + id2 + ` 1 3 1
+author bob
+author-mail <bob@example.com>
+author-time 1581602199
+author-tz -0130
+previous ` + id1 + ` README.txt
+filename OLD.txt
+	` + id2 + ` 3 3 looks like a header
+`
+	lines, err := parseBlameLines([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("Should have three lines but got %d", len(lines))
+	}
+
+	alice := lines[0].Author
+	assert.Equal(t, "alice", alice.Name)
+	assert.Equal(t, "alice@example.com", alice.Email)
+	assert.Equal(t, int64(1581602099), alice.When.Unix())
+	_, offset := alice.When.Zone()
+	assert.Equal(t, 8*60*60, offset)
+
+	bob := lines[2].Author
+	assert.Equal(t, "bob", bob.Name)
+	_, offset = bob.When.Zone()
+	assert.Equal(t, -90*60, offset)
+
+	exp := []*BlameLine{
+		{Number: 1, CommitID: MustIDFromString(id1), Author: alice, Path: "README.txt", Content: "first line"},
+		{Number: 2, CommitID: MustIDFromString(id1), Author: alice, Path: "README.txt", Content: ""},
+		{Number: 3, CommitID: MustIDFromString(id2), Author: bob, Path: "OLD.txt", Content: id2 + " 3 3 looks like a header"},
+	}
+	assert.Equal(t, exp, lines)
+
+	// The author is shared by lines of the same commit.
+	assert.Same(t, lines[0].Author, lines[1].Author)
+
+	for _, data := range []string{
+		"bad 1 1\n\tline\n",
+		id1 + " 1\n\tline\n",
+		id1 + " 1 bad\n\tline\n",
+		id1 + " 1 1\nauthor-time bad\n\tline\n",
+		id1 + " 1 1\nauthor-tz bad\n\tline\n",
+		id1 + " 1 1\n",
+	} {
+		_, err = parseBlameLines([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestRepository_Blame(t *testing.T) {
+	t.Run("bad file", func(t *testing.T) {
+		_, err := testrepo.Blame("HEAD", "404.txt")
+		assert.Error(t, err)
+	})
+
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	var ids []string
+	for _, content := range []string{
+		"1\n2\n3\n",
+		"1\n2 changed\n3\n",
+		"1\n2 changed\n3\n4\n5\n",
+	} {
+		err = commitFile(r, "blame.txt", content, "Change blame.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		id, err := r.RevParse("HEAD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+
+	type line struct {
+		number  int
+		id      string
+		content string
+	}
+	toLines := func(blame *Blame) []line {
+		lines := make([]line, len(blame.Lines()))
+		for i, l := range blame.Lines() {
+			assert.Equal(t, "alice", l.Author.Name)
+			assert.Equal(t, "blame.txt", l.Path)
+			lines[i] = line{number: l.Number, id: l.CommitID.String(), content: l.Content}
+		}
+		return lines
+	}
+
+	blame, err := r.Blame("HEAD", "blame.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []line{
+		{number: 1, id: ids[0], content: "1"},
+		{number: 2, id: ids[1], content: "2 changed"},
+		{number: 3, id: ids[0], content: "3"},
+		{number: 4, id: ids[2], content: "4"},
+		{number: 5, id: ids[2], content: "5"},
+	}, toLines(blame))
+	assert.Equal(t, ids[1], blame.Line(2).ID.String())
+	assert.Nil(t, blame.Line(6))
+
+	t.Run("line range", func(t *testing.T) {
+		blame, err := r.Blame("HEAD", "blame.txt", BlameOptions{StartLine: 2, EndLine: 4})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, []line{
+			{number: 2, id: ids[1], content: "2 changed"},
+			{number: 3, id: ids[0], content: "3"},
+			{number: 4, id: ids[2], content: "4"},
+		}, toLines(blame))
+		assert.Equal(t, ids[0], blame.Line(3).ID.String())
+		assert.Nil(t, blame.Line(1))
+		assert.Nil(t, blame.Line(5))
+	})
+
+	t.Run("follow moves", func(t *testing.T) {
+		// Moved lines must have enough alphanumeric characters to be detected.
+		const (
+			block = "the first line that is moved\nthe second line that is moved\n"
+			rest  = "a\nb\nc\n"
+		)
+		err := commitFile(r, "moves.txt", block+rest, "Add moves.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, err := r.RevParse("HEAD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = commitFile(r, "moves.txt", rest+block, "Move lines")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		blame, err := r.Blame("HEAD", "moves.txt", BlameOptions{StartLine: 4, FollowMoves: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, l := range blame.Lines() {
+			assert.Equal(t, id, l.CommitID.String(), "line %d", l.Number)
+		}
+		assert.Len(t, blame.Lines(), 2)
+	})
+}
@@ -4,7 +4,13 @@
 
 package git
 
-import "time"
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+)
 
 // CatFileBlobOptions contains optional arguments for verifying the objects.
 //
@@ -26,12 +32,20 @@ func (r *Repository) CatFileBlob(rev string, opts ...CatFileBlobOptions) (*Blob,
 		opt = opts[0]
 	}
 
-	rev, err := r.RevParse(rev, RevParseOptions{Timeout: opt.Timeout}) //nolint
+	// Environment variables are passed through so that objects in a quarantine
+	// directory can be resolved.
+	rev, err := r.RevParse(rev, RevParseOptions{
+		Timeout:        opt.Timeout, //nolint
+		CommandOptions: CommandOptions{Envs: opt.Envs},
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	typ, err := r.CatFileType(rev)
+	typ, err := r.CatFileType(rev, CatFileTypeOptions{
+		Timeout:        opt.Timeout, //nolint
+		CommandOptions: CommandOptions{Envs: opt.Envs},
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -51,3 +65,123 @@ func (r *Repository) CatFileBlob(rev string, opts ...CatFileBlobOptions) (*Blob,
 		},
 	}, nil
 }
+
+// CatFileBlobReader returns a reader of the content of the blob with given ID
+// and the size of the content, so that the content can be streamed rather than
+// loaded into memory all at once. The reader must be closed. It returns
+// ErrObjectNotExist if the object does not exist, or ErrNotBlob if the object
+// is not a blob. Any other error, e.g. a corrupt object, is returned as is.
+func (r *Repository) CatFileBlobReader(id string, opts ...CatFileBlobOptions) (io.ReadCloser, int64, error) {
+	var opt CatFileBlobOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	infos, missing, err := r.ObjectInfos([]string{id}, ObjectInfosOptions{
+		Timeout:        opt.Timeout, //nolint
+		CommandOptions: opt.CommandOptions,
+	})
+	if err != nil {
+		return nil, 0, err
+	} else if len(missing) > 0 {
+		return nil, 0, ErrObjectNotExist
+	}
+
+	info := infos[id]
+	if info.Type != ObjectBlob {
+		return nil, 0, ErrNotBlob
+	}
+
+	cmd := NewCommand("cat-file").
+		AddOptions(opt.CommandOptions).
+		AddArgs("blob", id)
+	if opt.Timeout != 0 { //nolint
+		cmd = cmd.WithTimeout(opt.Timeout) //nolint
+	}
+	return cmd.RunInDirReader(r.path), info.Size, nil
+}
+
+// ReadFileOptions contains optional arguments for reading the content of a
+// file.
+//
+// Docs: https://git-scm.com/docs/git-cat-file
+type ReadFileOptions struct {
+	// The maximum size of the file in bytes, no limit when not positive.
+	MaxSize int64
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// ReadFile returns the content of the file with given path (relative to the
+// repository root) in the revision. It returns ErrFileNotExist if the path does
+// not exist, ErrNotBlob if the path is not a file (e.g. a directory), or
+// ErrFileTooLarge if the size exceeds ReadFileOptions.MaxSize, in which case
+// the content is not read.
+func (r *Repository) ReadFile(rev, path string, opts ...ReadFileOptions) ([]byte, error) {
+	var opt ReadFileOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	rc, size, err := r.CatFileBlobReader(rev+":"+path, CatFileBlobOptions{
+		Timeout:        opt.Timeout, //nolint
+		CommandOptions: opt.CommandOptions,
+	})
+	if err != nil {
+		if err == ErrObjectNotExist {
+			return nil, ErrFileNotExist
+		}
+		return nil, err
+	}
+	defer func() { _ = rc.Close() }()
+
+	if opt.MaxSize > 0 && size > opt.MaxSize {
+		return nil, ErrFileTooLarge
+	}
+	return ioutil.ReadAll(rc)
+}
+
+// HashObjectOptions contains optional arguments for writing a blob.
+//
+// Docs: https://git-scm.com/docs/git-hash-object
+type HashObjectOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// HashObject writes the content as a blob to the object database and returns
+// the ID of the blob.
+func (r *Repository) HashObject(content io.Reader, opts ...HashObjectOptions) (string, error) {
+	var opt HashObjectOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	stdout := new(bytes.Buffer)
+	stderr := newTailBuffer(stderrLimit)
+	cmd := NewCommand("hash-object").
+		AddOptions(opt.CommandOptions).
+		AddArgs("-w", "--stdin")
+	if opt.Timeout != 0 {
+		cmd = cmd.WithTimeout(opt.Timeout)
+	}
+	err := cmd.RunInDirWithOptions(r.path, RunInDirOptions{
+		Stdin:  content,
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+	if err != nil {
+		return "", concatenateError(err, stderr.String())
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -5,6 +5,8 @@
 package git
 
 import (
+	"io/ioutil"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -29,3 +31,96 @@ func TestRepository_CatFileBlob(t *testing.T) {
 		assert.True(t, b.IsBlob())
 	})
 }
+
+func TestRepository_CatFileBlobReader(t *testing.T) {
+	blobID, err := testrepo.RevParse("master:README.txt")
+	require.NoError(t, err)
+	treeID, err := testrepo.RevParse("master^{tree}")
+	require.NoError(t, err)
+
+	t.Run("not a blob", func(t *testing.T) {
+		_, _, err := testrepo.CatFileBlobReader(treeID)
+		assert.Equal(t, ErrNotBlob, err)
+	})
+
+	t.Run("object does not exist", func(t *testing.T) {
+		_, _, err := testrepo.CatFileBlobReader("0000000000000000000000000000000000000001")
+		assert.Equal(t, ErrObjectNotExist, err)
+	})
+
+	rc, size, err := testrepo.CatFileBlobReader(blobID)
+	require.NoError(t, err)
+	defer func() {
+		_ = rc.Close()
+	}()
+
+	p, err := ioutil.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(p)), size)
+
+	blob, err := testrepo.CatFileBlob(blobID)
+	require.NoError(t, err)
+	exp, err := blob.Bytes()
+	require.NoError(t, err)
+	assert.Equal(t, exp, p)
+}
+
+func TestRepository_ReadFile(t *testing.T) {
+	p, err := testrepo.ReadFile("master", "src/main.go")
+	require.NoError(t, err)
+
+	stdout, err := NewCommand("cat-file", "blob", "master:src/main.go").RunInDir(testrepo.Path())
+	require.NoError(t, err)
+	assert.Equal(t, stdout, p)
+
+	t.Run("max size", func(t *testing.T) {
+		p, err := testrepo.ReadFile("master", "src/main.go", ReadFileOptions{MaxSize: int64(len(stdout))})
+		require.NoError(t, err)
+		assert.Equal(t, stdout, p)
+
+		_, err = testrepo.ReadFile("master", "src/main.go", ReadFileOptions{MaxSize: int64(len(stdout)) - 1})
+		assert.Equal(t, ErrFileTooLarge, err)
+	})
+
+	t.Run("not a file", func(t *testing.T) {
+		_, err := testrepo.ReadFile("master", "src")
+		assert.Equal(t, ErrNotBlob, err)
+	})
+
+	t.Run("file does not exist", func(t *testing.T) {
+		for _, test := range []struct {
+			rev  string
+			path string
+		}{
+			{rev: "master", path: "404.txt"},
+			{rev: "404", path: "src/main.go"},
+		} {
+			_, err := testrepo.ReadFile(test.rev, test.path)
+			assert.Equal(t, ErrFileNotExist, err)
+		}
+	})
+}
+
+func TestRepository_HashObject(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	id, err := r.HashObject(strings.NewReader("Hello, world!\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "af5626b4a114abcb82d63db7c8082c3c4756e51b", id)
+
+	blob, err := r.CatFileBlob(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := blob.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Hello, world!\n", string(p))
+}
@@ -94,7 +94,12 @@ func (r *Repository) CatFileCommit(rev string, opts ...CatFileCommitOptions) (*C
 		return cache.(*Commit), nil
 	}
 
-	commitID, err := r.RevParse(rev, RevParseOptions{Timeout: opt.Timeout}) //nolint
+	// Environment variables are passed through so that objects in a quarantine
+	// directory can be resolved.
+	commitID, err := r.RevParse(rev, RevParseOptions{
+		Timeout:        opt.Timeout, //nolint
+		CommandOptions: CommandOptions{Envs: opt.Envs},
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -147,6 +152,48 @@ func (r *Repository) CatFileType(rev string, opts ...CatFileTypeOptions) (Object
 	return ObjectType(typ), nil
 }
 
+// CommitExistsOptions contains optional arguments for checking existence of a
+// commit.
+//
+// Docs: https://git-scm.com/docs/git-cat-file#Documentation/git-cat-file.txt--e
+type CommitExistsOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// CommitExists returns true if given object ID (or revision) resolves to a
+// commit, where an annotated tag is peeled to the commit it points to. It
+// returns false with no error if the object does not exist, or ErrNotCommit if
+// the object exists but is not a commit, e.g. a blob.
+func (r *Repository) CommitExists(id string, opts ...CommitExistsOptions) (bool, error) {
+	var opt CommitExistsOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	_, err := NewCommand("cat-file").
+		AddOptions(opt.CommandOptions).
+		AddArgs("-e", id+"^{commit}").
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "expected commit type"):
+			return false, ErrNotCommit
+		case isExitCode(err, 1),
+			strings.Contains(err.Error(), "Not a valid object name"),
+			strings.Contains(err.Error(), "does not exist"):
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
 // BranchCommit returns the latest commit of given branch of the repository. The
 // branch must be given in short name e.g. "master".
 func (r *Repository) BranchCommit(branch string, opts ...CatFileCommitOptions) (*Commit, error) {
@@ -175,6 +222,20 @@ type LogOptions struct {
 	RegexpIgnoreCase bool
 	// The relative path of the repository.
 	Path string
+	// The list of relative paths of the repository, in addition to Path, to
+	// only show commits that touched any of them.
+	Paths []string
+	// Indicates whether to read changed-path Bloom filters from the commit-graph
+	// to speed up filtering commits by Path, regardless of the repository's
+	// configuration. It only takes effect when the commit-graph carries the
+	// filters (see Repository.HasChangedPathFilters).
+	UseBloomFilters bool
+	// Indicates whether to load Commit.Note of each commit.
+	ShowNotes bool
+	// The reference of the notes to load, e.g. "refs/notes/ci", the default notes
+	// reference is used when not supplied. It only takes effect when ShowNotes is
+	// set.
+	NotesRef string
 	// The timeout duration before giving up for each shell command execution. The
 	// default timeout duration will be used when not supplied.
 	Timeout time.Duration
@@ -212,16 +273,35 @@ func RepoLog(repoPath, rev string, opts ...LogOptions) ([]*Commit, error) {
 }
 
 // Log returns a list of commits in the state of given revision of the repository.
-// The returned list is in reverse chronological order.
+// The returned list is in reverse chronological order. The commits are loaded
+// while the output of "git log" is being streamed, thus LogOptions.Timeout
+// applies to the whole call, including loading the commits, rather than only
+// to the "git log" command.
 func (r *Repository) Log(rev string, opts ...LogOptions) ([]*Commit, error) {
 	var opt LogOptions
 	if len(opts) > 0 {
 		opt = opts[0]
 	}
 
-	cmd := NewCommand("log").
-		AddOptions(opt.CommandOptions).
-		AddArgs("--pretty="+LogFormatHashOnly, rev)
+	cmd := NewCommand()
+	if opt.UseBloomFilters {
+		cmd.AddArgs(
+			"-c", "core.commitGraph=true",
+			"-c", "commitGraph.readChangedPaths=true",
+		)
+	}
+	cmd.AddArgs("log").AddOptions(opt.CommandOptions)
+	if opt.ShowNotes {
+		cmd.AddArgs("--pretty=" + logFormatHashWithNote)
+		if opt.NotesRef != "" {
+			cmd.AddArgs("--notes=" + opt.NotesRef)
+		} else {
+			cmd.AddArgs("--notes")
+		}
+	} else {
+		cmd.AddArgs("--pretty=" + LogFormatHashOnly)
+	}
+	cmd.AddArgs(rev)
 	if opt.MaxCount > 0 {
 		cmd.AddArgs("--max-count=" + strconv.Itoa(opt.MaxCount))
 	}
@@ -241,12 +321,55 @@ func (r *Repository) Log(rev string, opts ...LogOptions) ([]*Commit, error) {
 	if opt.Path != "" {
 		cmd.AddArgs(escapePath(opt.Path))
 	}
+	for _, path := range opt.Paths {
+		cmd.AddArgs(escapePath(path))
+	}
 
-	stdout, err := cmd.RunInDirWithTimeout(opt.Timeout, r.path)
-	if err != nil {
-		return nil, err
+	if opt.Timeout != 0 {
+		cmd = cmd.WithTimeout(opt.Timeout)
 	}
-	return r.parsePrettyFormatLogToList(opt.Timeout, stdout)
+	if opt.ShowNotes {
+		stdout, err := cmd.RunInDir(r.path)
+		if err != nil {
+			return nil, err
+		}
+		return r.parseLogWithNotes(opt.Timeout, stdout)
+	}
+
+	stdout := cmd.RunInDirReader(r.path)
+	defer func() { _ = stdout.Close() }()
+	return r.parsePrettyFormatLog(opt.Timeout, stdout)
+}
+
+// logFormatHashWithNote is the format of logs that each commit starts with a
+// record separator, followed by the commit ID and the note separated by a NUL
+// byte.
+const logFormatHashWithNote = `format:%x1e%H%x00%N`
+
+// parseLogWithNotes returns a list of commits with notes parsed from given logs
+// that are formatted in logFormatHashWithNote.
+func (r *Repository) parseLogWithNotes(timeout time.Duration, logs []byte) ([]*Commit, error) {
+	commits := make([]*Commit, 0)
+	for _, record := range bytes.Split(logs, []byte{0x1e}) {
+		record = bytes.TrimSpace(record)
+		if len(record) == 0 {
+			continue
+		}
+
+		fields := bytes.SplitN(record, []byte{0}, 2)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("malformed record: %q", record)
+		}
+
+		c, err := r.CatFileCommit(string(fields[0]), CatFileCommitOptions{Timeout: timeout}) //nolint
+		if err != nil {
+			return nil, err
+		}
+		c = c.clone()
+		c.Note = string(bytes.TrimRight(fields[1], "\n"))
+		commits = append(commits, c)
+	}
+	return commits, nil
 }
 
 // CommitByRevisionOptions contains optional arguments for getting a commit.
@@ -255,6 +378,8 @@ func (r *Repository) Log(rev string, opts ...LogOptions) ([]*Commit, error) {
 type CommitByRevisionOptions struct {
 	// The relative path of the repository.
 	Path string
+	// Indicates whether to load Commit.Stats of the commit in the same command.
+	LoadStats bool
 	// The timeout duration before giving up for each shell command execution. The
 	// default timeout duration will be used when not supplied.
 	Timeout time.Duration
@@ -269,6 +394,10 @@ func (r *Repository) CommitByRevision(rev string, opts ...CommitByRevisionOption
 		opt = opts[0]
 	}
 
+	if opt.LoadStats {
+		return r.commitByRevisionWithStats(rev, opt)
+	}
+
 	commits, err := r.Log(rev, LogOptions{
 		MaxCount:       1,
 		Path:           opt.Path,
@@ -286,6 +415,81 @@ func (r *Repository) CommitByRevision(rev string, opts ...CommitByRevisionOption
 	return commits[0], nil
 }
 
+// parseShortStat parses the output of "--shortstat", e.g. " 3 files changed, 10
+// insertions(+), 2 deletions(-)". Parts with zero count are omitted by Git.
+func parseShortStat(line string) (*CommitStats, error) {
+	stats := new(CommitStats)
+	for _, part := range strings.Split(strings.TrimSpace(line), ",") {
+		fields := strings.Fields(part)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("malformed shortstat %q", line)
+		}
+
+		n, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("parse count %q: %v", part, err)
+		}
+
+		switch {
+		case strings.HasPrefix(fields[1], "file"):
+			stats.FilesChanged = n
+		case strings.HasPrefix(fields[1], "insertion"):
+			stats.Insertions = n
+		case strings.HasPrefix(fields[1], "deletion"):
+			stats.Deletions = n
+		default:
+			return nil, fmt.Errorf("unexpected shortstat %q", part)
+		}
+	}
+	return stats, nil
+}
+
+// commitByRevisionWithStats returns a commit by given revision with its stats
+// loaded, using the commit ID and stats printed by the same command.
+func (r *Repository) commitByRevisionWithStats(rev string, opt CommitByRevisionOptions) (*Commit, error) {
+	// Stats should cover all the changes of the commit even if it is matched by
+	// the path.
+	cmd := NewCommand("log").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--max-count=1", "--pretty="+LogFormatHashOnly, "--shortstat", "--full-diff", rev, "--")
+	if opt.Path != "" {
+		cmd.AddArgs(escapePath(opt.Path))
+	}
+
+	stdout, err := cmd.RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		if strings.Contains(err.Error(), "bad revision") {
+			return nil, ErrRevisionNotExist
+		}
+		return nil, err
+	}
+
+	lines := bytesToStrings(bytes.TrimSpace(stdout))
+	if len(lines) == 0 {
+		return nil, ErrRevisionNotExist
+	}
+
+	// There is no stats line for a commit without changes, e.g. a merge commit.
+	stats := new(CommitStats)
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		stats, err = parseShortStat(line)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c, err := r.CatFileCommit(lines[0], CatFileCommitOptions{Timeout: opt.Timeout}) //nolint
+	if err != nil {
+		return nil, err
+	}
+	c = c.clone()
+	c.Stats = stats
+	return c, nil
+}
+
 // CommitsByPageOptions contains optional arguments for getting paginated
 // commits.
 //
@@ -380,6 +584,292 @@ func (r *Repository) CommitsSince(rev string, since time.Time, opts ...CommitsSi
 	})
 }
 
+// CommitsTouchingOptions contains optional arguments for listing commits that
+// touched files matching a glob pattern.
+//
+// Docs: https://git-scm.com/docs/gitglossary#Documentation/gitglossary.txt-aiddefpathspecapathspec
+type CommitsTouchingOptions struct {
+	// The maximum number of commits to output.
+	MaxCount int
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// globPathspec returns the pathspec that matches files against given glob
+// pattern relative to the repository root, e.g. "**/*.go". Everything after
+// the magic signature is taken as the pattern, so a leading ":" in the pattern
+// needs no further escaping.
+func globPathspec(pattern string) string {
+	return ":(glob)" + pattern
+}
+
+// CommitsTouching returns a list of commits in the state of given revision that
+// touched any file matching the glob pattern, e.g. "**/*.go" for all Go files.
+// The returned list is in reverse chronological order.
+func (r *Repository) CommitsTouching(rev, pattern string, opts ...CommitsTouchingOptions) ([]*Commit, error) {
+	var opt CommitsTouchingOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	if pattern == "" {
+		return nil, errors.New("empty pattern")
+	}
+
+	cmd := NewCommand("log").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--pretty="+LogFormatHashOnly, rev)
+	if opt.MaxCount > 0 {
+		cmd.AddArgs("--max-count=" + strconv.Itoa(opt.MaxCount))
+	}
+	cmd.AddArgs("--", globPathspec(pattern))
+
+	stdout, err := cmd.RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		return nil, err
+	}
+	return r.parsePrettyFormatLogToList(opt.Timeout, stdout)
+}
+
+// FileHistoryEntry is a commit that touched a file, with the status of the file
+// in the commit.
+type FileHistoryEntry struct {
+	// The commit that touched the file.
+	Commit *Commit
+	// The status of the file in the commit.
+	Status DiffFileType
+	// The path of the file in the commit.
+	Path string
+	// The path of the file before the commit, it is only set when Status is
+	// DiffFileRename.
+	OldPath string
+}
+
+// FileHistoryOptions contains optional arguments for listing the history of a
+// file.
+//
+// Docs: https://git-scm.com/docs/git-log#Documentation/git-log.txt---follow
+type FileHistoryOptions struct {
+	// The maximum number of commits to output.
+	MaxCount int
+	// The number commits skipped before starting to show the commit output.
+	Skip int
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// parseFileHistory parses the output of "git log --follow --name-status -z
+// --pretty=tformat:%x00%H". Each record starts with an empty field (the NUL of
+// the format) followed by the commit ID, the status and the path(s), e.g.
+// "\x00<commit>\x00\nR100\x00<old path>\x00<new path>\x00". The returned
+// entries do not have the commit loaded, whose IDs are returned separately in
+// the same order.
+func parseFileHistory(data []byte) ([]*FileHistoryEntry, []string, error) {
+	fields := bytes.Split(data, []byte{0})
+
+	var entries []*FileHistoryEntry
+	var ids []string
+	for i := 0; i < len(fields); i++ {
+		if len(fields[i]) == 0 {
+			continue
+		}
+
+		id := string(fields[i])
+		if i+1 >= len(fields) || len(bytes.TrimSpace(fields[i+1])) == 0 {
+			return nil, nil, fmt.Errorf("no status for commit %q", id)
+		}
+		i++
+
+		status := bytes.TrimSpace(fields[i])
+		entry := new(FileHistoryEntry)
+		numPaths := 1
+		switch status[0] {
+		case 'A':
+			entry.Status = DiffFileAdd
+		case 'M', 'T':
+			entry.Status = DiffFileChange
+		case 'D':
+			entry.Status = DiffFileDelete
+		case 'R':
+			entry.Status = DiffFileRename
+			numPaths = 2
+		case 'C':
+			// A copy is a new file as far as the history of the file goes.
+			entry.Status = DiffFileAdd
+			numPaths = 2
+		default:
+			return nil, nil, fmt.Errorf("unexpected status %q for commit %q", status, id)
+		}
+
+		if i+numPaths >= len(fields) {
+			return nil, nil, fmt.Errorf("missing path for commit %q", id)
+		}
+		if numPaths == 2 {
+			if entry.Status == DiffFileRename {
+				entry.OldPath = string(fields[i+1])
+			}
+			entry.Path = string(fields[i+2])
+		} else {
+			entry.Path = string(fields[i+1])
+		}
+		i += numPaths
+
+		entries = append(entries, entry)
+		ids = append(ids, id)
+	}
+	return entries, ids, nil
+}
+
+// FileHistory returns the list of commits in the state of given revision that
+// touched the file, with the status of the file in each commit. It follows the
+// file across renames. The returned list is in reverse chronological order.
+func (r *Repository) FileHistory(rev, path string, opts ...FileHistoryOptions) ([]*FileHistoryEntry, error) {
+	var opt FileHistoryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	cmd := NewCommand("log").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--follow", "--name-status", "-z", "--pretty=tformat:%x00%H", rev)
+	if opt.MaxCount > 0 {
+		cmd.AddArgs("--max-count=" + strconv.Itoa(opt.MaxCount))
+	}
+	if opt.Skip > 0 {
+		cmd.AddArgs("--skip=" + strconv.Itoa(opt.Skip))
+	}
+	cmd.AddArgs("--", escapePath(path))
+
+	stdout, err := cmd.RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		if strings.Contains(err.Error(), "bad revision") {
+			return nil, ErrRevisionNotExist
+		}
+		return nil, err
+	}
+
+	entries, ids, err := parseFileHistory(stdout)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		return []*FileHistoryEntry{}, nil
+	}
+
+	for i := range entries {
+		entries[i].Commit, err = r.CatFileCommit(ids[i], CatFileCommitOptions{Timeout: opt.Timeout}) //nolint
+		if err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// GraphLine is a single line of the commit graph as drawn by Git.
+type GraphLine struct {
+	// The graph characters drawn in front of the commit, e.g. "* |" or "|\",
+	// without trailing spaces.
+	Graph string
+	// The ID of the commit on this line. It is nil for lines that only continue
+	// the graph without a commit.
+	ID *SHA1
+	// The subject of the commit on this line.
+	Subject string
+}
+
+// IsCommit returns true if the line has a commit node, false when the line only
+// continues the graph.
+func (l *GraphLine) IsCommit() bool {
+	return l.ID != nil
+}
+
+// GraphLogOptions contains optional arguments for listing the commit graph.
+//
+// Docs: https://git-scm.com/docs/git-log#Documentation/git-log.txt---graph
+type GraphLogOptions struct {
+	// The maximum number of commits to output.
+	MaxCount int
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// graphLogFormat is the equivalent of "--oneline" with a NUL byte marking where
+// the graph ends, and the full commit ID.
+const graphLogFormat = "format:%x00%H %s"
+
+// parseGraphLog parses the output of "git log --graph" that is formatted in
+// graphLogFormat.
+func parseGraphLog(data []byte) ([]*GraphLine, error) {
+	lines := make([]*GraphLine, 0, bytes.Count(data, []byte{'\n'})+1)
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		i := strings.IndexByte(line, 0)
+		if i < 0 {
+			lines = append(lines, &GraphLine{
+				Graph: strings.TrimRight(line, " "),
+			})
+			continue
+		}
+
+		fields := strings.SplitN(line[i+1:], " ", 2)
+		id, err := NewIDFromString(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("parse commit ID %q: %v", fields[0], err)
+		}
+
+		gl := &GraphLine{
+			Graph: strings.TrimRight(line[:i], " "),
+			ID:    id,
+		}
+		if len(fields) > 1 {
+			gl.Subject = fields[1]
+		}
+		lines = append(lines, gl)
+	}
+	return lines, nil
+}
+
+// GraphLog returns the commit graph in the state of given revision as drawn by
+// "git log --graph --oneline", one element per output line. Lines that only
+// continue the graph have no commit.
+func (r *Repository) GraphLog(rev string, opts ...GraphLogOptions) ([]*GraphLine, error) {
+	var opt GraphLogOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	cmd := NewCommand("log").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--graph", "--color=never", "--pretty="+graphLogFormat)
+	if opt.MaxCount > 0 {
+		cmd.AddArgs("--max-count=" + strconv.Itoa(opt.MaxCount))
+	}
+	cmd.AddArgs(rev, "--")
+
+	stdout, err := cmd.RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		return nil, err
+	}
+	if len(stdout) == 0 {
+		return []*GraphLine{}, nil
+	}
+	return parseGraphLog(stdout)
+}
+
 // DiffNameOnlyOptions contains optional arguments for listing changed files.
 //
 // Docs: https://git-scm.com/docs/git-diff#Documentation/git-diff.txt---name-only
@@ -486,6 +976,160 @@ func (r *Repository) RevListCount(refspecs []string, opts ...RevListCountOptions
 	return strconv.ParseInt(strings.TrimSpace(string(stdout)), 10, 64)
 }
 
+// CommitCountBetweenOptions contains optional arguments for counting commits
+// between two revisions.
+//
+// Docs: https://git-scm.com/docs/git-rev-list#Documentation/git-rev-list.txt---count
+type CommitCountBetweenOptions struct {
+	// Indicates whether to use the three-dot form, i.e. to count commits that are
+	// reachable from either revision but not both, which are the commits after
+	// their merge base on both sides.
+	NeedsMergeBase bool
+	// The relative path of the repository.
+	Path string
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// CommitCountBetween returns number of commits that are reachable from head but
+// not from base, i.e. "base..head".
+func (r *Repository) CommitCountBetween(base, head string, opts ...CommitCountBetweenOptions) (int64, error) {
+	var opt CommitCountBetweenOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	refspec := base + ".." + head
+	if opt.NeedsMergeBase {
+		refspec = base + "..." + head
+	}
+	return r.RevListCount([]string{refspec}, RevListCountOptions{
+		Path:           opt.Path,
+		Timeout:        opt.Timeout,
+		CommandOptions: opt.CommandOptions,
+	})
+}
+
+// CommitCountByAuthorOptions contains optional arguments for counting commits
+// by author.
+//
+// Docs: https://git-scm.com/docs/git-log#Documentation/git-log.txt---use-mailmap
+type CommitCountByAuthorOptions struct {
+	// Indicates whether to map authors to their canonical email addresses using
+	// the mailmap, so that commits of the same person under multiple email
+	// addresses are counted together.
+	UseMailmap bool
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// CommitCountByAuthor returns the number of commits of each author email in the
+// revision range, e.g. "v1.0.0..master". It returns an empty map when there is
+// no commit in the range.
+func (r *Repository) CommitCountByAuthor(revRange string, opts ...CommitCountByAuthorOptions) (map[string]int, error) {
+	var opt CommitCountByAuthorOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	// The "%aE" respects the mailmap.
+	format := "--pretty=format:%ae"
+	if opt.UseMailmap {
+		format = "--pretty=format:%aE"
+	}
+	stdout, err := NewCommand("log").
+		AddOptions(opt.CommandOptions).
+		AddArgs(format, revRange, "--").
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		if strings.Contains(err.Error(), "bad revision") {
+			return nil, ErrRevisionNotExist
+		}
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, email := range bytesToStrings(stdout) {
+		counts[email]++
+	}
+	return counts, nil
+}
+
+// CommitNavigationOptions contains optional arguments for finding the adjacent
+// commits.
+//
+// Docs: https://git-scm.com/docs/git-rev-list#Documentation/git-rev-list.txt---first-parent
+type CommitNavigationOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// CommitNavigation returns the commits immediately before and after the
+// revision along the first-parent history of the branch. The previous commit
+// is nil for a root commit, and the next commit is nil when the revision is the
+// tip of the branch or not on its first-parent history.
+func (r *Repository) CommitNavigation(rev, branch string, opts ...CommitNavigationOptions) (prev, next *Commit, _ error) {
+	var opt CommitNavigationOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	commit, err := r.CatFileCommit(rev, CatFileCommitOptions{Timeout: opt.Timeout}) //nolint
+	if err != nil {
+		return nil, nil, err
+	}
+	if commit.ParentsCount() > 0 {
+		prev, err = commit.Parent(0, CatFileCommitOptions{Timeout: opt.Timeout}) //nolint
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// The oldest commit on the first-parent history of the branch that is not
+	// reachable from the revision is the next one if its first parent is the
+	// revision.
+	stdout, err := NewCommand("rev-list", "--first-parent", "--parents").
+		AddOptions(opt.CommandOptions).
+		AddArgs(branch, "^"+commit.ID.String(), "--").
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		if strings.Contains(err.Error(), "bad revision") {
+			return nil, nil, ErrRevisionNotExist
+		}
+		return nil, nil, err
+	}
+
+	lines := bytesToStrings(stdout)
+	if len(lines) == 0 {
+		return prev, nil, nil
+	}
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 2 || fields[1] != commit.ID.String() {
+		return prev, nil, nil
+	}
+
+	next, err = r.CatFileCommit(fields[0], CatFileCommitOptions{Timeout: opt.Timeout}) //nolint
+	if err != nil {
+		return nil, nil, err
+	}
+	return prev, next, nil
+}
+
 // RevListOptions contains optional arguments for listing commits.
 //
 // Docs: https://git-scm.com/docs/git-rev-list
@@ -530,6 +1174,9 @@ func (r *Repository) RevList(refspecs []string, opts ...RevListOptions) ([]*Comm
 type LatestCommitTimeOptions struct {
 	// To get the latest commit time of the branch. When not set, it checks all branches.
 	Branch string
+	// To get the latest time of a commit that changed the path, e.g. a file or a
+	// directory relative to the root of the repository.
+	Path string
 	// The timeout duration before giving up for each shell command execution. The
 	// default timeout duration will be used when not supplied.
 	Timeout time.Duration
@@ -537,13 +1184,19 @@ type LatestCommitTimeOptions struct {
 	CommandOptions
 }
 
-// LatestCommitTime returns the time of latest commit of the repository.
+// LatestCommitTime returns the time of latest commit of the repository. It
+// returns ErrRevisionNotExist when LatestCommitTimeOptions.Path is set but has
+// no history.
 func (r *Repository) LatestCommitTime(opts ...LatestCommitTimeOptions) (time.Time, error) {
 	var opt LatestCommitTimeOptions
 	if len(opts) > 0 {
 		opt = opts[0]
 	}
 
+	if opt.Path != "" {
+		return r.latestPathCommitTime(opt)
+	}
+
 	cmd := NewCommand("for-each-ref").
 		AddOptions(opt.CommandOptions).
 		AddArgs(
@@ -561,3 +1214,33 @@ func (r *Repository) LatestCommitTime(opts ...LatestCommitTimeOptions) (time.Tim
 	}
 	return time.Parse("2006-01-02 15:04:05 -0700", strings.TrimSpace(string(stdout)))
 }
+
+// latestPathCommitTime returns the time of latest commit that changed the path
+// of given options.
+func (r *Repository) latestPathCommitTime(opt LatestCommitTimeOptions) (time.Time, error) {
+	cmd := NewCommand("log").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--max-count=1", "--format=%ct")
+	if opt.Branch != "" {
+		cmd.AddArgs(RefsHeads + opt.Branch)
+	} else {
+		cmd.AddArgs("--branches")
+	}
+	cmd.AddArgs("--", escapePath(opt.Path))
+
+	stdout, err := cmd.RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	stdout = bytes.TrimSpace(stdout)
+	if len(stdout) == 0 {
+		return time.Time{}, ErrRevisionNotExist
+	}
+
+	sec, err := strconv.ParseInt(string(stdout), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse commit time %q: %v", stdout, err)
+	}
+	return time.Unix(sec, 0), nil
+}
@@ -0,0 +1,246 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CommitFile is a change of a file to be committed.
+type CommitFile struct {
+	// The path relative to the root of the tree.
+	Path string
+	// The content of the file.
+	Content []byte
+	// The mode of the file, e.g. "100644" or "100755". It defaults to "100644"
+	// when empty.
+	Mode string
+	// Indicates whether to delete the file instead, Content and Mode are ignored
+	// when set.
+	Delete bool
+}
+
+// CommitFilesOptions contains arguments for committing files without a working
+// tree.
+//
+// Docs: https://git-scm.com/docs/git-commit-tree
+type CommitFilesOptions struct {
+	// The revision of the commit to base on, an orphan commit is created when
+	// empty.
+	Base string
+	// The changes of files to be applied on top of the base commit.
+	Files []CommitFile
+	// The author of the commit, it defaults to the committer when not supplied.
+	Author *Signature
+	// The committer of the commit.
+	Committer *Signature
+	// The commit message.
+	Message string
+	// The branch to be updated to the new commit. The branch must currently
+	// point to the base commit, or must not exist when the base is empty or
+	// NewBranch is set. It is checked atomically so that a concurrent update to
+	// the branch is never lost. No branch is updated when empty.
+	Branch string
+	// Indicates whether the branch is a new branch to be created.
+	NewBranch bool
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// signatureEnvs returns the environment variables of the signature for the role,
+// i.e. "AUTHOR" or "COMMITTER".
+func signatureEnvs(role string, sig *Signature) []string {
+	envs := []string{
+		"GIT_" + role + "_NAME=" + sig.Name,
+		"GIT_" + role + "_EMAIL=" + sig.Email,
+	}
+	if !sig.When.IsZero() {
+		envs = append(envs, fmt.Sprintf("GIT_%s_DATE=%d %s", role, sig.When.Unix(), sig.When.Format("-0700")))
+	}
+	return envs
+}
+
+// CommitTreeOptions contains arguments for creating a commit from a tree.
+//
+// Docs: https://git-scm.com/docs/git-commit-tree
+type CommitTreeOptions struct {
+	// The revisions of the parent commits, a root commit is created when empty.
+	Parents []string
+	// The author of the commit, it defaults to the committer when not supplied.
+	Author *Signature
+	// The committer of the commit. The identity from the config of the
+	// repository is used when not supplied.
+	Committer *Signature
+	// The commit message.
+	Message string
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// CommitTree creates a commit of the tree in the object database without
+// updating any reference, and returns the ID of the new commit.
+func (r *Repository) CommitTree(tree string, opt CommitTreeOptions) (string, error) {
+	if opt.Author == nil {
+		opt.Author = opt.Committer
+	}
+
+	cmd := NewCommand("commit-tree").AddOptions(opt.CommandOptions)
+	if opt.Author != nil {
+		cmd.AddEnvs(signatureEnvs("AUTHOR", opt.Author)...)
+	}
+	if opt.Committer != nil {
+		cmd.AddEnvs(signatureEnvs("COMMITTER", opt.Committer)...)
+	}
+	for _, parent := range opt.Parents {
+		cmd.AddArgs("-p", parent)
+	}
+	stdout, err := cmd.AddArgs("-m", opt.Message, tree).RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(stdout)), nil
+}
+
+// CommitFiles creates a commit with the changes of files on top of the base
+// commit entirely in the object database, and returns the ID of the new commit.
+// It uses a temporary index so that it works for bare repositories and never
+// touches the working tree or the index of the repository, which makes it safe
+// to be called concurrently. It returns ErrRefUpdateRejected if the branch has
+// been updated concurrently.
+func (r *Repository) CommitFiles(opt CommitFilesOptions) (string, error) {
+	if opt.Committer == nil {
+		return "", errors.New("committer is required")
+	}
+	if opt.Author == nil {
+		opt.Author = opt.Committer
+	}
+
+	dir, err := ioutil.TempDir("", "git-module-index-")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	// All commands share the temporary index rather than the one of the
+	// repository.
+	cmdOpts := opt.CommandOptions
+	cmdOpts.Envs = append(append([]string{}, cmdOpts.Envs...), "GIT_INDEX_FILE="+filepath.Join(dir, "index"))
+	run := func(args ...string) (string, error) {
+		stdout, err := NewCommand(args[0]).
+			AddOptions(cmdOpts).
+			AddArgs(args[1:]...).
+			RunInDirWithTimeout(opt.Timeout, r.path)
+		return strings.TrimSpace(string(stdout)), err
+	}
+
+	var base string
+	if opt.Base != "" {
+		base, err = run("rev-parse", "--verify", opt.Base+"^{commit}")
+		if err != nil {
+			return "", ErrRevisionNotExist
+		}
+
+		_, err = run("read-tree", base)
+		if err != nil {
+			return "", fmt.Errorf("read tree: %w", err)
+		}
+	}
+
+	// A removal is staged through "--index-info" with mode 0, which unlike
+	// "--force-remove" does not require a working tree.
+	removals := new(bytes.Buffer)
+	for _, f := range opt.Files {
+		if f.Delete {
+			_, _ = fmt.Fprintf(removals, "0 %s\t%s\n", EmptyID, f.Path)
+			continue
+		}
+
+		err = r.StageContent(f.Path, f.Content, f.Mode, StageContentOptions{
+			Timeout:        opt.Timeout, //nolint
+			CommandOptions: cmdOpts,
+		})
+		if err != nil {
+			return "", fmt.Errorf("stage %q: %w", f.Path, err)
+		}
+	}
+	if removals.Len() > 0 {
+		stderr := newTailBuffer(stderrLimit)
+		cmd := NewCommand("update-index").
+			AddOptions(cmdOpts).
+			AddArgs("--index-info")
+		if opt.Timeout != 0 {
+			cmd = cmd.WithTimeout(opt.Timeout)
+		}
+		err = cmd.RunInDirWithOptions(r.path, RunInDirOptions{
+			Stdin:  removals,
+			Stdout: new(bytes.Buffer),
+			Stderr: stderr,
+		})
+		if err != nil {
+			return "", fmt.Errorf("remove files: %w", concatenateError(err, stderr.String()))
+		}
+	}
+
+	tree, err := run("write-tree")
+	if err != nil {
+		return "", fmt.Errorf("write tree: %w", err)
+	}
+
+	var parents []string
+	if base != "" {
+		parents = []string{base}
+	}
+	commitID, err := r.CommitTree(tree, CommitTreeOptions{
+		Parents:        parents,
+		Author:         opt.Author,
+		Committer:      opt.Committer,
+		Message:        opt.Message,
+		Timeout:        opt.Timeout, //nolint
+		CommandOptions: cmdOpts,
+	})
+	if err != nil {
+		return "", fmt.Errorf("commit tree: %w", err)
+	}
+
+	if opt.Branch == "" {
+		return commitID, nil
+	}
+
+	defer r.ClearReachabilityCache()
+
+	oldID := base
+	if oldID == "" || opt.NewBranch {
+		oldID = EmptyID
+	}
+	err = r.UpdateRef(RefsHeads+opt.Branch, commitID, UpdateRefOptions{
+		OldValue:       oldID,
+		Message:        "commit: " + strings.SplitN(opt.Message, "\n", 2)[0],
+		Timeout:        opt.Timeout, //nolint
+		CommandOptions: cmdOpts,
+	})
+	if err != nil {
+		return "", err
+	}
+	return commitID, nil
+}
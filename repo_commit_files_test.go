@@ -0,0 +1,226 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_CommitFiles(t *testing.T) {
+	path := tempPath()
+	defer func() {
+		_ = os.RemoveAll(path)
+	}()
+
+	err := Init(path, InitOptions{Bare: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	author := &Signature{
+		Name:  "alice",
+		Email: "alice@example.com",
+		When:  time.Unix(1600000000, 0).In(time.FixedZone("", 8*60*60)),
+	}
+	committer := &Signature{
+		Name:  "bob",
+		Email: "bob@example.com",
+	}
+
+	first, err := r.CommitFiles(CommitFilesOptions{
+		Files: []CommitFile{
+			{Path: "README.txt", Content: []byte("Hello\n")},
+			{Path: "dir/run.sh", Content: []byte("#!/bin/sh\n"), Mode: "100755"},
+		},
+		Author:    author,
+		Committer: committer,
+		Message:   "Initial commit",
+		Branch:    "master",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := r.CatFileCommit("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, first, c.ID.String())
+	assert.Equal(t, 0, c.ParentsCount())
+	assert.Equal(t, "Initial commit\n", c.Message)
+	assert.Equal(t, "alice", c.Author.Name)
+	assert.Equal(t, author.When.Unix(), c.Author.When.Unix())
+	assert.Equal(t, "bob", c.Committer.Name)
+
+	p, err := c.Blob("README.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := p.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Hello\n", string(content))
+
+	e, err := c.TreeEntry("dir/run.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, EntryExec, e.Mode())
+
+	second, err := r.CommitFiles(CommitFilesOptions{
+		Base: "master",
+		Files: []CommitFile{
+			{Path: "README.txt", Content: []byte("Hello, world\n")},
+			{Path: "dir/run.sh", Delete: true},
+		},
+		Committer: committer,
+		Message:   "Update README.txt",
+		Branch:    "master",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err = r.CatFileCommit("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, second, c.ID.String())
+	assert.Equal(t, "bob", c.Author.Name)
+
+	parentID, err := c.ParentID(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, first, parentID.String())
+
+	_, err = c.TreeEntry("dir/run.sh")
+	assert.Equal(t, ErrRevisionNotExist, err)
+
+	// The repository index is never touched.
+	entries, err := r.IndexEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, entries)
+
+	t.Run("new branch", func(t *testing.T) {
+		id, err := r.CommitFiles(CommitFilesOptions{
+			Base:      first,
+			Files:     []CommitFile{{Path: "feature.txt", Content: []byte("feature")}},
+			Committer: committer,
+			Message:   "Add feature.txt",
+			Branch:    "feature",
+			NewBranch: true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		c, err := r.CatFileCommit("feature")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, id, c.ID.String())
+	})
+
+	t.Run("stale base", func(t *testing.T) {
+		_, err := r.CommitFiles(CommitFilesOptions{
+			Base:      first,
+			Files:     []CommitFile{{Path: "stale.txt", Content: []byte("stale")}},
+			Committer: committer,
+			Message:   "Add stale.txt",
+			Branch:    "master",
+		})
+		assert.Equal(t, ErrRefUpdateRejected, err)
+
+		// The branch must not be moved.
+		c, err := r.CatFileCommit("master")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, second, c.ID.String())
+	})
+
+	t.Run("base does not exist", func(t *testing.T) {
+		_, err := r.CommitFiles(CommitFilesOptions{
+			Base:      "404",
+			Committer: committer,
+		})
+		assert.Equal(t, ErrRevisionNotExist, err)
+	})
+
+	t.Run("no committer", func(t *testing.T) {
+		_, err := r.CommitFiles(CommitFilesOptions{})
+		assert.Error(t, err)
+	})
+}
+
+func TestRepository_CommitTree(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	blobID, err := r.HashObject(strings.NewReader("Hello\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	treeID, err := r.MkTree([]MkTreeEntry{{Mode: EntryBlob, ID: blobID, Name: "README.txt"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parentID, err := r.RevParse("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	committer := &Signature{
+		Name:  "alice",
+		Email: "alice@example.com",
+	}
+	commitID, err := r.CommitTree(treeID, CommitTreeOptions{
+		Parents:   []string{parentID},
+		Committer: committer,
+		Message:   "Replace all files",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := r.CatFileCommit(commitID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, treeID, c.Tree.id.String())
+	assert.Equal(t, "Replace all files\n", c.Message)
+	assert.Equal(t, committer.Email, c.Author.Email)
+	assert.Equal(t, committer.Email, c.Committer.Email)
+	if assert.Equal(t, 1, c.ParentsCount()) {
+		id, err := c.ParentID(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, parentID, id.String())
+	}
+
+	// No reference is updated.
+	masterID, err := r.RevParse("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, parentID, masterID)
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// HasChangedPathFiltersOptions contains optional arguments for checking
+// changed-path Bloom filters.
+//
+// Docs: https://git-scm.com/docs/commit-graph-format
+type HasChangedPathFiltersOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// commitGraphHasChangedPathFilters returns true if the commit-graph file has
+// both chunks of changed-path Bloom filters, i.e. "BIDX" and "BDAT".
+func commitGraphHasChangedPathFilters(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = f.Close() }()
+
+	// The header is the signature "CGPH", version, hash version, number of
+	// chunks and number of base commit-graphs, each has one byte except the
+	// signature. It is followed by the table of contents that has (number of
+	// chunks + 1) entries, each has a 4-byte chunk ID and an 8-byte offset.
+	r := bufio.NewReader(f)
+	header := make([]byte, 8)
+	if _, err = io.ReadFull(r, header); err != nil {
+		return false, fmt.Errorf("read header: %v", err)
+	} else if !bytes.Equal(header[:4], []byte("CGPH")) {
+		return false, fmt.Errorf("bad signature %q", header[:4])
+	}
+
+	var hasIndex, hasData bool
+	entry := make([]byte, 12)
+	for i := 0; i < int(header[6]); i++ {
+		if _, err = io.ReadFull(r, entry); err != nil {
+			return false, fmt.Errorf("read table of contents: %v", err)
+		}
+
+		switch string(entry[:4]) {
+		case "BIDX":
+			hasIndex = true
+		case "BDAT":
+			hasData = true
+		}
+	}
+	return hasIndex && hasData, nil
+}
+
+// HasChangedPathFilters returns true if the commit-graph of the repository
+// carries changed-path Bloom filters, which Git uses to speed up listing
+// commits that changed a path. For a commit-graph chain, every commit-graph in
+// the chain must carry the filters. It returns false when there is no
+// commit-graph.
+func (r *Repository) HasChangedPathFilters(opts ...HasChangedPathFiltersOptions) (bool, error) {
+	var opt HasChangedPathFiltersOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	stdout, err := NewCommand("rev-parse").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--git-path", "objects/info").
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		return false, err
+	}
+
+	dir := strings.TrimSpace(string(stdout))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(r.path, dir)
+	}
+
+	// Git prefers a single commit-graph file over a commit-graph chain.
+	var graphs []string
+	if isFile(filepath.Join(dir, "commit-graph")) {
+		graphs = append(graphs, filepath.Join(dir, "commit-graph"))
+	} else {
+		chain, err := ioutil.ReadFile(filepath.Join(dir, "commit-graphs", "commit-graph-chain"))
+		if err != nil && !os.IsNotExist(err) {
+			return false, err
+		}
+		for _, hash := range bytesToStrings(chain) {
+			graphs = append(graphs, filepath.Join(dir, "commit-graphs", "graph-"+hash+".graph"))
+		}
+	}
+	if len(graphs) == 0 {
+		return false, nil
+	}
+
+	for _, graph := range graphs {
+		has, err := commitGraphHasChangedPathFilters(graph)
+		if err != nil {
+			return false, fmt.Errorf("check %q: %v", graph, err)
+		} else if !has {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,168 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	goversion "github.com/mcuadros/go-version"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_HasChangedPathFilters(t *testing.T) {
+	version, err := BinVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if goversion.Compare(version, "2.27", "<") {
+		t.Skip("Git version does not support changed-path Bloom filters")
+	}
+
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	infoDir := filepath.Join(r.Path(), ".git", "objects", "info")
+	writeCommitGraph := func(args ...string) {
+		err := os.RemoveAll(filepath.Join(infoDir, "commit-graph"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.RemoveAll(filepath.Join(infoDir, "commit-graphs"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = NewCommand("commit-graph", "write", "--reachable").AddArgs(args...).RunInDir(r.Path())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		args   []string
+		expHas bool
+	}{
+		{
+			name:   "without filters",
+			args:   []string{"--no-changed-paths"},
+			expHas: false,
+		},
+		{
+			name:   "with filters",
+			args:   []string{"--changed-paths"},
+			expHas: true,
+		},
+		{
+			name:   "chain with filters",
+			args:   []string{"--split", "--changed-paths"},
+			expHas: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			writeCommitGraph(test.args...)
+
+			has, err := r.HasChangedPathFilters()
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, test.expHas, has)
+		})
+	}
+
+	t.Run("no commit-graph", func(t *testing.T) {
+		err := os.RemoveAll(filepath.Join(infoDir, "commit-graph"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.RemoveAll(filepath.Join(infoDir, "commit-graphs"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		has, err := r.HasChangedPathFilters()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.False(t, has)
+	})
+
+	t.Run("bad commit-graph", func(t *testing.T) {
+		err := ioutil.WriteFile(filepath.Join(infoDir, "commit-graph"), []byte("bad"), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			_ = os.Remove(filepath.Join(infoDir, "commit-graph"))
+		}()
+
+		_, err = r.HasChangedPathFilters()
+		assert.Error(t, err)
+	})
+}
+
+func TestRepository_Log_useBloomFilters(t *testing.T) {
+	version, err := BinVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Configuration via environment variables requires Git 2.31
+	if goversion.Compare(version, "2.31", "<") {
+		t.Skip("Git version does not support configuration via environment variables")
+	}
+
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	_, err = NewCommand("commit-graph", "write", "--reachable", "--changed-paths").RunInDir(r.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The trace has statistics of Bloom filters only when they are used.
+	trace := filepath.Join(r.Path(), ".git", "trace")
+	log := func(opt LogOptions) []string {
+		_ = os.Remove(trace)
+		opt.Path = "README.txt"
+		opt.CommandOptions = CommandOptions{
+			Envs: []string{
+				"GIT_CONFIG_COUNT=1",
+				"GIT_CONFIG_KEY_0=commitGraph.readChangedPaths",
+				"GIT_CONFIG_VALUE_0=false",
+				"GIT_TRACE2_PERF=" + trace,
+			},
+		}
+		commits, err := r.Log("HEAD", opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return commitsToIDs(commits)
+	}
+	usedBloomFilters := func() bool {
+		p, err := ioutil.ReadFile(trace)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return bytes.Contains(p, []byte("bloom"))
+	}
+
+	expIDs := log(LogOptions{})
+	assert.NotEmpty(t, expIDs)
+	assert.False(t, usedBloomFilters())
+
+	assert.Equal(t, expIDs, log(LogOptions{UseBloomFilters: true}))
+	assert.True(t, usedBloomFilters())
+}
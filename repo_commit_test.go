@@ -6,6 +6,12 @@ package git
 
 import (
 	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -53,6 +59,90 @@ func TestRepository_CatFileCommit(t *testing.T) {
 	assert.Equal(t, "Add a symlink\n", c.Message)
 }
 
+func TestRepository_CatFileCommit_concurrent(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	_, err = NewCommand("notes", "add", "-m", "note", "master").RunInDir(r.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Commits are shared through the cache, run with the race detector to catch
+	// unsynchronized access.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			c, err := r.CatFileCommit("master")
+			if !assert.Nil(t, err) {
+				return
+			}
+			_, err = c.Parent(0)
+			assert.Nil(t, err)
+
+			c, err = r.CommitByRevision("master", CommitByRevisionOptions{LoadStats: true})
+			if assert.Nil(t, err) {
+				assert.NotNil(t, c.Stats)
+			}
+
+			commits, err := r.Log("master", LogOptions{MaxCount: 1, ShowNotes: true})
+			if assert.Nil(t, err) && assert.Len(t, commits, 1) {
+				assert.Equal(t, "note", commits[0].Note)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Fields loaded on demand do not leak into the cached commit.
+	c, err := r.CatFileCommit("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, c.Stats)
+	assert.Empty(t, c.Note)
+}
+
+func TestRepository_CommitExists(t *testing.T) {
+	blobID, err := testrepo.RevParse("master:README.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	treeID, err := testrepo.RevParse("master^{tree}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	masterID, err := testrepo.RevParse("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		id        string
+		expExists bool
+		expErr    error
+	}{
+		{id: masterID, expExists: true},
+		{id: "v1.1.0", expExists: true},
+		{id: blobID, expErr: ErrNotCommit},
+		{id: treeID, expErr: ErrNotCommit},
+		{id: "0000000000000000000000000000000000000001"},
+		{id: "bad_revision"},
+	}
+	for _, test := range tests {
+		t.Run(test.id, func(t *testing.T) {
+			exists, err := testrepo.CommitExists(test.id)
+			assert.Equal(t, test.expErr, err)
+			assert.Equal(t, test.expExists, exists)
+		})
+	}
+}
+
 func TestRepository_BranchCommit(t *testing.T) {
 	t.Run("invalid branch", func(t *testing.T) {
 		c, err := testrepo.BranchCommit("refs/heads/release-1.0")
@@ -128,6 +218,123 @@ func TestRepository_Log(t *testing.T) {
 	}
 }
 
+func TestRepository_Log_notes(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	for _, args := range [][]string{
+		{"notes", "add", "-m", "CI: passed", "-m", "Coverage: 80%", "master"},
+		{"notes", "--ref", "ci", "add", "-m", "Build #1", "master"},
+	} {
+		_, err = NewCommand(args...).RunInDir(r.Path())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		opt      LogOptions
+		expNotes []string
+	}{
+		{
+			name:     "default notes",
+			opt:      LogOptions{MaxCount: 2, ShowNotes: true},
+			expNotes: []string{"CI: passed\n\nCoverage: 80%", ""},
+		},
+		{
+			name:     "notes reference",
+			opt:      LogOptions{MaxCount: 2, ShowNotes: true, NotesRef: "refs/notes/ci"},
+			expNotes: []string{"Build #1", ""},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			commits, err := r.Log("master", test.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			notes := make([]string, 0, len(commits))
+			for _, c := range commits {
+				notes = append(notes, c.Note)
+			}
+			assert.Equal(t, test.expNotes, notes)
+			assert.NotContains(t, commits[0].Message, "CI: passed")
+		})
+	}
+}
+
+func TestRepository_Log_paths(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	var expCommitIDs []string
+	for _, file := range []string{"api/api.go", "other.txt", "client/client.go", "api/api_test.go"} {
+		if err = commitFile(r, file, "content", "Add "+file); err != nil {
+			t.Fatal(err)
+		}
+
+		if file == "other.txt" {
+			continue
+		}
+
+		id, err := r.RevParse("HEAD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		expCommitIDs = append([]string{id}, expCommitIDs...)
+	}
+
+	tests := []struct {
+		opt          LogOptions
+		expCommitIDs []string
+	}{
+		{
+			opt: LogOptions{
+				Paths: []string{"api", "client"},
+			},
+			expCommitIDs: expCommitIDs,
+		},
+		{
+			opt: LogOptions{
+				Path:  "api",
+				Paths: []string{"client"},
+			},
+			expCommitIDs: expCommitIDs,
+		},
+		{
+			opt: LogOptions{
+				Paths:    []string{"api", "client"},
+				MaxCount: 2,
+			},
+			expCommitIDs: expCommitIDs[:2],
+		},
+		{
+			opt: LogOptions{
+				Paths: []string{"client", "404"},
+			},
+			expCommitIDs: expCommitIDs[1:2],
+		},
+	}
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			commits, err := r.Log("master", test.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, test.expCommitIDs, commitsToIDs(commits))
+		})
+	}
+}
+
 func TestRepository_CommitByRevision(t *testing.T) {
 	t.Run("invalid revision", func(t *testing.T) {
 		c, err := testrepo.CommitByRevision("bad_revision")
@@ -157,6 +364,117 @@ func TestRepository_CommitByRevision(t *testing.T) {
 	}
 }
 
+func Test_parseShortStat(t *testing.T) {
+	tests := []struct {
+		line     string
+		expStats *CommitStats
+	}{
+		{
+			line:     " 3 files changed, 10 insertions(+), 2 deletions(-)",
+			expStats: &CommitStats{FilesChanged: 3, Insertions: 10, Deletions: 2},
+		},
+		{
+			line:     " 1 file changed, 1 insertion(+)",
+			expStats: &CommitStats{FilesChanged: 1, Insertions: 1},
+		},
+		{
+			line:     " 1 file changed, 1 deletion(-)",
+			expStats: &CommitStats{FilesChanged: 1, Deletions: 1},
+		},
+		{
+			line:     " 2 files changed",
+			expStats: &CommitStats{FilesChanged: 2},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			stats, err := parseShortStat(test.line)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, test.expStats, stats)
+		})
+	}
+
+	for _, line := range []string{"", "files changed", "x files changed", " 1 unknown(+)"} {
+		_, err := parseShortStat(line)
+		assert.Error(t, err, line)
+	}
+}
+
+func TestRepository_CommitByRevision_loadStats(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	err = ioutil.WriteFile(filepath.Join(r.Path(), "a.txt"), []byte("1\n2\n3\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = commitFile(r, "b.txt", "1\n", "Add a.txt and b.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = commitFile(r, "a.txt", "1\nchanged\n", "Change a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		rev      string
+		opt      CommitByRevisionOptions
+		expStats *CommitStats
+	}{
+		{
+			name:     "without stats",
+			rev:      "HEAD",
+			expStats: nil,
+		},
+		{
+			name:     "latest",
+			rev:      "HEAD",
+			opt:      CommitByRevisionOptions{LoadStats: true},
+			expStats: &CommitStats{FilesChanged: 1, Insertions: 1, Deletions: 2},
+		},
+		{
+			name:     "previous",
+			rev:      "HEAD~1",
+			opt:      CommitByRevisionOptions{LoadStats: true},
+			expStats: &CommitStats{FilesChanged: 2, Insertions: 4},
+		},
+		{
+			name:     "by path",
+			rev:      "HEAD",
+			opt:      CommitByRevisionOptions{Path: "b.txt", LoadStats: true},
+			expStats: &CommitStats{FilesChanged: 2, Insertions: 4},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Use a fresh repository to not share cached commits between cases
+			r, err := Open(r.Path())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c, err := r.CommitByRevision(test.rev, test.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, test.expStats, c.Stats)
+		})
+	}
+
+	t.Run("invalid revision", func(t *testing.T) {
+		c, err := r.CommitByRevision("bad_revision", CommitByRevisionOptions{LoadStats: true})
+		assert.Equal(t, ErrRevisionNotExist, err)
+		assert.Nil(t, c)
+	})
+}
+
 func TestRepository_CommitsSince(t *testing.T) {
 	tests := []struct {
 		rev          string
@@ -190,6 +508,295 @@ func TestRepository_CommitsSince(t *testing.T) {
 	}
 }
 
+func TestRepository_CommitsTouching(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	var expCommitIDs []string
+	for _, file := range []string{"top.glob", "nested/dir/deep.glob", "other.txt"} {
+		if err = commitFile(r, file, "content", "Add "+file); err != nil {
+			t.Fatal(err)
+		}
+
+		if file == "other.txt" {
+			continue
+		}
+
+		id, err := r.RevParse("HEAD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		expCommitIDs = append([]string{id}, expCommitIDs...)
+	}
+
+	tests := []struct {
+		pattern      string
+		opt          CommitsTouchingOptions
+		expCommitIDs []string
+	}{
+		{
+			pattern:      "**/*.glob",
+			expCommitIDs: expCommitIDs,
+		},
+		{
+			pattern: "**/*.glob",
+			opt: CommitsTouchingOptions{
+				MaxCount: 1,
+			},
+			expCommitIDs: expCommitIDs[:1],
+		},
+		{
+			pattern:      "*.glob",
+			expCommitIDs: expCommitIDs[1:],
+		},
+		{
+			pattern:      "**/*.nonexistent",
+			expCommitIDs: []string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			commits, err := r.CommitsTouching("master", test.pattern, test.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, test.expCommitIDs, commitsToIDs(commits))
+		})
+	}
+}
+
+func Test_parseFileHistory(t *testing.T) {
+	const (
+		id1 = "1111111111111111111111111111111111111111"
+		id2 = "2222222222222222222222222222222222222222"
+		id3 = "3333333333333333333333333333333333333333"
+	)
+	data := "\x00" + id3 + "\x00\nM\x00new name.txt\x00" +
+		"\x00" + id2 + "\x00\nR100\x00old.txt\x00new name.txt\x00" +
+		"\x00" + id1 + "\x00\nA\x00old.txt\x00"
+
+	entries, ids, err := parseFileHistory([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{id3, id2, id1}, ids)
+	assert.Equal(t,
+		[]*FileHistoryEntry{
+			{Status: DiffFileChange, Path: "new name.txt"},
+			{Status: DiffFileRename, Path: "new name.txt", OldPath: "old.txt"},
+			{Status: DiffFileAdd, Path: "old.txt"},
+		},
+		entries,
+	)
+
+	for _, data := range []string{
+		"\x00" + id1 + "\x00",
+		"\x00" + id1 + "\x00\nX\x00old.txt\x00",
+		"\x00" + id1 + "\x00\nR100\x00old.txt",
+	} {
+		_, _, err = parseFileHistory([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestRepository_FileHistory(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	var ids []string
+	commit := func(fn func() error) {
+		if err := fn(); err != nil {
+			t.Fatal(err)
+		}
+		id, err := r.RevParse("HEAD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append([]string{id}, ids...)
+	}
+
+	commit(func() error {
+		return commitFile(r, "history.txt", "1\n2\n3\n4\n5\n", "Add history.txt")
+	})
+	commit(func() error {
+		return commitFile(r, "history.txt", "1\n2\n3\n4\n5\n6\n", "Change history.txt")
+	})
+	commit(func() error {
+		err := r.Move("history.txt", "renamed.txt")
+		if err != nil {
+			return err
+		}
+		return r.Commit(&Signature{Name: "alice", Email: "alice@example.com"}, "Rename history.txt")
+	})
+	// A commit that does not touch the file should not be listed.
+	err = commitFile(r, "other.txt", "other", "Add other.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit(func() error {
+		return commitFile(r, "renamed.txt", "1\n2\n3\n4\n5\n6\n7\n", "Change renamed.txt")
+	})
+
+	entries, err := r.FileHistory("master", "renamed.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []struct {
+		status  DiffFileType
+		path    string
+		oldPath string
+	}{
+		{DiffFileChange, "renamed.txt", ""},
+		{DiffFileRename, "renamed.txt", "history.txt"},
+		{DiffFileChange, "history.txt", ""},
+		{DiffFileAdd, "history.txt", ""},
+	}
+	if !assert.Len(t, entries, len(exp)) {
+		return
+	}
+	for i, e := range exp {
+		assert.Equal(t, ids[i], entries[i].Commit.ID.String())
+		assert.Equal(t, e.status, entries[i].Status)
+		assert.Equal(t, e.path, entries[i].Path)
+		assert.Equal(t, e.oldPath, entries[i].OldPath)
+	}
+
+	t.Run("max count and skip", func(t *testing.T) {
+		entries, err := r.FileHistory("master", "renamed.txt", FileHistoryOptions{MaxCount: 1, Skip: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if assert.Len(t, entries, 1) {
+			assert.Equal(t, ids[1], entries[0].Commit.ID.String())
+		}
+	})
+
+	t.Run("file does not exist", func(t *testing.T) {
+		entries, err := r.FileHistory("master", "404.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Empty(t, entries)
+	})
+
+	t.Run("bad revision", func(t *testing.T) {
+		_, err := r.FileHistory("404", "renamed.txt")
+		assert.Equal(t, ErrRevisionNotExist, err)
+	})
+}
+
+func TestRepository_GraphLog(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	err = r.Checkout("feature", CheckoutOptions{BaseBranch: "master"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = commitFile(r, "feature.txt", "feature", "Add feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	featureID, err := r.RevParse("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.Checkout("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = commitFile(r, "master.txt", "master", "Add master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	masterID, err := r.RevParse("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = NewCommand("merge", "--no-ff", "-m", "Merge feature", "feature").
+		AddEnvs("GIT_COMMITTER_NAME=alice", "GIT_COMMITTER_EMAIL=alice@example.com",
+			"GIT_AUTHOR_NAME=alice", "GIT_AUTHOR_EMAIL=alice@example.com").
+		RunInDir(r.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mergeID, err := r.RevParse("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines, err := r.GraphLog("master", GraphLogOptions{MaxCount: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type line struct {
+		graph   string
+		id      string
+		subject string
+	}
+	got := make([]line, len(lines))
+	for i, l := range lines {
+		got[i] = line{graph: l.Graph, subject: l.Subject}
+		if l.IsCommit() {
+			got[i].id = l.ID.String()
+		}
+	}
+
+	// The merge parents are listed in reverse chronological order, and the
+	// order of the two depends on their commit time which may be the same.
+	assert.Len(t, got, 5)
+	assert.Equal(t, line{graph: "*", id: mergeID, subject: "Merge feature"}, got[0])
+	assert.Equal(t, line{graph: "|\\"}, got[1])
+	assert.False(t, lines[1].IsCommit())
+	assert.ElementsMatch(t,
+		[]string{masterID, featureID},
+		[]string{got[2].id, got[3].id},
+	)
+	assert.Equal(t, line{graph: "|/"}, got[4])
+}
+
+func Test_parseGraphLog(t *testing.T) {
+	data := "*   \x00" + EmptyID + " Merge\n" +
+		"|\\  \n" +
+		"| * \x00" + EmptyID + " Side\n" +
+		"* | \x00" + EmptyID + " Main\n" +
+		"|/  \n" +
+		"* \x00" + EmptyID + "\n"
+
+	lines, err := parseGraphLog([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	emptyID := MustIDFromString(EmptyID)
+	exp := []*GraphLine{
+		{Graph: "*", ID: emptyID, Subject: "Merge"},
+		{Graph: "|\\"},
+		{Graph: "| *", ID: emptyID, Subject: "Side"},
+		{Graph: "* |", ID: emptyID, Subject: "Main"},
+		{Graph: "|/"},
+		{Graph: "*", ID: emptyID},
+	}
+	assert.Equal(t, exp, lines)
+
+	_, err = parseGraphLog([]byte("* \x00bad Subject\n"))
+	assert.Error(t, err)
+}
+
 func TestRepository_DiffNameOnly(t *testing.T) {
 	tests := []struct {
 		base     string
@@ -292,6 +899,251 @@ func TestRepository_RevListCount(t *testing.T) {
 	}
 }
 
+func TestRepository_CommitCountBetween(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	base, err := r.RevParse("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Diverge: two commits on "master" and one commit on "feature"
+	for _, file := range []string{"a.txt", "b.txt"} {
+		if err = commitFile(r, file, "content", "Add "+file); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = r.Checkout("feature", CheckoutOptions{BaseBranch: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = commitFile(r, "c.txt", "content", "Add c.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		base     string
+		head     string
+		opt      CommitCountBetweenOptions
+		expCount int64
+	}{
+		{
+			base:     base,
+			head:     "master",
+			expCount: 2,
+		},
+		{
+			base:     "master",
+			head:     "feature",
+			expCount: 1,
+		},
+		{
+			base: "master",
+			head: "feature",
+			opt: CommitCountBetweenOptions{
+				NeedsMergeBase: true,
+			},
+			expCount: 3,
+		},
+		{
+			base: base,
+			head: "master",
+			opt: CommitCountBetweenOptions{
+				Path: "a.txt",
+			},
+			expCount: 1,
+		},
+		{
+			base:     "master",
+			head:     "master",
+			expCount: 0,
+		},
+	}
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			count, err := r.CommitCountBetween(test.base, test.head, test.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, test.expCount, count)
+		})
+	}
+}
+
+func TestRepository_CommitNavigation(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	// The history of master is "c1 - c2 - merge - c3", where "merge" merges the
+	// "side" branch that is created from c1.
+	revParse := func(rev string) string {
+		id, err := r.RevParse(rev)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	err = commitFile(r, "nav.txt", "1", "Commit 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c1 := revParse("HEAD")
+	err = r.Checkout("side", CheckoutOptions{BaseBranch: "master"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = commitFile(r, "side.txt", "side", "Side commit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	side := revParse("HEAD")
+	err = r.Checkout("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = commitFile(r, "nav.txt", "2", "Commit 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2 := revParse("HEAD")
+	_, err = NewCommand("merge", "--no-ff", "-m", "Merge side", "side").RunInDir(r.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	merge := revParse("HEAD")
+	err = commitFile(r, "nav.txt", "3", "Commit 3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c3 := revParse("HEAD")
+
+	stdout, err := NewCommand("rev-list", "--first-parent", "--reverse", "master").RunInDir(r.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	history := strings.Fields(string(stdout))
+
+	tests := []struct {
+		rev     string
+		expPrev string
+		expNext string
+	}{
+		{rev: c2, expPrev: c1, expNext: merge},
+		{rev: merge, expPrev: c2, expNext: c3},
+		{rev: c3, expPrev: merge, expNext: ""},
+		{rev: side, expPrev: c1, expNext: ""},
+		{rev: history[0], expPrev: "", expNext: history[1]},
+	}
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			prev, next, err := r.CommitNavigation(test.rev, "master")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var prevID, nextID string
+			if prev != nil {
+				prevID = prev.ID.String()
+			}
+			if next != nil {
+				nextID = next.ID.String()
+			}
+			assert.Equal(t, test.expPrev, prevID)
+			assert.Equal(t, test.expNext, nextID)
+		})
+	}
+
+	t.Run("branch does not exist", func(t *testing.T) {
+		_, _, err := r.CommitNavigation(c2, "404")
+		assert.Equal(t, ErrRevisionNotExist, err)
+	})
+}
+
+func TestRepository_CommitCountByAuthor(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	base, err := r.RevParse("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, email := range []string{"bob@example.com", "bob@personal.com", "alice@example.com", "bob@example.com"} {
+		author := &Signature{
+			Name:  strings.Split(email, "@")[0],
+			Email: email,
+		}
+		_, err = r.CommitFiles(CommitFilesOptions{
+			Base:      "master",
+			Files:     []CommitFile{{Path: "author.txt", Content: []byte(strconv.Itoa(i))}},
+			Author:    author,
+			Committer: author,
+			Message:   "Change author.txt",
+			Branch:    "master",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = ioutil.WriteFile(filepath.Join(r.Path(), ".mailmap"), []byte("<bob@example.com> <bob@personal.com>\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		revRange  string
+		opt       CommitCountByAuthorOptions
+		expCounts map[string]int
+	}{
+		{
+			revRange: base + "..master",
+			expCounts: map[string]int{
+				"bob@example.com":   2,
+				"bob@personal.com":  1,
+				"alice@example.com": 1,
+			},
+		},
+		{
+			revRange: base + "..master",
+			opt: CommitCountByAuthorOptions{
+				UseMailmap: true,
+			},
+			expCounts: map[string]int{
+				"bob@example.com":   3,
+				"alice@example.com": 1,
+			},
+		},
+		{
+			revRange:  "master..master",
+			expCounts: map[string]int{},
+		},
+	}
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			counts, err := r.CommitCountByAuthor(test.revRange, test.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, test.expCounts, counts)
+		})
+	}
+
+	t.Run("bad revision", func(t *testing.T) {
+		_, err := r.CommitCountByAuthor("404..master")
+		assert.Equal(t, ErrRevisionNotExist, err)
+	})
+}
+
 func TestRepository_RevList(t *testing.T) {
 	t.Run("no refspecs", func(t *testing.T) {
 		commits, err := testrepo.RevList([]string{})
@@ -357,3 +1209,79 @@ func TestRepository_LatestCommitTime(t *testing.T) {
 		})
 	}
 }
+
+func TestRepository_LatestCommitTime_path(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	commitAt := func(name string, when time.Time) {
+		err := ioutil.WriteFile(filepath.Join(r.Path(), name), []byte(name), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = r.Add(AddOptions{All: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = r.Commit(
+			&Signature{Name: "alice", Email: "alice@example.com"},
+			"Add "+name,
+			CommitOptions{
+				CommandOptions: CommandOptions{
+					Envs: []string{"GIT_COMMITTER_DATE=" + when.Format(time.RFC3339)},
+				},
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dirTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	err = os.MkdirAll(filepath.Join(r.Path(), "dir"), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitAt("dir/a.txt", dirTime)
+	fileTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	commitAt("b.txt", fileTime)
+
+	tests := []struct {
+		name    string
+		opt     LatestCommitTimeOptions
+		expTime time.Time
+	}{
+		{
+			name:    "directory",
+			opt:     LatestCommitTimeOptions{Path: "dir"},
+			expTime: dirTime,
+		},
+		{
+			name:    "file in directory",
+			opt:     LatestCommitTimeOptions{Path: "dir/a.txt"},
+			expTime: dirTime,
+		},
+		{
+			name:    "file on branch",
+			opt:     LatestCommitTimeOptions{Branch: "master", Path: "b.txt"},
+			expTime: fileTime,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := r.LatestCommitTime(test.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, test.expTime.Unix(), got.Unix())
+		})
+	}
+
+	t.Run("no history", func(t *testing.T) {
+		_, err := r.LatestCommitTime(LatestCommitTimeOptions{Path: "404.txt"})
+		assert.Equal(t, ErrRevisionNotExist, err)
+	})
+}
@@ -0,0 +1,251 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bytes"
+	"strings"
+	"time"
+)
+
+// ConfigEntriesOptions contains optional arguments for listing configuration
+// entries.
+//
+// Docs: https://git-scm.com/docs/git-config
+type ConfigEntriesOptions struct {
+	// Indicates whether to list all configuration entries. The regular expression
+	// is ignored when set.
+	List bool
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// ConfigEntries returns configuration entries whose keys match given regular
+// expression (e.g. `^remote\.origin\.`) for the repository. Keys are in the
+// canonical form (e.g. "remote.origin.url") as reported by Git. When a key has
+// multiple values, the last one wins. It returns an empty map when no entry
+// matches.
+func (r *Repository) ConfigEntries(regex string, opts ...ConfigEntriesOptions) (map[string]string, error) {
+	var opt ConfigEntriesOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	cmd := NewCommand("config", "--null").AddOptions(opt.CommandOptions)
+	if opt.List {
+		cmd.AddArgs("--list")
+	} else {
+		cmd.AddArgs("--get-regexp", regex)
+	}
+
+	stdout, err := cmd.RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		// No stderr but exit code 1 means no entry matches.
+		if isExitCode(err, 1) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+	return parseConfigEntries(stdout), nil
+}
+
+// parseConfigEntries parses entries from the output of "git config --null",
+// where each entry is terminated by a NUL byte, and the key is separated from
+// the value by a newline. A key without the newline has no value, i.e. a
+// boolean that is set with "[section] key".
+func parseConfigEntries(data []byte) map[string]string {
+	entries := make(map[string]string)
+	for _, entry := range bytes.Split(data, []byte{0}) {
+		if len(entry) == 0 {
+			continue
+		}
+
+		i := bytes.IndexByte(entry, '\n')
+		if i < 0 {
+			entries[string(entry)] = ""
+			continue
+		}
+		entries[string(entry[:i])] = string(entry[i+1:])
+	}
+	return entries
+}
+
+// ConfigOptions contains optional arguments for reading and writing the
+// configuration.
+//
+// Docs: https://git-scm.com/docs/git-config
+type ConfigOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// Config returns the value of the key (e.g. "core.bare") in the local
+// configuration of the repository, i.e. the global and system configurations
+// are not read. When the key has multiple values, the last one wins. It returns
+// ErrConfigKeyNotExist if the key is not set.
+func (r *Repository) Config(key string, opts ...ConfigOptions) (string, error) {
+	var opt ConfigOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	// 🚨 SECURITY: Prevent including unintended options in the path to the Git command.
+	stdout, err := NewCommand("config", "--local", "--get").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--end-of-options", key).
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		// No stderr but exit code 1 means the key is not set.
+		if isExitCode(err, 1) {
+			return "", ErrConfigKeyNotExist
+		}
+		return "", err
+	}
+	return strings.TrimSuffix(string(stdout), "\n"), nil
+}
+
+// ConfigAll returns all values of the multivalued key (e.g.
+// "remote.origin.fetch") in the local configuration of the repository. It
+// returns ErrConfigKeyNotExist if the key is not set.
+func (r *Repository) ConfigAll(key string, opts ...ConfigOptions) ([]string, error) {
+	var opt ConfigOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	// 🚨 SECURITY: Prevent including unintended options in the path to the Git command.
+	stdout, err := NewCommand("config", "--local", "--null", "--get-all").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--end-of-options", key).
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		// No stderr but exit code 1 means the key is not set.
+		if isExitCode(err, 1) {
+			return nil, ErrConfigKeyNotExist
+		}
+		return nil, err
+	}
+
+	values := strings.Split(string(stdout), "\x00")
+	return values[:len(values)-1], nil
+}
+
+// SetConfig sets the key to the value in the local configuration of the
+// repository, and replaces all existing values of the key.
+func (r *Repository) SetConfig(key, value string, opts ...ConfigOptions) error {
+	var opt ConfigOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	// 🚨 SECURITY: Prevent including unintended options in the path to the Git command.
+	_, err := NewCommand("config", "--local", "--replace-all").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--end-of-options", key, value).
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	return err
+}
+
+// UnsetConfig removes all values of the key from the local configuration of
+// the repository. It returns ErrConfigKeyNotExist if the key is not set.
+func (r *Repository) UnsetConfig(key string, opts ...ConfigOptions) error {
+	var opt ConfigOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	// 🚨 SECURITY: Prevent including unintended options in the path to the Git command.
+	_, err := NewCommand("config", "--local", "--unset-all").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--end-of-options", key).
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		// No stderr but exit code 5 means the key is not set.
+		if isExitCode(err, 5) {
+			return ErrConfigKeyNotExist
+		}
+		return err
+	}
+	return nil
+}
+
+// DeltaIslandsOptions contains optional arguments for reading and writing the
+// delta islands configuration.
+//
+// Docs: https://git-scm.com/docs/git-pack-objects#_delta_islands
+type DeltaIslandsOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// DeltaIslands returns the regular expressions of references that define the
+// delta islands, i.e. the values of "pack.island", of the repository. It
+// returns an empty list when none is configured.
+func (r *Repository) DeltaIslands(opts ...DeltaIslandsOptions) ([]string, error) {
+	var opt DeltaIslandsOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	stdout, err := NewCommand("config", "--get-all").
+		AddOptions(opt.CommandOptions).
+		AddArgs("pack.island").
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		// No stderr but exit code 1 means the key is not set.
+		if isExitCode(err, 1) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	return bytesToStrings(stdout), nil
+}
+
+// SetDeltaIslands replaces the regular expressions of references that define
+// the delta islands of the repository, e.g. `refs/virtual/([0-9]+)/heads/` to
+// put the branches of each fork in a network on its own island. Deltas are only
+// restricted to islands when repacking with RepackOptions.DeltaIslands. Passing
+// an empty list removes the configuration.
+func (r *Repository) SetDeltaIslands(regexes []string, opts ...DeltaIslandsOptions) error {
+	var opt DeltaIslandsOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	_, err := NewCommand("config", "--unset-all").
+		AddOptions(opt.CommandOptions).
+		AddArgs("pack.island").
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	// No stderr but exit code 5 means the key is not set.
+	if err != nil && !isExitCode(err, 5) {
+		return err
+	}
+
+	for _, regex := range regexes {
+		_, err = NewCommand("config", "--add").
+			AddOptions(opt.CommandOptions).
+			AddArgs("pack.island", regex).
+			RunInDirWithTimeout(opt.Timeout, r.path)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
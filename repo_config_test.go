@@ -0,0 +1,202 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseConfigEntries(t *testing.T) {
+	tests := []struct {
+		data       string
+		expEntries map[string]string
+	}{
+		{
+			data:       "",
+			expEntries: map[string]string{},
+		},
+		{
+			data: "core.bare\nfalse\x00user.name\nAlice Bob\x00core.flag\x00alias.lg\nlog --graph\n--oneline\x00",
+			expEntries: map[string]string{
+				"core.bare": "false",
+				"user.name": "Alice Bob",
+				"core.flag": "",
+				"alias.lg":  "log --graph\n--oneline",
+			},
+		},
+		{
+			data: "remote.origin.fetch\n+refs/heads/*:refs/remotes/origin/*\x00remote.origin.fetch\n+refs/tags/*:refs/tags/*\x00",
+			expEntries: map[string]string{
+				"remote.origin.fetch": "+refs/tags/*:refs/tags/*",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			assert.Equal(t, test.expEntries, parseConfigEntries([]byte(test.data)))
+		})
+	}
+}
+
+func TestRepository_ConfigEntries(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	_, err = NewCommand("config", "test.with-spaces", "a value with spaces").RunInDir(r.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("match by regexp", func(t *testing.T) {
+		entries, err := r.ConfigEntries(`^test\.`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, map[string]string{"test.with-spaces": "a value with spaces"}, entries)
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		entries, err := r.ConfigEntries(`^nonexistent\.`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Empty(t, entries)
+	})
+
+	t.Run("list all", func(t *testing.T) {
+		entries, err := r.ConfigEntries("", ConfigEntriesOptions{List: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "a value with spaces", entries["test.with-spaces"])
+		assert.Equal(t, testrepo.Path(), entries["remote.origin.url"])
+	})
+}
+
+func TestRepository_Config(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	const key = "gogs.defaultbranch"
+	_, err = r.Config(key)
+	assert.Equal(t, ErrConfigKeyNotExist, err)
+	_, err = r.ConfigAll(key)
+	assert.Equal(t, ErrConfigKeyNotExist, err)
+	err = r.UnsetConfig(key)
+	assert.Equal(t, ErrConfigKeyNotExist, err)
+
+	err = r.SetConfig(key, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := r.Config(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "main", value)
+
+	// Multiple values
+	_, err = NewCommand("config", "--add", key, "-develop\nline").RunInDir(r.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := r.ConfigAll(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"main", "-develop\nline"}, values)
+	value, err = r.Config(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "-develop\nline", value)
+
+	// Setting replaces all values
+	err = r.SetConfig(key, "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err = r.ConfigAll(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"master"}, values)
+
+	err = r.UnsetConfig(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.Config(key)
+	assert.Equal(t, ErrConfigKeyNotExist, err)
+
+	// Invalid key
+	_, err = r.Config("invalid")
+	assert.Error(t, err)
+	assert.NotEqual(t, ErrConfigKeyNotExist, err)
+
+	// Keys are never treated as options
+	_, err = r.Config("--list")
+	assert.Error(t, err)
+	assert.NotEqual(t, ErrConfigKeyNotExist, err)
+	err = r.SetConfig("--add", key)
+	assert.Error(t, err)
+	_, err = r.Config(key)
+	assert.Equal(t, ErrConfigKeyNotExist, err)
+}
+
+func TestRepository_DeltaIslands(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	islands, err := r.DeltaIslands()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, islands)
+
+	regexes := []string{`refs/virtual/([0-9]+)/heads/`, `refs/virtual/([0-9]+)/tags/`}
+	for i := 0; i < 2; i++ {
+		// Setting the same islands again replaces rather than appends.
+		err = r.SetDeltaIslands(regexes)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	islands, err = r.DeltaIslands()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, regexes, islands)
+
+	err = r.Repack(RepackOptions{
+		All:             true,
+		DeleteRedundant: true,
+		DeltaIslands:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.SetDeltaIslands(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	islands, err = r.DeltaIslands()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, islands)
+}
@@ -8,6 +8,9 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -18,6 +21,24 @@ type DiffOptions struct {
 	// The commit ID to used for computing diff between a range of commits (base,
 	// revision]. When not set, only computes diff for a single commit at revision.
 	Base string
+	// The number of context lines to show around each change. Git's default is
+	// used when not set.
+	ContextLines int
+	// Indicates whether to show no context lines at all, which takes precedence
+	// over ContextLines. Each section then only contains changed lines.
+	ZeroContext bool
+	// The number of context lines between sections, up to which the sections are
+	// merged into one.
+	InterHunkContext int
+	// Indicates whether to ignore whitespace when comparing lines, even if one
+	// line has whitespace where the other line has none.
+	IgnoreAllSpace bool
+	// Indicates whether to ignore changes in amount of whitespace.
+	IgnoreSpaceChange bool
+	// Indicates whether to ignore changes in whitespace at end of line.
+	IgnoreSpaceAtEOL bool
+	// Indicates whether to ignore changes whose lines are all blank.
+	IgnoreBlankLines bool
 	// The timeout duration before giving up for each shell command execution. The
 	// default timeout duration will be used when not supplied.
 	//
@@ -27,7 +48,42 @@ type DiffOptions struct {
 	CommandOptions
 }
 
+// contextArgs returns the arguments to control context lines of the diff.
+func (opt DiffOptions) contextArgs() []string {
+	var args []string
+	if opt.ZeroContext {
+		args = append(args, "--unified=0")
+	} else if opt.ContextLines > 0 {
+		args = append(args, "--unified="+strconv.Itoa(opt.ContextLines))
+	}
+	if opt.InterHunkContext > 0 {
+		args = append(args, "--inter-hunk-context="+strconv.Itoa(opt.InterHunkContext))
+	}
+	return args
+}
+
+// whitespaceArgs returns the arguments to control how whitespace changes are
+// treated in the diff.
+func (opt DiffOptions) whitespaceArgs() []string {
+	var args []string
+	if opt.IgnoreAllSpace {
+		args = append(args, "--ignore-all-space")
+	}
+	if opt.IgnoreSpaceChange {
+		args = append(args, "--ignore-space-change")
+	}
+	if opt.IgnoreSpaceAtEOL {
+		args = append(args, "--ignore-space-at-eol")
+	}
+	if opt.IgnoreBlankLines {
+		args = append(args, "--ignore-blank-lines")
+	}
+	return args
+}
+
 // Diff returns a parsed diff object between given commits of the repository.
+// The first commit of the repository is compared against the empty tree when
+// no base is given.
 func (r *Repository) Diff(rev string, maxFiles, maxFileLines, maxLineChars int, opts ...DiffOptions) (*Diff, error) {
 	var opt DiffOptions
 	if len(opts) > 0 {
@@ -39,34 +95,39 @@ func (r *Repository) Diff(rev string, maxFiles, maxFileLines, maxLineChars int,
 		return nil, err
 	}
 
-	cmd := NewCommand()
-	if opt.Base == "" {
-		// First commit of repository
+	base := opt.Base
+	if base == "" {
+		// The first commit of repository is compared against the empty tree, so
+		// that all of its files are shown as added.
 		if commit.ParentsCount() == 0 {
-			cmd = cmd.AddArgs("show").
-				AddOptions(opt.CommandOptions).
-				AddArgs("--full-index", rev)
+			base = EmptyTreeID
 		} else {
 			c, err := commit.Parent(0)
 			if err != nil {
 				return nil, err
 			}
-			cmd = cmd.AddArgs("diff").
-				AddOptions(opt.CommandOptions).
-				AddArgs("--full-index", "-M", c.ID.String(), rev)
+			base = c.ID.String()
 		}
-	} else {
-		cmd = cmd.AddArgs("diff").
-			AddOptions(opt.CommandOptions).
-			AddArgs("--full-index", "-M", opt.Base, rev)
 	}
 
+	cmd := NewCommand("diff").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--full-index", "-M").
+		AddArgs(opt.contextArgs()...).
+		AddArgs(opt.whitespaceArgs()...).
+		AddArgs(base, rev)
+
+	return r.streamParseDiff(cmd, opt.Timeout, maxFiles, maxFileLines, maxLineChars)
+}
+
+// streamParseDiff runs the command and parses its output as a diff.
+func (r *Repository) streamParseDiff(cmd *Command, timeout time.Duration, maxFiles, maxFileLines, maxLineChars int) (*Diff, error) {
 	stdout, w := io.Pipe()
 	done := make(chan SteamParseDiffResult)
 	go StreamParseDiff(stdout, done, maxFiles, maxFileLines, maxLineChars)
 
-	stderr := new(bytes.Buffer)
-	err = cmd.RunInDirPipelineWithTimeout(opt.Timeout, w, stderr, r.path)
+	stderr := newTailBuffer(stderrLimit)
+	err := cmd.RunInDirPipelineWithTimeout(timeout, w, stderr, r.path)
 	_ = w.Close() // Close writer to exit parsing goroutine
 	if err != nil {
 		return nil, concatenateError(err, stderr.String())
@@ -76,6 +137,43 @@ func (r *Repository) Diff(rev string, maxFiles, maxFileLines, maxLineChars int,
 	return result.Diff, result.Err
 }
 
+// StagedDiffOptions contains optional arguments for parsing the staged diff.
+//
+// Docs: https://git-scm.com/docs/git-diff#Documentation/git-diff.txt---cached
+type StagedDiffOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// StagedDiff returns a parsed diff object between the index and HEAD of the
+// repository, i.e. what is about to be committed. The index is compared with
+// the empty tree when there is no commit yet.
+func (r *Repository) StagedDiff(maxFiles, maxFileLines, maxLineChars int, opts ...StagedDiffOptions) (*Diff, error) {
+	var opt StagedDiffOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	base := "HEAD"
+	_, err := r.RevParse(base, RevParseOptions{Timeout: opt.Timeout}) //nolint
+	if err != nil {
+		if err != ErrRevisionNotExist {
+			return nil, err
+		}
+		base = EmptyTreeID
+	}
+
+	cmd := NewCommand("diff").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--cached", "--full-index", "-M", base)
+	return r.streamParseDiff(cmd, opt.Timeout, maxFiles, maxFileLines, maxLineChars)
+}
+
 // RawDiffFormat is the format of a raw diff.
 type RawDiffFormat string
 
@@ -88,6 +186,10 @@ const (
 //
 // Docs: https://git-scm.com/docs/git-format-patch
 type RawDiffOptions struct {
+	// When to include ANSI color codes in the output, i.e. "always", "never" or
+	// "auto". The "auto" mode colors the output only when the io.Writer is a
+	// terminal. The output is not colored when empty.
+	Color string
 	// The timeout duration before giving up for each shell command execution. The
 	// default timeout duration will be used when not supplied.
 	Timeout time.Duration
@@ -95,6 +197,33 @@ type RawDiffOptions struct {
 	CommandOptions
 }
 
+// isTerminal returns true if the io.Writer is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// colorArgs returns the arguments for the color mode of the output to the
+// io.Writer. The "auto" mode is resolved by this package because the output of
+// Git is never a terminal.
+func colorArgs(color string, w io.Writer) []string {
+	switch color {
+	case "":
+		return nil
+	case "auto":
+		if isTerminal(w) {
+			return []string{"--color=always"}
+		}
+		return []string{"--color=never"}
+	default:
+		return []string{"--color=" + color}
+	}
+}
+
 // RawDiff dumps diff of repository in given revision directly to given
 // io.Writer.
 func (r *Repository) RawDiff(rev string, diffType RawDiffFormat, w io.Writer, opts ...RawDiffOptions) error {
@@ -103,6 +232,7 @@ func (r *Repository) RawDiff(rev string, diffType RawDiffFormat, w io.Writer, op
 		opt = opts[0]
 	}
 
+	color := colorArgs(opt.Color, w)
 	commit, err := r.CatFileCommit(rev, CatFileCommitOptions{Timeout: opt.Timeout}) //nolint
 	if err != nil {
 		return err
@@ -114,6 +244,7 @@ func (r *Repository) RawDiff(rev string, diffType RawDiffFormat, w io.Writer, op
 		if commit.ParentsCount() == 0 {
 			cmd = cmd.AddArgs("show").
 				AddOptions(opt.CommandOptions).
+				AddArgs(color...).
 				AddArgs("--full-index", rev)
 		} else {
 			c, err := commit.Parent(0)
@@ -122,12 +253,14 @@ func (r *Repository) RawDiff(rev string, diffType RawDiffFormat, w io.Writer, op
 			}
 			cmd = cmd.AddArgs("diff").
 				AddOptions(opt.CommandOptions).
+				AddArgs(color...).
 				AddArgs("--full-index", "-M", c.ID.String(), rev)
 		}
 	case RawDiffPatch:
 		if commit.ParentsCount() == 0 {
 			cmd = cmd.AddArgs("format-patch").
 				AddOptions(opt.CommandOptions).
+				AddArgs(color...).
 				AddArgs("--full-index", "--no-signoff", "--no-signature", "--stdout", "--root", rev)
 		} else {
 			c, err := commit.Parent(0)
@@ -136,19 +269,69 @@ func (r *Repository) RawDiff(rev string, diffType RawDiffFormat, w io.Writer, op
 			}
 			cmd = cmd.AddArgs("format-patch").
 				AddOptions(opt.CommandOptions).
+				AddArgs(color...).
 				AddArgs("--full-index", "--no-signoff", "--no-signature", "--stdout", rev+"..."+c.ID.String())
 		}
 	default:
 		return fmt.Errorf("invalid diffType: %s", diffType)
 	}
 
-	stderr := new(bytes.Buffer)
+	stderr := newTailBuffer(stderrLimit)
 	if err = cmd.RunInDirPipelineWithTimeout(opt.Timeout, w, stderr, r.path); err != nil {
 		return concatenateError(err, stderr.String())
 	}
 	return nil
 }
 
+// MergeDiffOptions contains optional arguments for producing the diff of the
+// head revision against its merge base with the base revision.
+//
+// Docs: https://git-scm.com/docs/git-diff
+type MergeDiffOptions struct {
+	// When to include ANSI color codes in the output, i.e. "always", "never" or
+	// "auto". The "auto" mode colors the output only when the io.Writer is a
+	// terminal.
+	Color string
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// MergeDiff writes the diff of the changes that the head revision introduces
+// since its merge base with the base revision (i.e. "git diff base...head") to
+// the io.Writer, which is what a pull request shows as changed files. It
+// returns ErrNoMergeBase if the revisions have no common ancestor, or
+// ErrRevisionNotExist if either revision does not exist.
+func (r *Repository) MergeDiff(base, head string, w io.Writer, opts ...MergeDiffOptions) error {
+	var opt MergeDiffOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	stderr := newTailBuffer(stderrLimit)
+	err := NewCommand("diff").
+		AddOptions(opt.CommandOptions).
+		AddArgs(colorArgs(opt.Color, w)...).
+		AddArgs("--full-index", "-M").
+		// 🚨 SECURITY: Prevent including unintended options in the path to the Git command.
+		AddArgs("--end-of-options", base+"..."+head).
+		RunInDirPipelineWithTimeout(opt.Timeout, w, stderr, r.path)
+	if err != nil {
+		if strings.Contains(stderr.String(), "no merge base") {
+			return ErrNoMergeBase
+		} else if strings.Contains(stderr.String(), "unknown revision") ||
+			strings.Contains(stderr.String(), "bad revision") {
+			return ErrRevisionNotExist
+		}
+		return concatenateError(err, stderr.String())
+	}
+	return nil
+}
+
 // DiffBinaryOptions contains optional arguments for producing binary patch.
 type DiffBinaryOptions struct {
 	// The timeout duration before giving up for each shell command execution. The
@@ -171,3 +354,239 @@ func (r *Repository) DiffBinary(base, head string, opts ...DiffBinaryOptions) ([
 		AddArgs("--full-index", "--binary", base, head).
 		RunInDirWithTimeout(opt.Timeout, r.path)
 }
+
+// FilePatchOptions contains optional arguments for producing the patch of a
+// single file.
+//
+// Docs: https://git-scm.com/docs/git-diff
+type FilePatchOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// FilePatch returns the patch of the file with given path (relative to the
+// repository root) between base and head revisions. It is much cheaper than
+// producing the whole diff when only a few files are of interest. It returns an
+// empty patch if the file is not changed.
+func (r *Repository) FilePatch(base, head, path string, opts ...FilePatchOptions) ([]byte, error) {
+	var opt FilePatchOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	return NewCommand("diff").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--full-index", base, head, "--", escapePath(path)).
+		RunInDirWithTimeout(opt.Timeout, r.path)
+}
+
+// ChangedFilesOptions contains optional arguments for listing changed files
+// between two revisions.
+//
+// Docs: https://git-scm.com/docs/git-diff-tree
+type ChangedFilesOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// parseChangedFiles parses the output of "git diff-tree -r --name-status -z
+// --no-renames", which is pairs of the status and the path, each is terminated
+// by a NUL. A type change is reported as modified.
+func parseChangedFiles(data []byte) (*NameStatus, error) {
+	fields := strings.Split(strings.TrimSuffix(string(data), "\x00"), "\x00")
+	if len(fields) == 1 && fields[0] == "" {
+		return &NameStatus{}, nil
+	} else if len(fields)%2 != 0 {
+		return nil, fmt.Errorf("unexpected number of fields: %d", len(fields))
+	}
+
+	status := &NameStatus{}
+	for i := 0; i < len(fields); i += 2 {
+		path := fields[i+1]
+		switch fields[i] {
+		case "A":
+			status.Added = append(status.Added, path)
+		case "D":
+			status.Removed = append(status.Removed, path)
+		case "M", "T":
+			status.Modified = append(status.Modified, path)
+		default:
+			return nil, fmt.Errorf("unexpected status %q of %q", fields[i], path)
+		}
+	}
+	return status, nil
+}
+
+// ChangedFiles returns the files changed from the old revision to the new
+// revision, e.g. the old and new values of a reference update that are given
+// to a pre-receive hook. Renames are reported as a removal and an addition.
+// An old revision of EmptyID (i.e. the reference is created) is compared as
+// the empty tree so that all files are added, and a new revision of EmptyID
+// (i.e. the reference is deleted) is compared as the empty tree so that all
+// files are removed.
+func (r *Repository) ChangedFiles(oldRev, newRev string, opts ...ChangedFilesOptions) (*NameStatus, error) {
+	var opt ChangedFilesOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	if oldRev == EmptyID {
+		oldRev = EmptyTreeID
+	}
+	if newRev == EmptyID {
+		newRev = EmptyTreeID
+	}
+
+	stdout, err := NewCommand("diff-tree", "-r", "--name-status", "-z", "--no-renames").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--end-of-options", oldRev, newRev).
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		if strings.Contains(err.Error(), "bad object") ||
+			strings.Contains(err.Error(), "unknown revision") {
+			return nil, ErrRevisionNotExist
+		}
+		return nil, err
+	}
+	return parseChangedFiles(stdout)
+}
+
+// DirStat is the aggregated diff stats of files in a directory.
+type DirStat struct {
+	// The number of changed files.
+	FilesChanged int
+	// The number of added lines, binary files are not counted.
+	Additions int
+	// The number of deleted lines, binary files are not counted.
+	Deletions int
+}
+
+// DiffStatByDirOptions contains optional arguments for aggregating diff stats
+// by directory.
+//
+// Docs: https://git-scm.com/docs/git-diff#Documentation/git-diff.txt---numstat
+type DiffStatByDirOptions struct {
+	// The number of leading path components to aggregate by, e.g. "cmd/gogs" for
+	// "cmd/gogs/main.go" when the depth is 2. It defaults to 1 (i.e. top-level
+	// directories) when not positive.
+	Depth int
+	// Indicates whether to compare head with the merge base of two revisions.
+	NeedsMergeBase bool
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// dirPrefix returns the first depth components of the directory of the path, or
+// "." if the path is at the root.
+func dirPrefix(path string, depth int) string {
+	i := strings.LastIndex(path, "/")
+	if i < 0 {
+		return "."
+	}
+
+	dir := path[:i]
+	end := 0
+	for n := 0; n < depth; n++ {
+		j := strings.Index(dir[end:], "/")
+		if j < 0 {
+			return dir
+		}
+		end += j + 1
+	}
+	return dir[:end-1]
+}
+
+// parseDiffStatByDir parses the output of "git diff --numstat -z" and aggregates
+// the stats by directory. Each entry is in the form of "<added> TAB <deleted>
+// TAB <path> NUL", or "<added> TAB <deleted> TAB NUL <old path> NUL <new path>
+// NUL" for a rename, where the numbers are "-" for binary files.
+func parseDiffStatByDir(data []byte, depth int) (map[string]*DirStat, error) {
+	stats := make(map[string]*DirStat)
+	fields := bytes.Split(data, []byte{0})
+	for i := 0; i < len(fields); i++ {
+		if len(fields[i]) == 0 {
+			continue
+		}
+
+		parts := bytes.SplitN(fields[i], []byte{'\t'}, 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("malformed numstat: %q", fields[i])
+		}
+
+		path := string(parts[2])
+		if path == "" {
+			// The path of a rename is in the next two fields, the new one counts.
+			if i+2 >= len(fields) || len(fields[i+2]) == 0 {
+				return nil, fmt.Errorf("missing paths of rename: %q", fields[i])
+			}
+			path = string(fields[i+2])
+			i += 2
+		}
+
+		dir := dirPrefix(path, depth)
+		stat, ok := stats[dir]
+		if !ok {
+			stat = new(DirStat)
+			stats[dir] = stat
+		}
+		stat.FilesChanged++
+
+		if string(parts[0]) == "-" {
+			continue
+		}
+		additions, err := strconv.Atoi(string(parts[0]))
+		if err != nil {
+			return nil, fmt.Errorf("parse additions %q: %v", parts[0], err)
+		}
+		deletions, err := strconv.Atoi(string(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("parse deletions %q: %v", parts[1], err)
+		}
+		stat.Additions += additions
+		stat.Deletions += deletions
+	}
+	return stats, nil
+}
+
+// DiffStatByDir returns the diff stats between base and head revisions
+// aggregated by directory, where files at the root are aggregated as ".". It
+// returns an empty map if there is no change.
+func (r *Repository) DiffStatByDir(base, head string, opts ...DiffStatByDirOptions) (map[string]*DirStat, error) {
+	var opt DiffStatByDirOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Depth <= 0 {
+		opt.Depth = 1
+	}
+
+	cmd := NewCommand("diff").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--numstat", "-z")
+	if opt.NeedsMergeBase {
+		cmd.AddArgs(base + "..." + head)
+	} else {
+		cmd.AddArgs(base, head)
+	}
+
+	stdout, err := cmd.AddArgs("--").RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		return nil, err
+	}
+	return parseDiffStatByDir(stdout, opt.Depth)
+}
@@ -7,6 +7,11 @@ package git
 import (
 	"bytes"
 	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -130,6 +135,10 @@ func TestRepository_Diff(t *testing.T) {
 										RightLine: 11,
 									},
 								},
+								OldStart:     0,
+								OldLines:     0,
+								NewStart:     1,
+								NewLines:     11,
 								numAdditions: 11,
 							},
 						},
@@ -179,6 +188,10 @@ func TestRepository_Diff(t *testing.T) {
 										RightLine: 4,
 									},
 								},
+								OldStart:     0,
+								OldLines:     0,
+								NewStart:     1,
+								NewLines:     4,
 								numAdditions: 4,
 							},
 						},
@@ -240,6 +253,10 @@ func TestRepository_Diff(t *testing.T) {
 										RightLine: 6,
 									},
 								},
+								OldStart:     0,
+								OldLines:     0,
+								NewStart:     1,
+								NewLines:     6,
 								numAdditions: 6,
 							},
 						},
@@ -299,6 +316,418 @@ func TestRepository_Diff(t *testing.T) {
 	}
 }
 
+func TestRepository_Diff_context(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	var lines []string
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	err = commitFile(r, "context.txt", strings.Join(lines, "\n")+"\n", "Add context.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Change line 2, add a line after line 10 and delete line 15.
+	changed := append([]string{}, lines[:1]...)
+	changed = append(changed, "line 2 changed")
+	changed = append(changed, lines[2:10]...)
+	changed = append(changed, "line 10.5")
+	changed = append(changed, lines[10:14]...)
+	changed = append(changed, lines[15:]...)
+	err = commitFile(r, "context.txt", strings.Join(changed, "\n")+"\n", "Change context.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sectionRanges := func(diff *Diff) [][4]int {
+		var ranges [][4]int
+		for _, s := range diff.Files[0].Sections {
+			ranges = append(ranges, [4]int{s.OldStart, s.OldLines, s.NewStart, s.NewLines})
+		}
+		return ranges
+	}
+
+	tests := []struct {
+		name      string
+		opt       DiffOptions
+		expRanges [][4]int
+	}{
+		{
+			name:      "default",
+			expRanges: [][4]int{{1, 5, 1, 5}, {8, 11, 8, 11}},
+		},
+		{
+			name:      "zero context",
+			opt:       DiffOptions{ZeroContext: true},
+			expRanges: [][4]int{{2, 1, 2, 1}, {10, 0, 11, 1}, {15, 1, 15, 0}},
+		},
+		{
+			name:      "one line of context",
+			opt:       DiffOptions{ContextLines: 1},
+			expRanges: [][4]int{{1, 3, 1, 3}, {10, 2, 10, 3}, {14, 3, 15, 2}},
+		},
+		{
+			name:      "inter-hunk context",
+			opt:       DiffOptions{ContextLines: 1, InterHunkContext: 3},
+			expRanges: [][4]int{{1, 3, 1, 3}, {10, 7, 10, 7}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diff, err := r.Diff("HEAD", 0, 0, 0, test.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !assert.Len(t, diff.Files, 1) {
+				return
+			}
+			assert.Equal(t, test.expRanges, sectionRanges(diff))
+		})
+	}
+
+	// The deleted line 15 is right before the new line 15
+	diff, err := r.Diff("HEAD", 0, 0, 0, DiffOptions{ZeroContext: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deleted := diff.Files[0].Sections[2].Lines[1]
+	assert.Equal(t, DiffLineDelete, deleted.Type)
+	assert.Equal(t, "-line 15", deleted.Content)
+	assert.Equal(t, 15, deleted.LeftLine)
+	assert.Equal(t, 0, deleted.RightLine)
+}
+
+func TestRepository_Diff_whitespace(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	err = commitFile(r, "whitespace.txt", "func main() {\n\treturn\n}\n", "Add whitespace.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = commitFile(r, "whitespace.txt", "func main()  {\n\treturn \n\n}\n", "Change whitespace.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changedLines := func(diff *Diff) []string {
+		var lines []string
+		for _, f := range diff.Files {
+			for _, s := range f.Sections {
+				for _, l := range s.Lines {
+					if l.Type == DiffLineAdd || l.Type == DiffLineDelete {
+						lines = append(lines, l.Content)
+					}
+				}
+			}
+		}
+		return lines
+	}
+
+	tests := []struct {
+		name     string
+		opt      DiffOptions
+		expLines []string
+	}{
+		{
+			name:     "default",
+			expLines: []string{"-func main() {", "-\treturn", "+func main()  {", "+\treturn ", "+"},
+		},
+		{
+			name:     "ignore space at end of line",
+			opt:      DiffOptions{IgnoreSpaceAtEOL: true},
+			expLines: []string{"-func main() {", "+func main()  {", "+"},
+		},
+		{
+			name:     "ignore space change",
+			opt:      DiffOptions{IgnoreSpaceChange: true},
+			expLines: []string{"+"},
+		},
+		{
+			name: "ignore all space and blank lines",
+			opt:  DiffOptions{IgnoreAllSpace: true, IgnoreBlankLines: true},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diff, err := r.Diff("HEAD", 0, 0, 0, test.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, test.expLines, changedLines(diff))
+		})
+	}
+}
+
+func TestRepository_Diff_rootCommit(t *testing.T) {
+	path := tempPath()
+	defer func() {
+		_ = os.RemoveAll(path)
+	}()
+
+	err := Init(path, InitOptions{Bare: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := r.CommitFiles(CommitFilesOptions{
+		Files: []CommitFile{
+			{Path: "README.txt", Content: []byte("1\n2\n")},
+			{Path: "dir/main.go", Content: []byte("package main\n")},
+		},
+		Committer: &Signature{Name: "alice", Email: "alice@example.com"},
+		Message:   "Initial commit",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := r.Diff(root, 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, diff.NumFiles())
+	assert.Equal(t, 3, diff.TotalAdditions())
+	for _, f := range diff.Files {
+		assert.True(t, f.IsCreated(), f.Name)
+	}
+	assert.Equal(t, "README.txt", diff.Files[0].Name)
+	assert.Equal(t, "dir/main.go", diff.Files[1].Name)
+}
+
+func TestRepository_StagedDiff(t *testing.T) {
+	t.Run("nothing staged", func(t *testing.T) {
+		r, cleanup, err := setupTempRepo()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cleanup()
+
+		diff, err := r.StagedDiff(0, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, 0, diff.NumFiles())
+	})
+
+	t.Run("staged changes", func(t *testing.T) {
+		r, cleanup, err := setupTempRepo()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cleanup()
+
+		err = ioutil.WriteFile(filepath.Join(r.Path(), "STAGED"), []byte("line 1\nline 2\n"), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = r.Add(AddOptions{Pathspecs: []string{"STAGED"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Unstaged changes should not be included
+		err = ioutil.WriteFile(filepath.Join(r.Path(), "UNSTAGED"), []byte("something"), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		diff, err := r.StagedDiff(0, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, 1, diff.NumFiles())
+		assert.Equal(t, "STAGED", diff.Files[0].Name)
+		assert.True(t, diff.Files[0].IsCreated())
+		assert.Equal(t, 2, diff.TotalAdditions())
+	})
+
+	t.Run("no commit yet", func(t *testing.T) {
+		path := tempPath()
+		defer func() {
+			_ = os.RemoveAll(path)
+		}()
+
+		err := Init(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = ioutil.WriteFile(filepath.Join(path, "README"), []byte("hello\n"), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = r.Add(AddOptions{All: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		diff, err := r.StagedDiff(0, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, 1, diff.NumFiles())
+		assert.Equal(t, "README", diff.Files[0].Name)
+		assert.True(t, diff.Files[0].IsCreated())
+	})
+}
+
+func Test_parseChangedFiles(t *testing.T) {
+	status, err := parseChangedFiles([]byte("A\x00a.txt\x00D\x00dir/b.txt\x00M\x00c d.txt\x00T\x00link\x00"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, &NameStatus{
+		Added:    []string{"a.txt"},
+		Removed:  []string{"dir/b.txt"},
+		Modified: []string{"c d.txt", "link"},
+	}, status)
+
+	status, err = parseChangedFiles(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, &NameStatus{}, status)
+
+	for _, data := range []string{
+		"A\x00",
+		"X\x00a.txt\x00",
+	} {
+		_, err = parseChangedFiles([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestRepository_ChangedFiles(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	oldRev, err := r.RevParse("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = commitFile(r, "added.txt", "added", "Add added.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.Move("run.sh", "moved.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = commitFile(r, "README.txt", "modified", "Modify README.txt and move run.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newRev, err := r.RevParse("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := r.ChangedFiles(oldRev, newRev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, &NameStatus{
+		Added:    []string{"added.txt", "moved.sh"},
+		Removed:  []string{"run.sh"},
+		Modified: []string{"README.txt"},
+	}, status)
+
+	stdout, err := NewCommand("ls-tree", "-r", "--name-only", newRev).RunInDir(r.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := bytesToStrings(stdout)
+
+	t.Run("created", func(t *testing.T) {
+		status, err := r.ChangedFiles(EmptyID, newRev)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.ElementsMatch(t, paths, status.Added)
+		assert.Empty(t, status.Removed)
+		assert.Empty(t, status.Modified)
+	})
+
+	t.Run("deleted", func(t *testing.T) {
+		status, err := r.ChangedFiles(newRev, EmptyID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Empty(t, status.Added)
+		assert.ElementsMatch(t, paths, status.Removed)
+		assert.Empty(t, status.Modified)
+	})
+
+	t.Run("revision does not exist", func(t *testing.T) {
+		_, err := r.ChangedFiles("404", newRev)
+		assert.Equal(t, ErrRevisionNotExist, err)
+
+		_, err = r.ChangedFiles(oldRev, "1111111111111111111111111111111111111111")
+		assert.Equal(t, ErrRevisionNotExist, err)
+	})
+}
+
+func Test_colorArgs(t *testing.T) {
+	assert.Nil(t, colorArgs("", new(bytes.Buffer)))
+	assert.Equal(t, []string{"--color=always"}, colorArgs("always", new(bytes.Buffer)))
+	assert.Equal(t, []string{"--color=never"}, colorArgs("never", new(bytes.Buffer)))
+
+	// The "auto" mode never colors output that is not a terminal.
+	assert.Equal(t, []string{"--color=never"}, colorArgs("auto", new(bytes.Buffer)))
+}
+
+func TestRepository_RawDiff_color(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	err = commitFile(r, "color.txt", "color\n", "Add color.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, diffType := range []RawDiffFormat{RawDiffNormal, RawDiffPatch} {
+		t.Run(string(diffType), func(t *testing.T) {
+			for color, colored := range map[string]bool{
+				"":       false,
+				"always": true,
+				"never":  false,
+				"auto":   false,
+			} {
+				buf := new(bytes.Buffer)
+				err := r.RawDiff("master", diffType, buf, RawDiffOptions{Color: color})
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.Contains(t, buf.String(), "color.txt", color)
+				assert.Equal(t, colored, strings.Contains(buf.String(), "\x1b["), color)
+			}
+		})
+	}
+}
+
 func TestRepository_RawDiff(t *testing.T) {
 	t.Run("invalid revision", func(t *testing.T) {
 		err := testrepo.RawDiff("bad_revision", "bad_diff_type", nil)
@@ -518,3 +947,212 @@ index 0000000000000000000000000000000000000000..6b08f76a5313fa3d26859515b30aa17a
 		})
 	}
 }
+
+func TestRepository_FilePatch(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	base, err := r.RevParse("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := []string{"a.txt"}
+	// Colon is not allowed in file names on Windows
+	if runtime.GOOS != "windows" {
+		files = append(files, ":colon.txt")
+	}
+	for _, file := range files {
+		err = commitFile(r, file, "content of "+file+"\n", "Add "+file)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		path        string
+		expContains string
+		expExcludes string
+	}{
+		{
+			path:        "a.txt",
+			expContains: "+content of a.txt",
+			expExcludes: "colon.txt",
+		},
+		{
+			path:        ":colon.txt",
+			expContains: "+content of :colon.txt",
+			expExcludes: "a.txt",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			if runtime.GOOS == "windows" && strings.Contains(test.path, ":") {
+				t.Skip("colon is not allowed in file names on Windows")
+			}
+
+			p, err := r.FilePatch(base, "HEAD", test.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Contains(t, string(p), test.expContains)
+			assert.NotContains(t, string(p), test.expExcludes)
+		})
+	}
+
+	t.Run("file not changed", func(t *testing.T) {
+		p, err := r.FilePatch("HEAD", "HEAD", "a.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Empty(t, p)
+	})
+}
+
+func Test_dirPrefix(t *testing.T) {
+	tests := []struct {
+		path   string
+		depth  int
+		expDir string
+	}{
+		{path: "README.md", depth: 1, expDir: "."},
+		{path: "cmd/main.go", depth: 1, expDir: "cmd"},
+		{path: "cmd/gogs/main.go", depth: 1, expDir: "cmd"},
+		{path: "cmd/gogs/main.go", depth: 2, expDir: "cmd/gogs"},
+		{path: "cmd/gogs/main.go", depth: 3, expDir: "cmd/gogs"},
+		{path: "a/b/c/d.go", depth: 2, expDir: "a/b"},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			assert.Equal(t, test.expDir, dirPrefix(test.path, test.depth))
+		})
+	}
+}
+
+func Test_parseDiffStatByDir(t *testing.T) {
+	data := "1\t2\tREADME.md\x00" +
+		"3\t0\tcmd/gogs/main.go\x00" +
+		"4\t1\tcmd/web.go\x00" +
+		"-\t-\tpublic/img/logo.png\x00" +
+		"0\t0\t\x00old/name.go\x00internal/name.go\x00"
+
+	stats, err := parseDiffStatByDir([]byte(data), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t,
+		map[string]*DirStat{
+			".":        {FilesChanged: 1, Additions: 1, Deletions: 2},
+			"cmd":      {FilesChanged: 2, Additions: 7, Deletions: 1},
+			"public":   {FilesChanged: 1},
+			"internal": {FilesChanged: 1},
+		},
+		stats,
+	)
+
+	for _, data := range []string{
+		"1\t2\x00",
+		"x\t2\tREADME.md\x00",
+		"1\tx\tREADME.md\x00",
+		"1\t2\t\x00old.go\x00",
+	} {
+		_, err = parseDiffStatByDir([]byte(data), 1)
+		assert.Error(t, err, data)
+	}
+}
+
+func TestRepository_DiffStatByDir(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	base, err := r.RevParse("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{"stat.txt", "api/v1/api.go", "api/v2/api.go", "client/client.go"} {
+		err = commitFile(r, file, "1\n2\n", "Add "+file)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats, err := r.DiffStatByDir(base, "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t,
+		map[string]*DirStat{
+			".":      {FilesChanged: 1, Additions: 2},
+			"api":    {FilesChanged: 2, Additions: 4},
+			"client": {FilesChanged: 1, Additions: 2},
+		},
+		stats,
+	)
+
+	stats, err = r.DiffStatByDir(base, "master", DiffStatByDirOptions{Depth: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t,
+		map[string]*DirStat{
+			".":      {FilesChanged: 1, Additions: 2},
+			"api/v1": {FilesChanged: 1, Additions: 2},
+			"api/v2": {FilesChanged: 1, Additions: 2},
+			"client": {FilesChanged: 1, Additions: 2},
+		},
+		stats,
+	)
+
+	stats, err = r.DiffStatByDir("master", "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, stats)
+}
+
+func TestRepository_MergeDiff(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	err = setupDivergedBranches(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := new(bytes.Buffer)
+	err = r.MergeDiff("master", "feature", buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Changes made on the base after the branch point are not included.
+	assert.Contains(t, buf.String(), "diff --git a/feature1.txt b/feature1.txt")
+	assert.Contains(t, buf.String(), "diff --git a/feature2.txt b/feature2.txt")
+	assert.NotContains(t, buf.String(), "master.txt")
+
+	t.Run("no merge base", func(t *testing.T) {
+		_, err := NewCommand("checkout", "--orphan", "orphan").RunInDir(r.Path())
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = commitFile(r, "orphan.txt", "orphan", "Add orphan.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = r.MergeDiff("master", "orphan", ioutil.Discard)
+		assert.Equal(t, ErrNoMergeBase, err)
+	})
+
+	t.Run("revision does not exist", func(t *testing.T) {
+		err := r.MergeDiff("master", "404", ioutil.Discard)
+		assert.Equal(t, ErrRevisionNotExist, err)
+	})
+}
@@ -19,12 +19,18 @@ type GrepOptions struct {
 	Tree string
 	// Limits the search to files in the specified pathspec.
 	Pathspec string
+	// Limits the search to files in any of the pathspecs, in addition to
+	// Pathspec.
+	Pathspecs []string
 	// Whether to do case insensitive search.
 	IgnoreCase bool
 	// Whether to match the pattern only at word boundaries.
 	WordRegexp bool
 	// Whether use extended regular expressions.
 	ExtendedRegexp bool
+	// Whether to match the pattern as a fixed string rather than a regular
+	// expression.
+	FixedString bool
 	// The timeout duration before giving up for each shell command execution. The
 	// default timeout duration will be used when not supplied.
 	//
@@ -48,33 +54,36 @@ type GrepResult struct {
 	Text string
 }
 
-func parseGrepLine(line string) (*GrepResult, error) {
-	r := &GrepResult{}
-	sp := strings.SplitN(line, ":", 5)
-	var n int
-	switch len(sp) {
-	case 4:
-		// HEAD
-		r.Tree = "HEAD"
-	case 5:
-		// Tree included
-		r.Tree = sp[0]
-		n++
-	default:
+// parseGrepLine parses a line of the output of "git grep -z" on the tree, which
+// is in the form of "<tree>:<path>\0<line>\0<column>\0<text>". The path is
+// separated by a NUL so that it may contain any character.
+func parseGrepLine(tree, line string) (*GrepResult, error) {
+	sp := strings.SplitN(line, "\x00", 4)
+	if len(sp) != 4 || !strings.HasPrefix(sp[0], tree+":") {
 		return nil, fmt.Errorf("invalid grep line: %s", line)
 	}
-	r.Path = sp[n]
-	n++
-	r.Line, _ = strconv.Atoi(sp[n])
-	n++
-	r.Column, _ = strconv.Atoi(sp[n])
-	n++
-	r.Text = sp[n]
+
+	r := &GrepResult{
+		Tree: tree,
+		Path: strings.TrimPrefix(sp[0], tree+":"),
+		Text: sp[3],
+	}
+	r.Line, _ = strconv.Atoi(sp[1])
+	r.Column, _ = strconv.Atoi(sp[2])
 	return r, nil
 }
 
-// Grep returns the results of a grep search in the repository.
+// Grep returns the results of a grep search in the repository. It returns nil
+// when there is no match or the search fails, use GrepWithError to tell them
+// apart.
 func (r *Repository) Grep(pattern string, opts ...GrepOptions) []*GrepResult {
+	results, _ := r.GrepWithError(pattern, opts...)
+	return results
+}
+
+// GrepWithError returns the results of a grep search in the repository, and
+// returns nil without an error when there is no match.
+func (r *Repository) GrepWithError(pattern string, opts ...GrepOptions) ([]*GrepResult, error) {
 	var opt GrepOptions
 	if len(opts) > 0 {
 		opt = opts[0]
@@ -86,7 +95,7 @@ func (r *Repository) Grep(pattern string, opts ...GrepOptions) []*GrepResult {
 	cmd := NewCommand("grep").
 		AddOptions(opt.CommandOptions).
 		// Display full-name, line number and column number
-		AddArgs("--full-name", "--line-number", "--column")
+		AddArgs("-z", "--full-name", "--line-number", "--column")
 	if opt.IgnoreCase {
 		cmd.AddArgs("--ignore-case")
 	}
@@ -96,14 +105,28 @@ func (r *Repository) Grep(pattern string, opts ...GrepOptions) []*GrepResult {
 	if opt.ExtendedRegexp {
 		cmd.AddArgs("--extended-regexp")
 	}
-	cmd.AddArgs(pattern, opt.Tree)
+	if opt.FixedString {
+		cmd.AddArgs("--fixed-strings")
+	}
+	// The pattern is passed with "-e" in case it starts with a dash.
+	cmd.AddArgs("-e", pattern, opt.Tree)
+	var pathspecs []string
 	if opt.Pathspec != "" {
-		cmd.AddArgs("--", opt.Pathspec)
+		pathspecs = append(pathspecs, opt.Pathspec)
+	}
+	pathspecs = append(pathspecs, opt.Pathspecs...)
+	if len(pathspecs) > 0 {
+		cmd.AddArgs("--")
+		cmd.AddArgs(pathspecs...)
 	}
 
 	stdout, err := cmd.RunInDirWithTimeout(opt.Timeout, r.path)
 	if err != nil {
-		return nil
+		// Git exits with 1 when there is no match.
+		if isExitCode(err, 1) {
+			return nil, nil
+		}
+		return nil, err
 	}
 
 	var results []*GrepResult
@@ -113,10 +136,10 @@ func (r *Repository) Grep(pattern string, opts ...GrepOptions) []*GrepResult {
 		if len(line) == 0 {
 			continue
 		}
-		r, err := parseGrepLine(line)
+		r, err := parseGrepLine(opt.Tree, line)
 		if err == nil {
 			results = append(results, r)
 		}
 	}
-	return results
+	return results, nil
 }
@@ -142,3 +142,88 @@ func TestRepository_Grep_WordRegexp(t *testing.T) {
 	got := testrepo.Grep("world", GrepOptions{WordRegexp: true})
 	assert.Equal(t, want, got)
 }
+
+func TestRepository_Grep_options(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	err = commitFile(r, "dir/with space:colon.txt", "a.b\n-flag\n", "Add file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = commitFile(r, "other/a.txt", "axb\n", "Add other file")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		pattern    string
+		opt        GrepOptions
+		expResults []*GrepResult
+	}{
+		{
+			name:    "fixed string",
+			pattern: "a.b",
+			opt:     GrepOptions{FixedString: true},
+			expResults: []*GrepResult{
+				{Tree: "HEAD", Path: "dir/with space:colon.txt", Line: 1, Column: 1, Text: "a.b"},
+			},
+		},
+		{
+			name:    "pathspecs",
+			pattern: "a.b",
+			opt:     GrepOptions{Pathspecs: []string{"dir", "other"}},
+			expResults: []*GrepResult{
+				{Tree: "HEAD", Path: "dir/with space:colon.txt", Line: 1, Column: 1, Text: "a.b"},
+				{Tree: "HEAD", Path: "other/a.txt", Line: 1, Column: 1, Text: "axb"},
+			},
+		},
+		{
+			name:    "pattern starts with a dash",
+			pattern: "-flag",
+			opt:     GrepOptions{Tree: "master"},
+			expResults: []*GrepResult{
+				{Tree: "master", Path: "dir/with space:colon.txt", Line: 2, Column: 1, Text: "-flag"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expResults, r.Grep(test.pattern, test.opt))
+		})
+	}
+}
+
+func TestRepository_GrepWithError(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	err = commitFile(r, "grep.txt", "hello\n", "Add grep.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := r.GrepWithError("hello", GrepOptions{Pathspec: "grep.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []*GrepResult{
+		{Tree: "HEAD", Path: "grep.txt", Line: 1, Column: 1, Text: "hello"},
+	}, results)
+
+	// No match is not an error.
+	results, err = r.GrepWithError("404 does not exist")
+	assert.Nil(t, err)
+	assert.Nil(t, results)
+
+	results, err = r.GrepWithError("hello", GrepOptions{Tree: "404"})
+	assert.Error(t, err)
+	assert.Nil(t, results)
+}
@@ -8,6 +8,8 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 )
 
 // DefaultHooksDir is the default directory for Git hooks.
@@ -74,3 +76,168 @@ func (r *Repository) Hooks(dir string) ([]*Hook, error) {
 	}
 	return hooks, nil
 }
+
+// hooksDir returns the absolute path of the directory where Git looks for hooks
+// of the repository. Unlike joining DefaultHooksDir to the repository path, it
+// resolves the actual Git directory of non-bare repositories and linked
+// worktrees, and respects the "core.hooksPath" configuration.
+func (r *Repository) hooksDir() (string, error) {
+	stdout, err := NewCommand("rev-parse", "--git-path", DefaultHooksDir).RunInDir(r.path)
+	if err != nil {
+		return "", err
+	}
+
+	dir := strings.TrimSpace(string(stdout))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(r.path, dir)
+	}
+	return dir, nil
+}
+
+// GetHook returns the content of the hook with given name that is installed in
+// the repository. It returns an ErrInvalidHookName if the name is not a hook
+// recognized by Git, or an os.ErrNotExist if the hook is not installed.
+func (r *Repository) GetHook(name HookName) ([]byte, error) {
+	if !IsKnownHook(name) {
+		return nil, ErrInvalidHookName
+	}
+
+	dir, err := r.hooksDir()
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := ioutil.ReadFile(filepath.Join(dir, string(name)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, os.ErrNotExist
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+// SetHook installs the hook with given name and content to the repository,
+// overwriting the existing one. The file mode defaults to 0755 when not
+// supplied, and it is applied to the existing file as well. It returns an
+// ErrInvalidHookName if the name is not a hook recognized by Git.
+func (r *Repository) SetHook(name HookName, content []byte, mode os.FileMode) error {
+	if !IsKnownHook(name) {
+		return ErrInvalidHookName
+	}
+
+	if mode == 0 {
+		mode = 0755
+	}
+
+	dir, err := r.hooksDir()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	fpath := filepath.Join(dir, string(name))
+	err = ioutil.WriteFile(fpath, content, mode)
+	if err != nil {
+		return err
+	}
+
+	// The mode is only used by ioutil.WriteFile when creating a new file.
+	return os.Chmod(fpath, mode)
+}
+
+// ListHooks returns all hooks recognized by Git with their installation status
+// in the repository, sorted by name. The content is only read for installed
+// hooks.
+func (r *Repository) ListHooks() ([]*Hook, error) {
+	dir, err := r.hooksDir()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(knownHooks))
+	for name := range knownHooks {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+
+	hooks := make([]*Hook, 0, len(names))
+	for _, name := range names {
+		h := &Hook{
+			name: HookName(name),
+			path: filepath.Join(dir, name),
+		}
+
+		fi, err := os.Stat(h.path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				return nil, err
+			}
+			hooks = append(hooks, h)
+			continue
+		} else if fi.IsDir() {
+			hooks = append(hooks, h)
+			continue
+		}
+
+		p, err := ioutil.ReadFile(h.path)
+		if err != nil {
+			return nil, err
+		}
+		h.content = string(p)
+		h.isInstalled = true
+		h.isExecutable = fi.Mode().Perm()&0111 != 0
+		hooks = append(hooks, h)
+	}
+	return hooks, nil
+}
+
+// setHookExecutable adds or removes the executable bits of the installed hook
+// with given name.
+func (r *Repository) setHookExecutable(name HookName, executable bool) error {
+	if !IsKnownHook(name) {
+		return ErrInvalidHookName
+	}
+
+	dir, err := r.hooksDir()
+	if err != nil {
+		return err
+	}
+
+	fpath := filepath.Join(dir, string(name))
+	fi, err := os.Stat(fpath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return os.ErrNotExist
+		}
+		return err
+	}
+
+	mode := fi.Mode().Perm()
+	if executable {
+		mode |= 0111
+	} else {
+		mode &^= 0111
+	}
+	return os.Chmod(fpath, mode)
+}
+
+// DisableHook removes the executable bits of the installed hook with given name
+// so that Git skips it without deleting its content. It returns an
+// os.ErrNotExist if the hook is not installed.
+//
+// NOTE: Git for Windows does not honor the executable bit, thus disabled hooks
+// are still run on Windows.
+func (r *Repository) DisableHook(name HookName) error {
+	return r.setHookExecutable(name, false)
+}
+
+// EnableHook adds the executable bits to the installed hook with given name. It
+// returns an os.ErrNotExist if the hook is not installed.
+func (r *Repository) EnableHook(name HookName) error {
+	return r.setHookExecutable(name, true)
+}
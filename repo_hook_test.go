@@ -6,6 +6,8 @@ package git
 
 import (
 	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -39,3 +41,133 @@ func TestRepository_Hooks(t *testing.T) {
 		assert.NotEmpty(t, hooks[i].Content())
 	}
 }
+
+func TestRepository_SetHook(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	t.Run("invalid hook", func(t *testing.T) {
+		err := r.SetHook("bad_hook", []byte("echo"), 0)
+		assert.Equal(t, ErrInvalidHookName, err)
+
+		_, err = r.GetHook("bad_hook")
+		assert.Equal(t, ErrInvalidHookName, err)
+	})
+
+	t.Run("hook not installed", func(t *testing.T) {
+		_, err := r.GetHook(HookPostReceive)
+		assert.Equal(t, os.ErrNotExist, err)
+	})
+
+	err = r.SetHook(HookPreReceive, []byte("#!/bin/sh\necho $1"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Hooks of a non-bare repository live in the ".git" directory
+	fpath := filepath.Join(r.Path(), ".git", DefaultHooksDir, string(HookPreReceive))
+	fi, err := os.Stat(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" {
+		assert.Equal(t, os.FileMode(0755), fi.Mode().Perm())
+	}
+
+	p, err := r.GetHook(HookPreReceive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "#!/bin/sh\necho $1", string(p))
+
+	// Overwrite with a different mode
+	err = r.SetHook(HookPreReceive, []byte("#!/bin/sh"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fi, err = os.Stat(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" {
+		assert.Equal(t, os.FileMode(0644), fi.Mode().Perm())
+	}
+}
+
+func TestRepository_ListHooks(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	err = r.SetHook(HookPostReceive, []byte("#!/bin/sh"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	findHook := func(hooks []*Hook, name HookName) *Hook {
+		for _, h := range hooks {
+			if h.Name() == name {
+				return h
+			}
+		}
+		return nil
+	}
+
+	hooks, err := r.ListHooks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(knownHooks), len(hooks))
+
+	h := findHook(hooks, HookPreReceive)
+	assert.NotNil(t, h)
+	assert.False(t, h.IsInstalled())
+	assert.False(t, h.IsExecutable())
+
+	h = findHook(hooks, HookPostReceive)
+	assert.NotNil(t, h)
+	assert.True(t, h.IsInstalled())
+	assert.Equal(t, "#!/bin/sh", h.Content())
+	if runtime.GOOS == "windows" {
+		return
+	}
+	assert.True(t, h.IsExecutable())
+
+	t.Run("disable and enable", func(t *testing.T) {
+		err := r.DisableHook(HookPostReceive)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		hooks, err := r.ListHooks()
+		if err != nil {
+			t.Fatal(err)
+		}
+		h := findHook(hooks, HookPostReceive)
+		assert.True(t, h.IsInstalled())
+		assert.False(t, h.IsExecutable())
+
+		err = r.EnableHook(HookPostReceive)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		hooks, err = r.ListHooks()
+		if err != nil {
+			t.Fatal(err)
+		}
+		h = findHook(hooks, HookPostReceive)
+		assert.True(t, h.IsExecutable())
+	})
+
+	t.Run("hook not installed", func(t *testing.T) {
+		assert.Equal(t, os.ErrNotExist, r.DisableHook(HookPreReceive))
+		assert.Equal(t, os.ErrNotExist, r.EnableHook(HookPreReceive))
+	})
+}
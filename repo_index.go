@@ -0,0 +1,231 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Conflict is a conflicted path in the index, with the blob of each side that
+// took part in the conflict. A side is nil when the path does not exist on it,
+// e.g. Base is nil when both sides added the same path.
+type Conflict struct {
+	// The path relative to the root of the working tree.
+	Path string
+	// The blob of the common ancestor, i.e. stage 1.
+	Base *SHA1
+	// The blob of the current branch, i.e. stage 2.
+	Ours *SHA1
+	// The blob of the branch being merged, i.e. stage 3.
+	Theirs *SHA1
+}
+
+// ConflictedFilesOptions contains optional arguments for listing conflicted
+// files.
+//
+// Docs: https://git-scm.com/docs/git-ls-files#Documentation/git-ls-files.txt--u
+type ConflictedFilesOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// IndexEntry is an entry in the index.
+type IndexEntry struct {
+	// The mode of the entry.
+	Mode EntryMode
+	// The ID of the blob (or the commit for a submodule).
+	ID *SHA1
+	// The stage number, which is 0 for a normal entry, and 1 (common ancestor), 2
+	// (ours) or 3 (theirs) for a conflicted entry.
+	Stage int
+	// The path relative to the root of the working tree.
+	Path string
+}
+
+// parseIndexEntries parses the output of "git ls-files --stage -z", where each
+// entry is in the form of "<mode> SP <object> SP <stage> TAB <path> NUL".
+func parseIndexEntries(data []byte) ([]*IndexEntry, error) {
+	var entries []*IndexEntry
+	for _, entry := range bytes.Split(data, []byte{0}) {
+		if len(entry) == 0 {
+			continue
+		}
+
+		tab := bytes.IndexByte(entry, '\t')
+		if tab < 0 {
+			return nil, fmt.Errorf("malformed entry: %q", entry)
+		}
+		fields := bytes.Fields(entry[:tab])
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("malformed entry: %q", entry)
+		}
+
+		mode, err := strconv.ParseInt(string(fields[0]), 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("parse mode %q: %v", fields[0], err)
+		}
+		id, err := NewIDFromString(string(fields[1]))
+		if err != nil {
+			return nil, fmt.Errorf("parse object ID %q: %v", fields[1], err)
+		}
+		stage, err := strconv.Atoi(string(fields[2]))
+		if err != nil || stage < 0 || stage > 3 {
+			return nil, fmt.Errorf("unexpected stage %q: %q", fields[2], entry)
+		}
+
+		entries = append(entries, &IndexEntry{
+			Mode:  EntryMode(mode),
+			ID:    id,
+			Stage: stage,
+			Path:  string(entry[tab+1:]),
+		})
+	}
+	return entries, nil
+}
+
+// IndexEntriesOptions contains optional arguments for listing index entries.
+//
+// Docs: https://git-scm.com/docs/git-ls-files#Documentation/git-ls-files.txt--s
+type IndexEntriesOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// IndexEntries returns all entries in the index, including every stage of
+// conflicted paths. The returned list is sorted by path and then stage.
+func (r *Repository) IndexEntries(opts ...IndexEntriesOptions) ([]*IndexEntry, error) {
+	var opt IndexEntriesOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	stdout, err := NewCommand("ls-files", "--stage", "-z").
+		AddOptions(opt.CommandOptions).
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := parseIndexEntries(stdout)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []*IndexEntry{}
+	}
+	return entries, nil
+}
+
+// parseConflicts parses the output of "git ls-files -u -z", which is in the same
+// format as "git ls-files --stage -z" but only has conflicted entries.
+func parseConflicts(data []byte) ([]*Conflict, error) {
+	entries, err := parseIndexEntries(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var conflicts []*Conflict
+	byPath := make(map[string]*Conflict)
+	for _, entry := range entries {
+		c, ok := byPath[entry.Path]
+		if !ok {
+			c = &Conflict{Path: entry.Path}
+			byPath[entry.Path] = c
+			conflicts = append(conflicts, c)
+		}
+
+		switch entry.Stage {
+		case 1:
+			c.Base = entry.ID
+		case 2:
+			c.Ours = entry.ID
+		case 3:
+			c.Theirs = entry.ID
+		default:
+			return nil, fmt.Errorf("unexpected stage %d of %q", entry.Stage, entry.Path)
+		}
+	}
+	return conflicts, nil
+}
+
+// ConflictedFiles returns the list of conflicted paths in the index, e.g. after
+// a merge, rebase or cherry-pick stopped with conflicts. The returned list is
+// sorted by path, and is empty when there are no conflicts.
+func (r *Repository) ConflictedFiles(opts ...ConflictedFilesOptions) ([]*Conflict, error) {
+	var opt ConflictedFilesOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	stdout, err := NewCommand("ls-files", "--unmerged", "-z").
+		AddOptions(opt.CommandOptions).
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts, err := parseConflicts(stdout)
+	if err != nil {
+		return nil, err
+	}
+	if conflicts == nil {
+		conflicts = []*Conflict{}
+	}
+	return conflicts, nil
+}
+
+// StageContentOptions contains optional arguments for staging content.
+//
+// Docs: https://git-scm.com/docs/git-update-index#Documentation/git-update-index.txt---cacheinfoltmodegtltobjectgtltpathgt
+type StageContentOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// StageContent writes the content as a blob to the object database and stages
+// it at the path in the index with the mode (e.g. "100644" or "100755"),
+// without touching the working tree. The mode defaults to "100644" when empty.
+// It works for bare repositories as well.
+func (r *Repository) StageContent(path string, content []byte, mode string, opts ...StageContentOptions) error {
+	var opt StageContentOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	id, err := r.HashObject(bytes.NewReader(content), HashObjectOptions{
+		Timeout:        opt.Timeout, //nolint
+		CommandOptions: opt.CommandOptions,
+	})
+	if err != nil {
+		return err
+	}
+
+	if mode == "" {
+		mode = "100644"
+	}
+	_, err = NewCommand("update-index").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--add", "--cacheinfo", mode+","+id+","+path).
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	return err
+}
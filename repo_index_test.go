@@ -0,0 +1,233 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseIndexEntries(t *testing.T) {
+	const (
+		id1 = "1111111111111111111111111111111111111111"
+		id2 = "2222222222222222222222222222222222222222"
+	)
+	data := "100644 " + id1 + " 0\tREADME.txt\x00" +
+		"100755 " + id2 + " 0\trun.sh\x00" +
+		"120000 " + id1 + " 0\tlink\x00" +
+		"160000 " + id2 + " 0\tgogs/docs-api\x00" +
+		"100644 " + id1 + " 2\tconflict.txt\x00" +
+		"100644 " + id2 + " 3\tconflict.txt\x00"
+
+	entries, err := parseIndexEntries([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []*IndexEntry{
+		{Mode: EntryBlob, ID: MustIDFromString(id1), Stage: 0, Path: "README.txt"},
+		{Mode: EntryExec, ID: MustIDFromString(id2), Stage: 0, Path: "run.sh"},
+		{Mode: EntrySymlink, ID: MustIDFromString(id1), Stage: 0, Path: "link"},
+		{Mode: EntryCommit, ID: MustIDFromString(id2), Stage: 0, Path: "gogs/docs-api"},
+		{Mode: EntryBlob, ID: MustIDFromString(id1), Stage: 2, Path: "conflict.txt"},
+		{Mode: EntryBlob, ID: MustIDFromString(id2), Stage: 3, Path: "conflict.txt"},
+	}
+	assert.Equal(t, exp, entries)
+
+	for _, data := range []string{
+		"100644 " + id1 + " 0 no-tab\x00",
+		"100644 " + id1 + "\tmissing-stage\x00",
+		"bad " + id1 + " 0\tbad-mode\x00",
+		"100644 bad 0\tbad-id\x00",
+		"100644 " + id1 + " 4\tbad-stage\x00",
+	} {
+		_, err = parseIndexEntries([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestRepository_IndexEntries(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	err = commitFile(r, "index.txt", "index", "Add index.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := r.RevParse("HEAD:index.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := r.IndexEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, entries, &IndexEntry{
+		Mode:  EntryBlob,
+		ID:    MustIDFromString(id),
+		Stage: 0,
+		Path:  "index.txt",
+	})
+
+	branch, err := commitConflict(r, "conflict.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewCommand("merge", branch).RunInDir(r.Path())
+	assert.Error(t, err)
+
+	entries, err = r.IndexEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stages []int
+	for _, e := range entries {
+		if e.Path == "conflict.txt" {
+			stages = append(stages, e.Stage)
+		}
+	}
+	assert.Equal(t, []int{2, 3}, stages)
+}
+
+func Test_parseConflicts(t *testing.T) {
+	const (
+		base   = "1111111111111111111111111111111111111111"
+		ours   = "2222222222222222222222222222222222222222"
+		theirs = "3333333333333333333333333333333333333333"
+	)
+	data := "100644 " + base + " 1\tboth modified.txt\x00" +
+		"100644 " + ours + " 2\tboth modified.txt\x00" +
+		"100755 " + theirs + " 3\tboth modified.txt\x00" +
+		"100644 " + ours + " 2\tdir/added by us\x00" +
+		"100644 " + ours + " 2\tthey\tdeleted.txt\x00" +
+		"100644 " + theirs + " 3\tthey\tdeleted.txt\x00"
+
+	conflicts, err := parseConflicts([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []*Conflict{
+		{
+			Path:   "both modified.txt",
+			Base:   MustIDFromString(base),
+			Ours:   MustIDFromString(ours),
+			Theirs: MustIDFromString(theirs),
+		},
+		{
+			Path: "dir/added by us",
+			Ours: MustIDFromString(ours),
+		},
+		{
+			Path:   "they\tdeleted.txt",
+			Ours:   MustIDFromString(ours),
+			Theirs: MustIDFromString(theirs),
+		},
+	}
+	assert.Equal(t, exp, conflicts)
+
+	for _, data := range []string{
+		"100644 " + base + " 1 no-tab\x00",
+		"100644 " + base + "\tmissing-stage\x00",
+		"100644 bad 1\tbad-id\x00",
+		"100644 " + base + " 0\tstage-zero\x00",
+	} {
+		_, err = parseConflicts([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestRepository_ConflictedFiles(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	conflicts, err := r.ConflictedFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, conflicts)
+
+	branch, err := commitConflict(r, "conflict.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = NewCommand("merge", branch).RunInDir(r.Path())
+	assert.Error(t, err)
+
+	oursID, err := r.RevParse("HEAD:conflict.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	theirsID, err := r.RevParse(branch + ":conflict.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conflicts, err = r.ConflictedFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []*Conflict{
+		{
+			Path:   "conflict.txt",
+			Ours:   MustIDFromString(oursID),
+			Theirs: MustIDFromString(theirsID),
+		},
+	}
+	assert.Equal(t, exp, conflicts)
+}
+
+func TestRepository_StageContent(t *testing.T) {
+	path := tempPath()
+	defer func() {
+		_ = os.RemoveAll(path)
+	}()
+
+	err := Init(path, InitOptions{Bare: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.StageContent("dir/README.txt", []byte("Hello\n"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.StageContent("run.sh", []byte("#!/bin/sh\n"), "100755")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := r.IndexEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t,
+		[]*IndexEntry{
+			{Mode: EntryBlob, ID: MustIDFromString("e965047ad7c57865823c7d992b1d046ea66edf78"), Path: "dir/README.txt"},
+			{Mode: EntryExec, ID: MustIDFromString("1a2485251c33a70432394c93fb89330ef214bfc9"), Path: "run.sh"},
+		},
+		entries,
+	)
+
+	t.Run("invalid mode", func(t *testing.T) {
+		err := r.StageContent("bad.txt", []byte("bad"), "bad")
+		assert.Error(t, err)
+	})
+}
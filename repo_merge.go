@@ -0,0 +1,361 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	goversion "github.com/mcuadros/go-version"
+)
+
+// MergeOptions contains optional arguments for merging.
+//
+// Docs: https://git-scm.com/docs/git-merge
+type MergeOptions struct {
+	// Indicates whether to always create a merge commit, even when the merge
+	// could be resolved as a fast-forward.
+	NoFastForward bool
+	// Indicates whether to only allow fast-forward merges. ErrNotFastForward is
+	// returned when the merge cannot be resolved as a fast-forward.
+	FastForwardOnly bool
+	// Indicates whether to stage the merged changes in the index without making
+	// a commit.
+	Squash bool
+	// The message of the merge commit. Git's default message is used when not
+	// set.
+	Message string
+	// The merge strategy to use, e.g. "ort" or "recursive". Git's default
+	// strategy is used when not set.
+	Strategy string
+	// The committer of the merge commit, which is also used as the author. The
+	// identity from the config of the repository is used when not set.
+	Committer *Signature
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// Merge merges given revision into the current branch of the repository. It
+// returns a *MergeConflictError that lists the conflicted paths if the merge
+// has conflicts, and the working tree and the index are left in the conflicted
+// state for the caller to resolve or abort the merge.
+func (r *Repository) Merge(rev string, opts ...MergeOptions) error {
+	defer r.ClearReachabilityCache()
+
+	var opt MergeOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	cmd := NewCommand("merge", "--no-edit").AddOptions(opt.CommandOptions)
+	if opt.Committer != nil {
+		cmd.AddCommitter(opt.Committer).
+			AddEnvs("GIT_AUTHOR_NAME="+opt.Committer.Name, "GIT_AUTHOR_EMAIL="+opt.Committer.Email)
+	}
+	if opt.NoFastForward {
+		cmd.AddArgs("--no-ff")
+	}
+	if opt.FastForwardOnly {
+		cmd.AddArgs("--ff-only")
+	}
+	if opt.Squash {
+		cmd.AddArgs("--squash")
+	}
+	if opt.Message != "" {
+		cmd.AddArgs("-m", opt.Message)
+	}
+	if opt.Strategy != "" {
+		cmd.AddArgs("--strategy=" + opt.Strategy)
+	}
+
+	// 🚨 SECURITY: Prevent including unintended options in the path to the Git command.
+	_, err := cmd.AddArgs("--end-of-options", rev).RunInDirWithTimeout(opt.Timeout, r.path)
+	if err == nil {
+		return nil
+	}
+	if strings.Contains(err.Error(), "Not possible to fast-forward") {
+		return ErrNotFastForward
+	}
+
+	// Unmerged paths are left in the index when the merge has conflicts.
+	stdout, uerr := NewCommand("diff", "--name-only", "--diff-filter=U", "-z").
+		AddOptions(opt.CommandOptions).
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if uerr != nil {
+		return err
+	}
+	var conflicts []string
+	for _, path := range strings.Split(string(stdout), "\x00") {
+		if path != "" {
+			conflicts = append(conflicts, path)
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return &MergeConflictError{Conflicts: conflicts}
+	}
+	return err
+}
+
+// MergeTest is the result of a test merge.
+type MergeTest struct {
+	// Indicates whether the head revision merges into the base revision without
+	// conflicts.
+	Clean bool
+	// The ID of the tree of the merge result. It is only set when the merge is
+	// clean.
+	TreeID *SHA1
+	// The paths that have conflicts, sorted by path. It is empty when the merge
+	// is clean.
+	Conflicts []string
+}
+
+// TestMergeOptions contains optional arguments for testing a merge.
+//
+// Docs: https://git-scm.com/docs/git-merge-tree
+type TestMergeOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// parseMergeTree parses the output of "git merge-tree --write-tree --name-only
+// --no-messages -z", which is the ID of the tree followed by the conflicted
+// paths, each is terminated by a NUL.
+func parseMergeTree(data []byte) (*SHA1, []string, error) {
+	fields := bytes.Split(data, []byte{0})
+	treeID, err := NewIDFromString(string(fields[0]))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conflicts := []string{}
+	for _, path := range fields[1:] {
+		if len(path) == 0 {
+			continue
+		}
+
+		// A path is listed once for each of its conflicts.
+		if n := len(conflicts); n > 0 && conflicts[n-1] == string(path) {
+			continue
+		}
+		conflicts = append(conflicts, string(path))
+	}
+	return treeID, conflicts, nil
+}
+
+// TestMerge tests whether the head revision merges into the base revision
+// without conflicts, without touching the working tree or the index of the
+// repository. It returns ErrNoMergeBase if the two revisions have no common
+// ancestor.
+//
+// It uses "git merge-tree --write-tree" with Git 2.38 and later. With older
+// versions of Git, it falls back to a three-way merge in a temporary index
+// that resolves conflicting text files with "git merge-file", which may
+// report conflicts that a recursive merge strategy would resolve, e.g.
+// renames.
+func (r *Repository) TestMerge(base, head string, opts ...TestMergeOptions) (*MergeTest, error) {
+	var opt TestMergeOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	version, err := BinVersion()
+	if err != nil {
+		return nil, err
+	}
+	if goversion.Compare(version, "2.38", "<") {
+		return r.testMergeWithIndex(base, head, opt)
+	}
+
+	stdout := new(bytes.Buffer)
+	stderr := newTailBuffer(stderrLimit)
+	cmd := NewCommand("merge-tree", "--write-tree", "--name-only", "--no-messages", "-z").
+		AddOptions(opt.CommandOptions).
+		AddArgs(base, head)
+	if opt.Timeout != 0 {
+		cmd = cmd.WithTimeout(opt.Timeout)
+	}
+	err = cmd.RunInDirPipeline(stdout, stderr, r.path)
+	if err != nil {
+		if strings.Contains(stderr.String(), "refusing to merge unrelated histories") {
+			return nil, ErrNoMergeBase
+		}
+
+		// Exit status 1 means the merge has conflicts.
+		if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+			return nil, concatenateError(err, stderr.String())
+		}
+	}
+
+	treeID, conflicts, err := parseMergeTree(stdout.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) > 0 {
+		return &MergeTest{Conflicts: conflicts}, nil
+	}
+	return &MergeTest{
+		Clean:     true,
+		TreeID:    treeID,
+		Conflicts: conflicts,
+	}, nil
+}
+
+// testMergeWithIndex tests the merge in a temporary index for Git versions that
+// do not support "git merge-tree --write-tree".
+func (r *Repository) testMergeWithIndex(base, head string, opt TestMergeOptions) (*MergeTest, error) {
+	mergeBase, err := r.MergeBase(base, head, MergeBaseOptions{
+		Timeout:        opt.Timeout, //nolint
+		CommandOptions: opt.CommandOptions,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := ioutil.TempDir("", "git-module-merge-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	cmdOpts := opt.CommandOptions
+	cmdOpts.Envs = append(append([]string{}, cmdOpts.Envs...), "GIT_INDEX_FILE="+filepath.Join(dir, "index"))
+	_, err = NewCommand("read-tree", "-i", "-m", "--aggressive").
+		AddOptions(cmdOpts).
+		AddArgs(mergeBase, base, head).
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := r.IndexEntries(IndexEntriesOptions{
+		Timeout:        opt.Timeout, //nolint
+		CommandOptions: cmdOpts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Entries of the same path are next to each other in the order of stages.
+	conflicts := []string{}
+	for i := 0; i < len(entries); {
+		path := entries[i].Path
+		stages := make([]*IndexEntry, 4)
+		for ; i < len(entries) && entries[i].Path == path; i++ {
+			stages[entries[i].Stage] = entries[i]
+		}
+		if stages[0] != nil {
+			continue
+		}
+
+		resolved, err := r.mergeFile(dir, stages[1], stages[2], stages[3], opt.Timeout, cmdOpts)
+		if err != nil {
+			return nil, err
+		} else if !resolved {
+			conflicts = append(conflicts, path)
+		}
+	}
+	if len(conflicts) > 0 {
+		return &MergeTest{Conflicts: conflicts}, nil
+	}
+
+	stdout, err := NewCommand("write-tree").
+		AddOptions(cmdOpts).
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		return nil, err
+	}
+	treeID, err := NewIDFromString(strings.TrimSpace(string(stdout)))
+	if err != nil {
+		return nil, err
+	}
+	return &MergeTest{
+		Clean:     true,
+		TreeID:    treeID,
+		Conflicts: conflicts,
+	}, nil
+}
+
+// mergeFile tries to resolve a conflicted path in the index by merging the
+// content of the both sides with "git merge-file". It returns false when the
+// path still has conflicts, or is not a regular file modified by both sides.
+func (r *Repository) mergeFile(dir string, base, ours, theirs *IndexEntry, timeout time.Duration, cmdOpts CommandOptions) (bool, error) {
+	isRegular := func(e *IndexEntry) bool {
+		return e != nil && (e.Mode == EntryBlob || e.Mode == EntryExec)
+	}
+	if !isRegular(base) || !isRegular(ours) || !isRegular(theirs) {
+		return false, nil
+	}
+
+	files := make([]string, 3)
+	for i, e := range []*IndexEntry{ours, base, theirs} {
+		// The content is read with the options of the caller so that objects in a
+		// quarantine directory can be resolved.
+		rc, _, err := r.CatFileBlobReader(e.ID.String(), CatFileBlobOptions{
+			Timeout:        timeout, //nolint
+			CommandOptions: cmdOpts,
+		})
+		if err != nil {
+			return false, err
+		}
+		p, err := ioutil.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			return false, err
+		}
+
+		files[i] = filepath.Join(dir, []string{"ours", "base", "theirs"}[i])
+		err = ioutil.WriteFile(files[i], p, 0600)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	stdout := new(bytes.Buffer)
+	stderr := newTailBuffer(stderrLimit)
+	cmd := NewCommand("merge-file", "-p").
+		AddOptions(cmdOpts).
+		AddArgs(files...)
+	if timeout != 0 {
+		cmd = cmd.WithTimeout(timeout)
+	}
+	err := cmd.RunInDirPipeline(stdout, stderr, r.path)
+	if err != nil {
+		// A positive exit status is the number of conflicts.
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() > 0 && exitErr.ExitCode() < 128 {
+			return false, nil
+		}
+		return false, concatenateError(err, stderr.String())
+	}
+
+	// Staging the merged content at stage 0 also removes the conflicted stages.
+	mode := "100644"
+	if ours.Mode == EntryExec {
+		mode = "100755"
+	}
+	err = r.StageContent(ours.Path, stdout.Bytes(), mode, StageContentOptions{
+		Timeout:        timeout, //nolint
+		CommandOptions: cmdOpts,
+	})
+	return err == nil, err
+}
@@ -0,0 +1,325 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_Merge(t *testing.T) {
+	committer := &Signature{
+		Name:  "alice",
+		Email: "alice@example.com",
+	}
+
+	t.Run("fast-forward", func(t *testing.T) {
+		r, cleanup, err := setupTempRepo()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cleanup()
+
+		err = r.Checkout("feature", CheckoutOptions{BaseBranch: "master"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = commitFile(r, "feature.txt", "feature", "Add feature.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = r.Checkout("master")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = r.Merge("feature", MergeOptions{FastForwardOnly: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		masterID, err := r.RevParse("master")
+		if err != nil {
+			t.Fatal(err)
+		}
+		featureID, err := r.RevParse("feature")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, featureID, masterID)
+	})
+
+	t.Run("no fast-forward", func(t *testing.T) {
+		r, cleanup, err := setupTempRepo()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cleanup()
+
+		err = setupDivergedBranches(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = r.Merge("feature", MergeOptions{FastForwardOnly: true})
+		assert.Equal(t, ErrNotFastForward, err)
+
+		err = r.Merge("feature", MergeOptions{
+			NoFastForward: true,
+			Message:       "Merge feature",
+			Committer:     committer,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		commit, err := r.CatFileCommit("master")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "Merge feature\n", commit.Message)
+		assert.Equal(t, 2, commit.ParentsCount())
+		assert.Equal(t, committer.Email, commit.Committer.Email)
+		assert.Equal(t, committer.Email, commit.Author.Email)
+	})
+
+	t.Run("squash", func(t *testing.T) {
+		r, cleanup, err := setupTempRepo()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cleanup()
+
+		err = setupDivergedBranches(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		headID, err := r.RevParse("HEAD")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = r.Merge("feature", MergeOptions{Squash: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The changes are staged without making a commit.
+		newHeadID, err := r.RevParse("HEAD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, headID, newHeadID)
+		stdout, err := NewCommand("diff", "--cached", "--name-only").RunInDir(r.Path())
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "feature1.txt\nfeature2.txt\n", string(stdout))
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		r, cleanup, err := setupTempRepo()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cleanup()
+
+		branch, err := commitConflict(r, "b.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = commitConflict(r, "a.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = r.Merge(branch, MergeOptions{Strategy: "recursive", Committer: committer})
+		if !assert.IsType(t, &MergeConflictError{}, err) {
+			return
+		}
+		assert.Equal(t, []string{"b.txt"}, err.(*MergeConflictError).Conflicts)
+		assert.True(t, errors.Is(err, ErrMergeConflict))
+	})
+
+	t.Run("bad revision", func(t *testing.T) {
+		r, cleanup, err := setupTempRepo()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cleanup()
+
+		err = r.Merge("404")
+		assert.Error(t, err)
+	})
+}
+
+func Test_parseMergeTree(t *testing.T) {
+	const treeID = "717a2127278f3e746fdbb80d44e1e9c264526183"
+
+	t.Run("clean", func(t *testing.T) {
+		id, conflicts, err := parseMergeTree([]byte(treeID + "\x00"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, treeID, id.String())
+		assert.Empty(t, conflicts)
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		id, conflicts, err := parseMergeTree([]byte(treeID + "\x00a.txt\x00a.txt\x00dir/b.txt\x00"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, treeID, id.String())
+		assert.Equal(t, []string{"a.txt", "dir/b.txt"}, conflicts)
+	})
+
+	t.Run("bad tree ID", func(t *testing.T) {
+		_, _, err := parseMergeTree([]byte("bad\x00"))
+		assert.Error(t, err)
+	})
+}
+
+func TestRepository_TestMerge(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	err = commitFile(r, "merge.txt", "1\n2\n3\n4\n5\n", "Add merge.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewCommand("branch", "clean").RunInDir(r.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = commitFile(r, "merge.txt", "one\n2\n3\n4\n5\n", "Change first line")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The "clean" branch changes another part of the same file.
+	_, err = NewCommand("checkout", "clean").RunInDir(r.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = commitFile(r, "merge.txt", "1\n2\n3\n4\nfive\n", "Change last line")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewCommand("checkout", "master").RunInDir(r.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conflictBranch, err := commitConflict(r, "merge.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = NewCommand("checkout", "--orphan", "orphan").RunInDir(r.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = commitFile(r, "orphan.txt", "orphan", "Add orphan.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewCommand("checkout", "-f", "master").RunInDir(r.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, testMerge := range map[string]func(base, head string) (*MergeTest, error){
+		"merge-tree": func(base, head string) (*MergeTest, error) {
+			return r.TestMerge(base, head)
+		},
+		"index": func(base, head string) (*MergeTest, error) {
+			return r.testMergeWithIndex(base, head, TestMergeOptions{})
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Run("clean", func(t *testing.T) {
+				result, err := testMerge(conflictBranch+"~1", "clean")
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.True(t, result.Clean)
+				assert.Empty(t, result.Conflicts)
+
+				// The merged content is written to the object database.
+				stdout, err := NewCommand("cat-file", "-p", result.TreeID.String()+":merge.txt").RunInDir(r.Path())
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, "one\n2\n3\n4\nfive\n", string(stdout))
+			})
+
+			t.Run("conflict", func(t *testing.T) {
+				result, err := testMerge("master", conflictBranch)
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, &MergeTest{Conflicts: []string{"merge.txt"}}, result)
+			})
+
+			t.Run("no merge base", func(t *testing.T) {
+				_, err := testMerge("master", "orphan")
+				assert.Equal(t, ErrNoMergeBase, err)
+			})
+		})
+	}
+
+	t.Run("quarantine", func(t *testing.T) {
+		// Change another line of the file on top of the "clean" branch in a
+		// quarantine directory, so that the content of that side is only visible
+		// with the environment variables.
+		objectDir := filepath.Join(r.Path(), ".git", "objects")
+		quarantineDir := filepath.Join(objectDir, "incoming-test")
+		err := os.MkdirAll(quarantineDir, os.ModePerm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		envs := QuarantineEnvs(quarantineDir, objectDir)
+
+		run := func(stdin string, args ...string) string {
+			stdout := new(strings.Builder)
+			stderr := new(strings.Builder)
+			err := NewCommand(args...).AddEnvs(envs...).RunInDirWithOptions(r.Path(), RunInDirOptions{
+				Stdin:  strings.NewReader(stdin),
+				Stdout: stdout,
+				Stderr: stderr,
+			})
+			if err != nil {
+				t.Fatal(concatenateError(err, stderr.String()))
+			}
+			return strings.TrimSpace(stdout.String())
+		}
+		blobID := run("1\n2\nthree\n4\nfive\n", "hash-object", "-w", "--stdin")
+		treeID := run("100644 blob "+blobID+"\tmerge.txt\n", "mktree")
+		commitID := run("", "commit-tree", treeID, "-p", "clean", "-m", "Change middle line")
+
+		result, err := r.testMergeWithIndex(conflictBranch+"~1", commitID, TestMergeOptions{
+			CommandOptions: CommandOptions{Envs: envs},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, result.Clean)
+		assert.Equal(t, "one\n2\nthree\n4\nfive", run("", "cat-file", "-p", result.TreeID.String()+":merge.txt"))
+	})
+
+	// The working tree and the index are not touched.
+	stdout, err := NewCommand("status", "--porcelain").RunInDir(r.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, string(stdout))
+}
@@ -0,0 +1,175 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// gitDir returns the absolute path of the Git directory of the repository by
+// inspecting the file system. It is the repository path itself for a bare
+// repository, or where the ".git" file points to for a linked worktree. The
+// parent directories are looked up when the repository path is a subdirectory
+// of the working tree.
+func (r *Repository) gitDir() (string, error) {
+	for dir := r.path; ; dir = filepath.Dir(dir) {
+		dotGit := filepath.Join(dir, ".git")
+		fi, err := os.Stat(dotGit)
+		if err == nil {
+			if fi.IsDir() {
+				return dotGit, nil
+			}
+			return readGitDirFile(dotGit)
+		} else if !os.IsNotExist(err) {
+			return "", err
+		}
+
+		if isFile(filepath.Join(dir, "HEAD")) && isDir(filepath.Join(dir, "objects")) {
+			return dir, nil
+		} else if filepath.Dir(dir) == dir {
+			return r.path, nil
+		}
+	}
+}
+
+// commonDir returns the absolute path of the Git directory that is shared by
+// all worktrees of the repository, e.g. where references and objects are
+// stored. It is the Git directory itself unless the repository is a linked
+// worktree.
+func (r *Repository) commonDir() (string, error) {
+	dir, err := r.gitDir()
+	if err != nil {
+		return "", err
+	}
+
+	p, err := ioutil.ReadFile(filepath.Join(dir, "commondir"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return dir, nil
+		}
+		return "", err
+	}
+
+	common := strings.TrimSpace(string(p))
+	if !filepath.IsAbs(common) {
+		common = filepath.Join(dir, common)
+	}
+	return common, nil
+}
+
+// readGitDirFile returns the absolute path of the Git directory that the ".git"
+// file points to.
+func readGitDirFile(dotGit string) (string, error) {
+	p, err := ioutil.ReadFile(dotGit)
+	if err != nil {
+		return "", err
+	}
+
+	const prefix = "gitdir:"
+	line := strings.TrimSpace(string(p))
+	if !strings.HasPrefix(line, prefix) {
+		return "", fmt.Errorf("malformed %q: %q", dotGit, line)
+	}
+
+	dir := strings.TrimSpace(line[len(prefix):])
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(dotGit), dir)
+	}
+	return dir, nil
+}
+
+// OperationState is the state of a multi-step operation of the repository.
+type OperationState string
+
+// A list of operation states.
+const (
+	OperationNone       OperationState = ""
+	OperationMerge      OperationState = "merge"
+	OperationRebase     OperationState = "rebase"
+	OperationCherryPick OperationState = "cherry-pick"
+	OperationRevert     OperationState = "revert"
+	OperationBisect     OperationState = "bisect"
+)
+
+// operationMarkers is the list of marker files in the Git directory that
+// indicates an operation is in progress. A rebase is checked first because it
+// may leave other markers behind while it stops at a conflict, and a bisect is
+// checked last because other operations could be started during a bisect.
+var operationMarkers = []struct {
+	marker string
+	state  OperationState
+}{
+	{"rebase-merge", OperationRebase},
+	{"rebase-apply", OperationRebase},
+	{"MERGE_HEAD", OperationMerge},
+	{"CHERRY_PICK_HEAD", OperationCherryPick},
+	{"REVERT_HEAD", OperationRevert},
+	{"BISECT_LOG", OperationBisect},
+}
+
+// InProgress returns the state of the operation that is in progress in the
+// repository, or OperationNone if there is none. It only inspects the file
+// system without executing any Git command.
+func (r *Repository) InProgress() (OperationState, error) {
+	dir, err := r.gitDir()
+	if err != nil {
+		return OperationNone, err
+	}
+
+	for _, m := range operationMarkers {
+		if isExist(filepath.Join(dir, m.marker)) {
+			return m.state, nil
+		}
+	}
+	return OperationNone, nil
+}
+
+// AbortInProgressOptions contains optional arguments for aborting the operation
+// in progress.
+type AbortInProgressOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// AbortInProgress aborts the merge, rebase, cherry-pick or revert that is in
+// progress in the repository to get back to the state before it was started. It
+// does nothing if there is no such operation in progress, and a bisect is left
+// as it is.
+func (r *Repository) AbortInProgress(opts ...AbortInProgressOptions) error {
+	defer r.ClearReachabilityCache()
+
+	var opt AbortInProgressOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	state, err := r.InProgress()
+	if err != nil {
+		return err
+	}
+
+	switch state {
+	case OperationMerge, OperationRebase, OperationCherryPick, OperationRevert:
+	default:
+		return nil
+	}
+
+	// The name of each state is also the subcommand of the operation.
+	_, err = NewCommand(string(state)).
+		AddOptions(opt.CommandOptions).
+		AddArgs("--abort").
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	return err
+}
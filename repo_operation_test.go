@@ -0,0 +1,220 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_gitDir(t *testing.T) {
+	t.Run("bare", func(t *testing.T) {
+		r, err := Open(testrepo.Path())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dir, err := r.gitDir()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, r.Path(), dir)
+	})
+
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	t.Run("non-bare", func(t *testing.T) {
+		dir, err := r.gitDir()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, filepath.Join(r.Path(), ".git"), dir)
+	})
+
+	t.Run("linked worktree", func(t *testing.T) {
+		path := tempPath()
+		defer func() {
+			_ = os.RemoveAll(path)
+		}()
+
+		_, err := NewCommand("worktree", "add", "-b", "worktree", path).RunInDir(r.Path())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wt, err := Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dir, err := wt.gitDir()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, isFile(filepath.Join(dir, "HEAD")))
+		assert.Equal(t, "worktrees", filepath.Base(filepath.Dir(dir)))
+	})
+}
+
+func TestRepository_InProgress(t *testing.T) {
+	state, err := testrepo.InProgress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, OperationNone, state)
+
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	state, err = r.InProgress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, OperationNone, state)
+
+	_, err = NewCommand("bisect", "start").RunInDir(r.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state, err = r.InProgress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, OperationBisect, state)
+
+	// A bisect is not aborted
+	err = r.AbortInProgress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, err = r.InProgress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, OperationBisect, state)
+}
+
+func TestRepository_AbortInProgress(t *testing.T) {
+	tests := []struct {
+		name     string
+		start    func(r *Repository) error
+		expState OperationState
+	}{
+		{
+			name:     "merge",
+			expState: OperationMerge,
+			start: func(r *Repository) error {
+				branch, err := commitConflict(r, "merge.txt")
+				if err != nil {
+					return err
+				}
+				_, err = NewCommand("merge", branch).RunInDir(r.Path())
+				return err
+			},
+		},
+		{
+			name:     "rebase",
+			expState: OperationRebase,
+			start: func(r *Repository) error {
+				branch, err := commitConflict(r, "rebase.txt")
+				if err != nil {
+					return err
+				}
+				_, err = NewCommand("rebase", branch).RunInDir(r.Path())
+				return err
+			},
+		},
+		{
+			name:     "cherry-pick",
+			expState: OperationCherryPick,
+			start: func(r *Repository) error {
+				branch, err := commitConflict(r, "cherry-pick.txt")
+				if err != nil {
+					return err
+				}
+				_, err = NewCommand("cherry-pick", branch).RunInDir(r.Path())
+				return err
+			},
+		},
+		{
+			name:     "revert",
+			expState: OperationRevert,
+			start: func(r *Repository) error {
+				for _, content := range []string{"1\n", "2\n", "3\n"} {
+					err := commitFile(r, "revert.txt", content, "Change revert.txt to "+content)
+					if err != nil {
+						return err
+					}
+				}
+				_, err := NewCommand("revert", "--no-edit", "HEAD~1").RunInDir(r.Path())
+				return err
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, cleanup, err := setupTempRepo()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer cleanup()
+
+			// Nothing is in progress
+			assert.Nil(t, r.AbortInProgress())
+
+			err = test.start(r)
+			assert.Error(t, err)
+
+			conflicts, err := r.ConflictedFiles()
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.NotEmpty(t, conflicts)
+
+			state, err := r.InProgress()
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, test.expState, state)
+
+			err = r.AbortInProgress()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			conflicts, err = r.ConflictedFiles()
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Empty(t, conflicts)
+
+			// A rebase detaches HEAD until it is finished or aborted
+			branch, err := r.CurrentBranch()
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, "master", branch)
+
+			// Nothing is in progress anymore
+			state, err = r.InProgress()
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, OperationNone, state)
+			assert.Nil(t, r.AbortInProgress())
+		})
+	}
+}
@@ -51,5 +51,16 @@ func RepoMergeBase(repoPath, base, head string, opts ...MergeBaseOptions) (strin
 // MergeBase returns merge base between base and head revisions of the
 // repository.
 func (r *Repository) MergeBase(base, head string, opts ...MergeBaseOptions) (string, error) {
-	return MergeBase(r.path, base, head, opts...)
+	key := r.reachabilityKey("merge-base", base, head)
+	if cache, ok := r.getReachability(key); ok {
+		return cache.(string), nil
+	}
+
+	mergeBase, err := MergeBase(r.path, base, head, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	r.setReachability(key, mergeBase)
+	return mergeBase, nil
 }
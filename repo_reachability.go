@@ -0,0 +1,350 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	goversion "github.com/mcuadros/go-version"
+)
+
+// EnableReachabilityCache enables the in-memory cache of reachability queries
+// between revisions, i.e. MergeBase, IsAncestor and AheadBehind, so repeated
+// queries with the same revisions do not execute Git again. The cache is
+// cleared by any method of the Repository that may update references. Call
+// ClearReachabilityCache after updating references by other means.
+//
+// It should be called before the Repository is used concurrently, and before
+// any handle is derived by In to share the cache with the handle.
+func (r *Repository) EnableReachabilityCache() {
+	if r.cachedReachability == nil {
+		r.cachedReachability = newObjectCache()
+	}
+}
+
+// ClearReachabilityCache clears the cache of reachability queries if it has
+// been enabled.
+func (r *Repository) ClearReachabilityCache() {
+	if r.cachedReachability != nil {
+		r.cachedReachability.Clear()
+	}
+}
+
+// reachabilityKey returns the cache key of given kind of query between base
+// and head revisions. The path of the repository is part of the key because
+// revisions like "HEAD" resolve differently in each worktree.
+func (r *Repository) reachabilityKey(kind, base, head string) string {
+	return r.path + "\x00" + kind + "\x00" + base + "\x00" + head
+}
+
+// getReachability returns the cached result of the query by given key.
+func (r *Repository) getReachability(key string) (interface{}, bool) {
+	if r.cachedReachability == nil {
+		return nil, false
+	}
+	return r.cachedReachability.Get(key)
+}
+
+// setReachability caches the result of the query by given key if the cache has
+// been enabled.
+func (r *Repository) setReachability(key string, result interface{}) {
+	if r.cachedReachability != nil {
+		r.cachedReachability.Set(key, result)
+	}
+}
+
+// IsAncestorOptions contains optional arguments for checking ancestry.
+//
+// Docs: https://git-scm.com/docs/git-merge-base#Documentation/git-merge-base.txt---is-ancestor
+type IsAncestorOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// IsAncestor returns true if the base revision is an ancestor of the head
+// revision, or they are the same commit. It returns ErrRevisionNotExist if
+// either revision cannot be resolved.
+func (r *Repository) IsAncestor(base, head string, opts ...IsAncestorOptions) (bool, error) {
+	var opt IsAncestorOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	key := r.reachabilityKey("is-ancestor", base, head)
+	if cache, ok := r.getReachability(key); ok {
+		return cache.(bool), nil
+	}
+
+	_, err := NewCommand("merge-base", "--is-ancestor").
+		AddOptions(opt.CommandOptions).
+		AddArgs(base, head).
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		if strings.Contains(err.Error(), "Not a valid object name") ||
+			strings.Contains(err.Error(), "Not a valid commit name") {
+			return false, ErrRevisionNotExist
+		}
+		// Exit code 1 without any error output means it is not an ancestor.
+		if !isExitCode(err, 1) {
+			return false, err
+		}
+	}
+
+	isAncestor := err == nil
+	r.setReachability(key, isAncestor)
+	return isAncestor, nil
+}
+
+// IndependentCommitsOptions contains optional arguments for finding
+// independent commits.
+//
+// Docs: https://git-scm.com/docs/git-merge-base#Documentation/git-merge-base.txt---independent
+type IndependentCommitsOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// IndependentCommits returns the IDs of commits of given revisions that cannot
+// be reached from any other of them, i.e. it drops revisions that are
+// ancestors of the others.
+func (r *Repository) IndependentCommits(revs []string, opts ...IndependentCommitsOptions) ([]string, error) {
+	var opt IndependentCommitsOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	if len(revs) == 0 {
+		return []string{}, nil
+	}
+
+	stdout, err := NewCommand("merge-base", "--independent").
+		AddOptions(opt.CommandOptions).
+		AddArgs(revs...).
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		return nil, err
+	}
+	return bytesToStrings(stdout), nil
+}
+
+// AheadBehindOptions contains optional arguments for counting diverged commits.
+//
+// Docs: https://git-scm.com/docs/git-rev-list#Documentation/git-rev-list.txt---left-right
+type AheadBehindOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// AheadBehind returns the number of commits that the head revision is ahead of
+// and behind the base revision respectively. It returns ErrRevisionNotExist if
+// either revision cannot be resolved.
+func (r *Repository) AheadBehind(base, head string, opts ...AheadBehindOptions) (ahead, behind int64, _ error) {
+	var opt AheadBehindOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	key := r.reachabilityKey("ahead-behind", base, head)
+	if cache, ok := r.getReachability(key); ok {
+		counts := cache.([2]int64)
+		return counts[0], counts[1], nil
+	}
+
+	stdout, err := NewCommand("rev-list", "--left-right", "--count").
+		AddOptions(opt.CommandOptions).
+		AddArgs(base+"..."+head, "--").
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		if strings.Contains(err.Error(), "bad revision") {
+			return 0, 0, ErrRevisionNotExist
+		}
+		return 0, 0, err
+	}
+
+	// The output is "<behind> TAB <ahead>" as the base is on the left.
+	fields := strings.Fields(string(stdout))
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("malformed output: %q", stdout)
+	}
+	behind, err = strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("parse behind count %q: %v", fields[0], err)
+	}
+	ahead, err = strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("parse ahead count %q: %v", fields[1], err)
+	}
+
+	r.setReachability(key, [2]int64{ahead, behind})
+	return ahead, behind, nil
+}
+
+// BranchStatus is the status of a branch relative to a base revision.
+type BranchStatus struct {
+	// The name of the branch, e.g. "master".
+	Name string
+	// The ID of the tip commit of the branch.
+	ID *SHA1
+	// The number of commits that the branch is ahead of the base revision.
+	Ahead int64
+	// The number of commits that the branch is behind the base revision.
+	Behind int64
+	// The committer date of the tip commit of the branch.
+	CommitDate time.Time
+}
+
+// BranchesStatusOptions contains optional arguments for getting the status of
+// branches.
+//
+// Docs: https://git-scm.com/docs/git-for-each-ref#Documentation/git-for-each-ref.txt-ahead-behindcommittish
+type BranchesStatusOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// branchesStatusFormat is the format of "git for-each-ref" to list branches
+// with their tip commits, each field is terminated by a NUL. The field of
+// ahead and behind counts is appended with Git 2.41 and later.
+const branchesStatusFormat = "%(refname)%00%(objectname)%00%(committerdate:iso-strict)%00"
+
+// parseBranchesStatus parses the output of "git for-each-ref" with
+// branchesStatusFormat, where each line optionally ends with the ahead and
+// behind counts separated by a space.
+func parseBranchesStatus(data []byte) ([]*BranchStatus, error) {
+	lines := bytesToStrings(data)
+	branches := make([]*BranchStatus, 0, len(lines))
+	for _, line := range lines {
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("malformed branch: %q", line)
+		}
+
+		name := strings.TrimPrefix(fields[0], RefsHeads)
+		id, err := NewIDFromString(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("parse ID of %q: %v", name, err)
+		}
+		date, err := time.Parse(time.RFC3339, fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("parse commit date of %q: %v", name, err)
+		}
+
+		branch := &BranchStatus{
+			Name:       name,
+			ID:         id,
+			CommitDate: date,
+		}
+		if fields[3] != "" {
+			counts := strings.Fields(fields[3])
+			if len(counts) != 2 {
+				return nil, fmt.Errorf("malformed ahead and behind counts of %q: %q", name, fields[3])
+			}
+			branch.Ahead, err = strconv.ParseInt(counts[0], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parse ahead count of %q: %v", name, err)
+			}
+			branch.Behind, err = strconv.ParseInt(counts[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parse behind count of %q: %v", name, err)
+			}
+		}
+		branches = append(branches, branch)
+	}
+	return branches, nil
+}
+
+// BranchesStatus returns the status of every local branch relative to the base
+// revision, sorted by branch names. It returns ErrRevisionNotExist if the base
+// revision cannot be resolved.
+//
+// With Git 2.41 and later, it takes a single Git command. With older versions
+// of Git, the ahead and behind counts are computed by AheadBehind for each
+// branch.
+func (r *Repository) BranchesStatus(base string, opts ...BranchesStatusOptions) ([]*BranchStatus, error) {
+	var opt BranchesStatusOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	version, err := BinVersion()
+	if err != nil {
+		return nil, err
+	}
+	if goversion.Compare(version, "2.41", "<") {
+		return r.branchesStatusWithRevList(base, opt)
+	}
+
+	// The base is resolved to a commit ID beforehand because it becomes part of
+	// the format, which would be broken by special characters.
+	baseID, err := r.RevParse(base, RevParseOptions{Timeout: opt.Timeout}) //nolint
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, err := NewCommand("for-each-ref").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--format="+branchesStatusFormat+"%(ahead-behind:"+baseID+")", RefsHeads).
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		return nil, err
+	}
+	return parseBranchesStatus(stdout)
+}
+
+// branchesStatusWithRevList returns the status of every local branch relative
+// to the base revision, where the ahead and behind counts are computed by
+// AheadBehind for each branch.
+func (r *Repository) branchesStatusWithRevList(base string, opt BranchesStatusOptions) ([]*BranchStatus, error) {
+	baseID, err := r.RevParse(base, RevParseOptions{Timeout: opt.Timeout}) //nolint
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, err := NewCommand("for-each-ref").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--format="+branchesStatusFormat, RefsHeads).
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		return nil, err
+	}
+
+	branches, err := parseBranchesStatus(stdout)
+	if err != nil {
+		return nil, err
+	}
+	for _, branch := range branches {
+		branch.Ahead, branch.Behind, err = r.AheadBehind(baseID, branch.ID.String(), AheadBehindOptions{
+			Timeout:        opt.Timeout, //nolint
+			CommandOptions: opt.CommandOptions,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("count ahead and behind of %q: %v", branch.Name, err)
+		}
+	}
+	return branches, nil
+}
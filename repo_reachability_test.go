@@ -0,0 +1,287 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setupDivergedBranches commits twice on a new branch "feature" and once on
+// "master" after the branch point.
+func setupDivergedBranches(r *Repository) error {
+	err := r.Checkout("feature", CheckoutOptions{BaseBranch: "master"})
+	if err != nil {
+		return err
+	}
+	for _, name := range []string{"feature1.txt", "feature2.txt"} {
+		err = commitFile(r, name, name, "Add "+name)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = r.Checkout("master")
+	if err != nil {
+		return err
+	}
+	return commitFile(r, "master.txt", "master", "Add master.txt")
+}
+
+func TestRepository_IsAncestor(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	err = setupDivergedBranches(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		base          string
+		head          string
+		expIsAncestor bool
+	}{
+		{base: "feature~1", head: "feature", expIsAncestor: true},
+		{base: "feature", head: "feature", expIsAncestor: true},
+		{base: "feature", head: "feature~1", expIsAncestor: false},
+		{base: "master", head: "feature", expIsAncestor: false},
+	}
+	for _, test := range tests {
+		t.Run(test.base+" "+test.head, func(t *testing.T) {
+			isAncestor, err := r.IsAncestor(test.base, test.head)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, test.expIsAncestor, isAncestor)
+		})
+	}
+
+	t.Run("bad revision", func(t *testing.T) {
+		_, err := r.IsAncestor("404", "feature")
+		assert.Equal(t, ErrRevisionNotExist, err)
+
+		_, err = r.IsAncestor("feature", "404")
+		assert.Equal(t, ErrRevisionNotExist, err)
+
+		_, err = r.IsAncestor(EmptyID, "feature")
+		assert.Equal(t, ErrRevisionNotExist, err)
+	})
+}
+
+func TestRepository_AheadBehind(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	err = setupDivergedBranches(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ahead, behind, err := r.AheadBehind("master", "feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(2), ahead)
+	assert.Equal(t, int64(1), behind)
+
+	ahead, behind, err = r.AheadBehind("feature", "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(1), ahead)
+	assert.Equal(t, int64(2), behind)
+
+	_, _, err = r.AheadBehind("404", "feature")
+	assert.Equal(t, ErrRevisionNotExist, err)
+	_, _, err = r.AheadBehind("master", "404")
+	assert.Equal(t, ErrRevisionNotExist, err)
+}
+
+func TestRepository_IndependentCommits(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	err = setupDivergedBranches(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expIDs []string
+	for _, rev := range []string{"master", "feature"} {
+		id, err := r.RevParse(rev)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expIDs = append(expIDs, id)
+	}
+
+	ids, err := r.IndependentCommits([]string{"master", "master~1", "feature", "feature~1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.ElementsMatch(t, expIDs, ids)
+
+	ids, err = r.IndependentCommits(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, ids)
+
+	_, err = r.IndependentCommits([]string{"404"})
+	assert.Error(t, err)
+}
+
+func TestRepository_EnableReachabilityCache(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	err = setupDivergedBranches(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.EnableReachabilityCache()
+
+	query := func() (string, bool, int64) {
+		mergeBase, err := r.MergeBase("master", "feature")
+		if err != nil {
+			t.Fatal(err)
+		}
+		isAncestor, err := r.IsAncestor("master", "feature")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, behind, err := r.AheadBehind("master", "feature")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return mergeBase, isAncestor, behind
+	}
+
+	mergeBase, isAncestor, behind := query()
+	assert.False(t, isAncestor)
+	assert.Equal(t, int64(1), behind)
+
+	// Changes made by other means are not seen until the cache is cleared.
+	_, err = NewCommand("update-ref", RefsHeads+"feature", "master").RunInDir(r.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotMergeBase, gotIsAncestor, gotBehind := query()
+	assert.Equal(t, mergeBase, gotMergeBase)
+	assert.False(t, gotIsAncestor)
+	assert.Equal(t, int64(1), gotBehind)
+
+	r.ClearReachabilityCache()
+	master, err := r.RevParse("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotMergeBase, gotIsAncestor, gotBehind = query()
+	assert.Equal(t, master, gotMergeBase)
+	assert.True(t, gotIsAncestor)
+	assert.Equal(t, int64(0), gotBehind)
+
+	// Changes made through the repository clear the cache.
+	err = commitFile(r, "master2.txt", "master", "Add master2.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, gotIsAncestor, gotBehind = query()
+	assert.False(t, gotIsAncestor)
+	assert.Equal(t, int64(1), gotBehind)
+}
+
+func TestRepository_BranchesStatus(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	err = setupDivergedBranches(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, branchesStatus := range map[string]func(base string) ([]*BranchStatus, error){
+		"for-each-ref": func(base string) ([]*BranchStatus, error) {
+			return r.BranchesStatus(base)
+		},
+		"rev-list": func(base string) ([]*BranchStatus, error) {
+			return r.branchesStatusWithRevList(base, BranchesStatusOptions{})
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			branches, err := branchesStatus("master")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			for _, branch := range branches {
+				names = append(names, branch.Name)
+
+				commit, err := r.CatFileCommit(branch.ID.String())
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, commit.Committer.When.Unix(), branch.CommitDate.Unix())
+
+				switch branch.Name {
+				case "master":
+					assert.Equal(t, int64(0), branch.Ahead)
+					assert.Equal(t, int64(0), branch.Behind)
+				case "feature":
+					assert.Equal(t, int64(2), branch.Ahead)
+					assert.Equal(t, int64(1), branch.Behind)
+				}
+			}
+			assert.Contains(t, names, "master")
+			assert.Contains(t, names, "feature")
+
+			_, err = branchesStatus("404")
+			assert.Equal(t, ErrRevisionNotExist, err)
+		})
+	}
+}
+
+func TestParseBranchesStatus(t *testing.T) {
+	data := []byte("refs/heads/feature\x00" + EmptyID + "\x002021-01-02T03:04:05+08:00\x002 1\n" +
+		"refs/heads/master\x00" + EmptyID + "\x002021-01-02T03:04:05Z\x00\n")
+	branches, err := parseBranchesStatus(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !assert.Len(t, branches, 2) {
+		return
+	}
+
+	assert.Equal(t, "feature", branches[0].Name)
+	assert.Equal(t, EmptyID, branches[0].ID.String())
+	assert.Equal(t, int64(2), branches[0].Ahead)
+	assert.Equal(t, int64(1), branches[0].Behind)
+	assert.Equal(t, int64(1609527845), branches[0].CommitDate.Unix())
+
+	assert.Equal(t, "master", branches[1].Name)
+	assert.Equal(t, int64(0), branches[1].Ahead)
+	assert.Equal(t, int64(0), branches[1].Behind)
+
+	_, err = parseBranchesStatus([]byte("refs/heads/master\x00" + EmptyID + "\n"))
+	assert.Error(t, err)
+}
@@ -6,6 +6,11 @@ package git
 
 import (
 	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 )
@@ -31,12 +36,18 @@ func RefShortName(ref string) string {
 type Reference struct {
 	ID      string
 	Refspec string
+	// The ID of the object that an annotated tag points to. It is only set by
+	// methods that report peeled tags, e.g. PackedRefs.
+	Peeled string
 }
 
 // ShowRefVerifyOptions contains optional arguments for verifying a reference.
 //
 // Docs: https://git-scm.com/docs/git-show-ref#Documentation/git-show-ref.txt---verify
 type ShowRefVerifyOptions struct {
+	// Indicates whether to dereference an annotated tag to the object it points
+	// to, e.g. the commit ID rather than the tag object ID.
+	Dereference bool
 	// The timeout duration before giving up for each shell command execution. The
 	// default timeout duration will be used when not supplied.
 	//
@@ -56,15 +67,24 @@ func ShowRefVerify(repoPath, ref string, opts ...ShowRefVerifyOptions) (string,
 		opt = opts[0]
 	}
 
-	cmd := NewCommand("show-ref", "--verify", ref).AddOptions(opt.CommandOptions)
-	stdout, err := cmd.RunInDirWithTimeout(opt.Timeout, repoPath)
+	cmd := NewCommand("show-ref", "--verify").AddOptions(opt.CommandOptions)
+	if opt.Dereference {
+		cmd.AddArgs("--dereference")
+	}
+	stdout, err := cmd.AddArgs(ref).RunInDirWithTimeout(opt.Timeout, repoPath)
 	if err != nil {
 		if strings.Contains(err.Error(), "not a valid ref") {
 			return "", ErrReferenceNotExist
 		}
 		return "", err
 	}
-	return strings.Split(string(stdout), " ")[0], nil
+
+	// The dereferenced "<ref>^{}" line of an annotated tag comes last.
+	lines := bytesToStrings(stdout)
+	if len(lines) == 0 {
+		return "", ErrReferenceNotExist
+	}
+	return strings.Split(lines[len(lines)-1], " ")[0], nil
 }
 
 // Deprecated: Use ShowRefVerify instead.
@@ -151,7 +171,9 @@ type SymbolicRefOptions struct {
 
 // SymbolicRef returns the reference name (e.g. "refs/heads/master") pointed by
 // the symbolic ref in the repository in given path. It returns an empty string
-// and nil error when doing set operation.
+// and nil error when doing set operation. It returns ErrReferenceNotExist if
+// the symbolic ref does not exist or is not a symbolic ref, e.g. HEAD is
+// detached.
 func SymbolicRef(repoPath string, opts ...SymbolicRefOptions) (string, error) {
 	var opt SymbolicRefOptions
 	if len(opts) > 0 {
@@ -169,6 +191,9 @@ func SymbolicRef(repoPath string, opts ...SymbolicRefOptions) (string, error) {
 
 	stdout, err := cmd.RunInDirWithTimeout(opt.Timeout, repoPath)
 	if err != nil {
+		if strings.Contains(err.Error(), "is not a symbolic ref") {
+			return "", ErrReferenceNotExist
+		}
 		return "", err
 	}
 	return strings.TrimSpace(string(stdout)), nil
@@ -176,11 +201,82 @@ func SymbolicRef(repoPath string, opts ...SymbolicRefOptions) (string, error) {
 
 // SymbolicRef returns the reference name (e.g. "refs/heads/master") pointed by
 // the symbolic ref. It returns an empty string and nil error when doing set
-// operation.
+// operation. It returns ErrReferenceNotExist if the symbolic ref does not exist
+// or is not a symbolic ref, e.g. HEAD is detached.
 func (r *Repository) SymbolicRef(opts ...SymbolicRefOptions) (string, error) {
 	return SymbolicRef(r.path, opts...)
 }
 
+// SetSymbolicRefOptions contains optional arguments for setting a symbolic
+// ref.
+//
+// Docs: https://git-scm.com/docs/git-symbolic-ref
+type SetSymbolicRefOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// SetSymbolicRef sets the symbolic ref (e.g. "HEAD") to point to the reference
+// (e.g. "refs/heads/main"), which is how the default branch of a bare
+// repository is changed. The reference does not need to exist.
+func (r *Repository) SetSymbolicRef(name, ref string, opts ...SetSymbolicRefOptions) error {
+	var opt SetSymbolicRefOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	defer r.ClearReachabilityCache()
+
+	_, err := SymbolicRef(r.path, SymbolicRefOptions{
+		Name:           name,
+		Ref:            ref,
+		Timeout:        opt.Timeout, //nolint
+		CommandOptions: opt.CommandOptions,
+	})
+	return err
+}
+
+// CurrentBranchOptions contains optional arguments for getting the current
+// branch.
+//
+// Docs: https://git-scm.com/docs/git-symbolic-ref
+type CurrentBranchOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// CurrentBranch returns the short name (e.g. "master") of the branch that is
+// currently checked out in the working tree of the repository. It returns an
+// ErrDetachedHead when HEAD does not point to a branch.
+func (r *Repository) CurrentBranch(opts ...CurrentBranchOptions) (string, error) {
+	var opt CurrentBranchOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	stdout, err := NewCommand("symbolic-ref").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--short", "HEAD").
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		if strings.Contains(err.Error(), "not a symbolic ref") {
+			return "", ErrDetachedHead
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(stdout)), nil
+}
+
 // ShowRefOptions contains optional arguments for listing references.
 //
 // Docs: https://git-scm.com/docs/git-show-ref
@@ -239,9 +335,62 @@ func (r *Repository) ShowRef(opts ...ShowRefOptions) ([]*Reference, error) {
 	return refs, nil
 }
 
-// Branches returns a list of all branches in the repository.
-func (r *Repository) Branches() ([]string, error) {
-	heads, err := r.ShowRef(ShowRefOptions{Heads: true})
+// BranchesOptions contains optional arguments for listing branches.
+//
+// Docs: https://git-scm.com/docs/git-for-each-ref
+type BranchesOptions struct {
+	// The glob pattern to filter branches by their names, e.g. "feature/*".
+	Pattern string
+	// Indicates whether to sort branches by the committer date of their tip
+	// commits with the newest first. Branches are sorted by their names
+	// otherwise.
+	SortByCommitDate bool
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// BranchReferences returns a list of branches in the repository along with
+// their tip commit IDs.
+func (r *Repository) BranchReferences(opts ...BranchesOptions) ([]*Reference, error) {
+	var opt BranchesOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	cmd := NewCommand("for-each-ref").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--format=%(objectname) %(refname)")
+	if opt.SortByCommitDate {
+		cmd.AddArgs("--sort=-committerdate")
+	}
+	stdout, err := cmd.AddArgs(RefsHeads+opt.Pattern).RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := bytesToStrings(stdout)
+	refs := make([]*Reference, 0, len(lines))
+	for _, line := range lines {
+		fields := strings.SplitN(line, " ", 2)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("malformed reference: %q", line)
+		}
+		refs = append(refs, &Reference{
+			ID:      fields[0],
+			Refspec: fields[1],
+		})
+	}
+	return refs, nil
+}
+
+// Branches returns a list of branches in the repository.
+func (r *Repository) Branches(opts ...BranchesOptions) ([]string, error) {
+	heads, err := r.BranchReferences(opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -253,6 +402,161 @@ func (r *Repository) Branches() ([]string, error) {
 	return branches, nil
 }
 
+// parsePackedRefs parses the content of the "packed-refs" file. The file may
+// start with a header of traits, e.g. "# pack-refs with: peeled fully-peeled
+// sorted", followed by one line of "<id> <refspec>" for each reference, and a
+// line of "^<id>" right after an annotated tag for the object it points to.
+func parsePackedRefs(data []byte) ([]*Reference, error) {
+	refs := []*Reference{}
+	for _, line := range bytesToStrings(data) {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "^") {
+			if len(refs) == 0 || refs[len(refs)-1].Peeled != "" {
+				return nil, fmt.Errorf("unexpected peeled line: %q", line)
+			}
+			refs[len(refs)-1].Peeled = line[1:]
+			continue
+		}
+
+		fields := strings.SplitN(line, " ", 2)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("malformed reference: %q", line)
+		}
+		refs = append(refs, &Reference{
+			ID:      fields[0],
+			Refspec: fields[1],
+		})
+	}
+	return refs, nil
+}
+
+// PackedRefs returns the references in the "packed-refs" file of the
+// repository, with the peeled object IDs of annotated tags when recorded. It
+// reads the file directly without executing any Git command, thus references
+// that are only stored as loose files are not included. It returns an empty
+// list if the file does not exist.
+func (r *Repository) PackedRefs() ([]*Reference, error) {
+	// A linked worktree shares references with the main repository.
+	dir, err := r.commonDir()
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := ioutil.ReadFile(filepath.Join(dir, "packed-refs"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*Reference{}, nil
+		}
+		return nil, err
+	}
+	return parsePackedRefs(p)
+}
+
+// CreateBranchOptions contains optional arguments for creating a branch.
+//
+// Docs: https://git-scm.com/docs/git-branch
+type CreateBranchOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// CreateBranch creates a new branch that points to the revision. It returns
+// ErrBranchExisted if the branch already exists, or ErrRevisionNotExist if the
+// revision does not exist.
+func (r *Repository) CreateBranch(name, rev string, opts ...CreateBranchOptions) error {
+	var opt CreateBranchOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	defer r.ClearReachabilityCache()
+
+	// 🚨 SECURITY: Prevent including unintended options in the path to the Git command.
+	_, err := NewCommand("branch").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--end-of-options", name, rev).
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			return ErrBranchExisted
+		} else if strings.Contains(err.Error(), "not a valid object name") {
+			return ErrRevisionNotExist
+		}
+		return err
+	}
+	return nil
+}
+
+// UpdateRefOptions contains optional arguments for updating a reference.
+//
+// Docs: https://git-scm.com/docs/git-update-ref
+type UpdateRefOptions struct {
+	// The expected current value of the reference, the update is rejected with
+	// ErrRefUpdateRejected if the reference is not at the value. Use EmptyID to
+	// expect the reference to not exist. The reference is updated regardless of
+	// its current value when not set.
+	OldValue string
+	// Indicates whether to delete the reference, the new value is ignored when
+	// set.
+	Delete bool
+	// The message to be recorded in the reflog for the update.
+	Message string
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// UpdateRef updates the reference (e.g. "refs/heads/master") to the new value
+// atomically, and the reference is created if it does not exist. It returns
+// ErrRefUpdateRejected if UpdateRefOptions.OldValue is set but does not match
+// the current value of the reference, e.g. it has been updated concurrently.
+func (r *Repository) UpdateRef(ref, newValue string, opts ...UpdateRefOptions) error {
+	defer r.ClearReachabilityCache()
+
+	var opt UpdateRefOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	cmd := NewCommand("update-ref").AddOptions(opt.CommandOptions)
+	if opt.Delete {
+		cmd.AddArgs("-d")
+	}
+	if opt.Message != "" {
+		cmd.AddArgs("-m", opt.Message)
+	}
+	// 🚨 SECURITY: Prevent including unintended options in the path to the Git command.
+	cmd.AddArgs("--end-of-options", ref)
+	if !opt.Delete {
+		cmd.AddArgs(newValue)
+	}
+	if opt.OldValue != "" {
+		cmd.AddArgs(opt.OldValue)
+	}
+
+	_, err := cmd.RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil && opt.OldValue != "" {
+		for _, reason := range []string{"but expected", "reference already exists", "unable to resolve reference"} {
+			if strings.Contains(err.Error(), reason) {
+				return ErrRefUpdateRejected
+			}
+		}
+	}
+	return err
+}
+
 // DeleteBranchOptions contains optional arguments for deleting a branch.
 //
 // Docs: https://git-scm.com/docs/git-branch
@@ -268,7 +572,8 @@ type DeleteBranchOptions struct {
 	CommandOptions
 }
 
-// DeleteBranch deletes the branch from the repository in given path.
+// DeleteBranch deletes the branch from the repository in given path. It returns
+// ErrBranchNotExist if the branch does not exist.
 func DeleteBranch(repoPath, name string, opts ...DeleteBranchOptions) error {
 	var opt DeleteBranchOptions
 	if len(opts) > 0 {
@@ -282,6 +587,9 @@ func DeleteBranch(repoPath, name string, opts ...DeleteBranchOptions) error {
 		cmd.AddArgs("-d")
 	}
 	_, err := cmd.AddArgs(name).RunInDirWithTimeout(opt.Timeout, repoPath)
+	if err != nil && strings.Contains(err.Error(), "not found") {
+		return ErrBranchNotExist
+	}
 	return err
 }
 
@@ -290,7 +598,118 @@ func RepoDeleteBranch(repoPath, name string, opts ...DeleteBranchOptions) error
 	return DeleteBranch(repoPath, name, opts...)
 }
 
-// DeleteBranch deletes the branch from the repository.
+// DeleteBranch deletes the branch from the repository. It returns
+// ErrBranchNotExist if the branch does not exist.
 func (r *Repository) DeleteBranch(name string, opts ...DeleteBranchOptions) error {
+	defer r.ClearReachabilityCache()
 	return DeleteBranch(r.path, name, opts...)
 }
+
+// SetUpstreamOptions contains optional arguments for setting the upstream of a
+// branch.
+//
+// Docs: https://git-scm.com/docs/git-branch#Documentation/git-branch.txt---set-upstream-toltupstreamgt
+type SetUpstreamOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// SetUpstream sets the branch to track the branch of the remote. It returns
+// ErrRemoteBranchNotExist if the remote-tracking branch does not exist, e.g. it
+// has not been fetched yet.
+func (r *Repository) SetUpstream(branch, remote, remoteBranch string, opts ...SetUpstreamOptions) error {
+	var opt SetUpstreamOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	// Use the full name of the remote-tracking branch so that it is never
+	// ambiguous with a local branch of the same name.
+	_, err := NewCommand("branch").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--set-upstream-to=refs/remotes/"+remote+"/"+remoteBranch, branch).
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil && strings.Contains(err.Error(), "requested upstream branch") {
+		return ErrRemoteBranchNotExist
+	}
+	return err
+}
+
+// UnsetUpstreamOptions contains optional arguments for unsetting the upstream
+// of a branch.
+//
+// Docs: https://git-scm.com/docs/git-branch#Documentation/git-branch.txt---unset-upstream
+type UnsetUpstreamOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// UnsetUpstream removes the upstream of the branch. It returns ErrNoUpstream if
+// the branch has no upstream.
+func (r *Repository) UnsetUpstream(branch string, opts ...UnsetUpstreamOptions) error {
+	var opt UnsetUpstreamOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	_, err := NewCommand("branch").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--unset-upstream", branch).
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil && strings.Contains(err.Error(), "has no upstream information") {
+		return ErrNoUpstream
+	}
+	return err
+}
+
+// UpstreamOptions contains optional arguments for getting the upstream of a
+// branch.
+//
+// Docs: https://git-scm.com/docs/git-config#Documentation/git-config.txt-branchltnamegtmerge
+type UpstreamOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// Upstream returns the remote and the branch of the remote that the branch
+// tracks. The remote is "." when the upstream is a local branch. It returns
+// ErrNoUpstream if the branch has no upstream.
+func (r *Repository) Upstream(branch string, opts ...UpstreamOptions) (remote, remoteBranch string, _ error) {
+	var opt UpstreamOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	// Read the configuration instead of parsing "<branch>@{upstream}", which can
+	// not tell where the name of the remote ends if it contains slashes.
+	prefix := "branch." + branch + "."
+	entries, err := r.ConfigEntries(`^branch\.`+regexp.QuoteMeta(branch)+`\.(remote|merge)$`, ConfigEntriesOptions{
+		Timeout:        opt.Timeout, //nolint
+		CommandOptions: opt.CommandOptions,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	remote = entries[prefix+"remote"]
+	merge := entries[prefix+"merge"]
+	if remote == "" || merge == "" {
+		return "", "", ErrNoUpstream
+	}
+	return remote, strings.TrimPrefix(merge, RefsHeads), nil
+}
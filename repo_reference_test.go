@@ -5,7 +5,9 @@
 package git
 
 import (
+	"os"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -50,6 +52,40 @@ func TestRepository_ShowRefVerify(t *testing.T) {
 	}
 
 	assert.Equal(t, "0eedd79eba4394bbef888c804e899731644367fe", rev)
+
+	t.Run("dereference", func(t *testing.T) {
+		tagID, err := testrepo.RevParse("v1.1.0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		commitID, err := testrepo.RevParse("v1.1.0^{commit}")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rev, err := testrepo.ShowRefVerify("refs/tags/v1.1.0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, tagID, rev)
+
+		rev, err = testrepo.ShowRefVerify("refs/tags/v1.1.0", ShowRefVerifyOptions{Dereference: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, commitID, rev)
+
+		// References that are not annotated tags are returned as is.
+		branchID, err := testrepo.RevParse("release-1.0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		rev, err = testrepo.ShowRefVerify("refs/heads/release-1.0", ShowRefVerifyOptions{Dereference: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, branchID, rev)
+	})
 }
 
 func TestRepository_BranchCommitID(t *testing.T) {
@@ -183,6 +219,70 @@ func TestRepository_SymbolicRef(t *testing.T) {
 		t.Fatal(err)
 	}
 	assert.Equal(t, RefsHeads+"develop", ref)
+
+	// Get a symbolic reference that does not exist
+	_, err = r.SymbolicRef(SymbolicRefOptions{
+		Name: "404",
+	})
+	assert.Equal(t, ErrReferenceNotExist, err)
+}
+
+func TestRepository_SetSymbolicRef(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	err = r.SetSymbolicRef("HEAD", RefsHeads+"main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := r.SymbolicRef()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, RefsHeads+"main", ref)
+
+	// Detach HEAD
+	masterID, err := r.RevParse("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.UpdateRef("HEAD", masterID, UpdateRefOptions{CommandOptions: CommandOptions{Args: []string{"--no-deref"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.SymbolicRef()
+	assert.Equal(t, ErrReferenceNotExist, err)
+}
+
+func TestRepository_CurrentBranch(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	branch, err := r.CurrentBranch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "master", branch)
+
+	t.Run("detached HEAD", func(t *testing.T) {
+		id, err := r.RevParse("HEAD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = r.Checkout(id); err != nil {
+			t.Fatal(err)
+		}
+
+		branch, err := r.CurrentBranch()
+		assert.Equal(t, ErrDetachedHead, err)
+		assert.Empty(t, branch)
+	})
 }
 
 func TestRepository_ShowRef(t *testing.T) {
@@ -245,6 +345,303 @@ func TestRepository_Branches(t *testing.T) {
 	}
 }
 
+func TestRepository_Branches_options(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	for _, branch := range []string{"feature/b", "feature/a", "fix"} {
+		err = r.Checkout(branch, CheckoutOptions{BaseBranch: "master"})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Make "feature/b" the most recently committed branch.
+	err = r.Checkout("feature/b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewCommand("commit", "--allow-empty", "--message=Empty", "--date=2099-01-01T00:00:00+00:00").
+		AddEnvs("GIT_COMMITTER_DATE=2099-01-01T00:00:00+00:00").
+		RunInDir(r.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		opt         BranchesOptions
+		expBranches []string
+	}{
+		{
+			expBranches: []string{"feature/a", "feature/b", "fix", "master"},
+		},
+		{
+			opt: BranchesOptions{
+				Pattern: "feature/*",
+			},
+			expBranches: []string{"feature/a", "feature/b"},
+		},
+		{
+			opt: BranchesOptions{
+				SortByCommitDate: true,
+			},
+			expBranches: []string{"feature/b"},
+		},
+		{
+			opt: BranchesOptions{
+				Pattern: "404",
+			},
+			expBranches: []string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			branches, err := r.Branches(test.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if test.opt.SortByCommitDate {
+				branches = branches[:1]
+			}
+			assert.Equal(t, test.expBranches, branches)
+		})
+	}
+
+	t.Run("tip commit IDs", func(t *testing.T) {
+		refs, err := r.BranchReferences(BranchesOptions{Pattern: "feature/b"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		id, err := r.RevParse("feature/b")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, []*Reference{{ID: id, Refspec: RefsHeads + "feature/b"}}, refs)
+	})
+}
+
+func Test_parsePackedRefs(t *testing.T) {
+	const (
+		id1 = "1111111111111111111111111111111111111111"
+		id2 = "2222222222222222222222222222222222222222"
+		id3 = "3333333333333333333333333333333333333333"
+	)
+	data := `# pack-refs with: peeled fully-peeled sorted 
+` + id1 + ` refs/heads/master
+` + id2 + ` refs/tags/v1.0.0
+^` + id3 + `
+` + id1 + ` refs/tags/v1.0.1
+`
+	refs, err := parsePackedRefs([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []*Reference{
+		{ID: id1, Refspec: "refs/heads/master"},
+		{ID: id2, Refspec: "refs/tags/v1.0.0", Peeled: id3},
+		{ID: id1, Refspec: "refs/tags/v1.0.1"},
+	}, refs)
+
+	for _, data := range []string{
+		"^" + id3 + "\n",
+		id2 + " refs/tags/v1.0.0\n^" + id3 + "\n^" + id3 + "\n",
+		id1 + "\n",
+	} {
+		_, err = parsePackedRefs([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestRepository_PackedRefs(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	err = r.CreateTag("annotated", "master", CreateTagOptions{
+		Annotated: true,
+		Message:   "Annotated tag",
+		Author: &Signature{
+			Name:  "alice",
+			Email: "alice@example.com",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tagID, err := r.ShowRefVerify(RefsTags + "annotated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitID, err := r.RevParse("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = NewCommand("pack-refs", "--all").RunInDir(r.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refs, err := r.PackedRefs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, refs, &Reference{ID: commitID, Refspec: RefsHeads + "master"})
+	assert.Contains(t, refs, &Reference{ID: tagID, Refspec: RefsTags + "annotated", Peeled: commitID})
+
+	t.Run("no packed-refs", func(t *testing.T) {
+		path := tempPath()
+		defer func() {
+			_ = os.RemoveAll(path)
+		}()
+
+		err := Init(path, InitOptions{Bare: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		refs, err := r.PackedRefs()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Empty(t, refs)
+	})
+}
+
+func TestRepository_CreateBranch(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	id, err := r.RevParse("master~1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.CreateBranch("created", "master~1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.BranchCommitID("created")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, id, got)
+
+	t.Run("branch already exists", func(t *testing.T) {
+		err := r.CreateBranch("created", "master")
+		assert.Equal(t, ErrBranchExisted, err)
+	})
+
+	t.Run("revision does not exist", func(t *testing.T) {
+		err := r.CreateBranch("bad", "404")
+		assert.Equal(t, ErrRevisionNotExist, err)
+	})
+}
+
+func TestRepository_UpdateRef(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	masterID, err := r.RevParse("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parentID, err := r.RevParse("master~1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const ref = RefsHeads + "update-ref"
+
+	t.Run("create", func(t *testing.T) {
+		err := r.UpdateRef(ref, parentID, UpdateRefOptions{
+			OldValue: EmptyID,
+			Message:  "create update-ref",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, err := r.RevParse(ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, parentID, id)
+
+		stdout, err := NewCommand("log", "-g", "--format=%gs", ref).RunInDir(r.Path())
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "create update-ref\n", string(stdout))
+
+		// The reference exists now.
+		err = r.UpdateRef(ref, masterID, UpdateRefOptions{OldValue: EmptyID})
+		assert.Equal(t, ErrRefUpdateRejected, err)
+	})
+
+	t.Run("compare and swap", func(t *testing.T) {
+		err := r.UpdateRef(ref, masterID, UpdateRefOptions{OldValue: masterID})
+		assert.Equal(t, ErrRefUpdateRejected, err)
+
+		err = r.UpdateRef(ref, masterID, UpdateRefOptions{OldValue: parentID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, err := r.RevParse(ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, masterID, id)
+	})
+
+	t.Run("unconditional", func(t *testing.T) {
+		err := r.UpdateRef(ref, parentID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, err := r.RevParse(ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, parentID, id)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		err := r.UpdateRef(ref, "", UpdateRefOptions{Delete: true, OldValue: masterID})
+		assert.Equal(t, ErrRefUpdateRejected, err)
+
+		err = r.UpdateRef(ref, "", UpdateRefOptions{Delete: true, OldValue: parentID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.False(t, r.HasReference(ref))
+
+		err = r.UpdateRef(ref, "", UpdateRefOptions{Delete: true, OldValue: parentID})
+		assert.Equal(t, ErrRefUpdateRejected, err)
+	})
+
+	t.Run("bad value", func(t *testing.T) {
+		err := r.UpdateRef(ref, "404")
+		assert.Error(t, err)
+		assert.NotEqual(t, ErrRefUpdateRejected, err)
+	})
+}
+
 func TestRepository_DeleteBranch(t *testing.T) {
 	r, cleanup, err := setupTempRepo()
 	if err != nil {
@@ -291,4 +688,90 @@ func TestRepository_DeleteBranch(t *testing.T) {
 			assert.False(t, r.HasReference(RefsHeads+branch))
 		})
 	}
+
+	t.Run("branch does not exist", func(t *testing.T) {
+		err := r.DeleteBranch("404")
+		assert.Equal(t, ErrBranchNotExist, err)
+	})
+}
+
+func TestRepository_SetUpstream(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	getConfig := func(key string) string {
+		stdout, _ := NewCommand("config", "--get", key).RunInDir(r.Path())
+		return strings.TrimSpace(string(stdout))
+	}
+
+	err = r.SetUpstream("master", "origin", "develop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "origin", getConfig("branch.master.remote"))
+	assert.Equal(t, "refs/heads/develop", getConfig("branch.master.merge"))
+
+	err = r.UnsetUpstream("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, getConfig("branch.master.remote"))
+	assert.Empty(t, getConfig("branch.master.merge"))
+
+	err = r.UnsetUpstream("master")
+	assert.Equal(t, ErrNoUpstream, err)
+
+	t.Run("remote branch does not exist", func(t *testing.T) {
+		err := r.SetUpstream("master", "origin", "404")
+		assert.Equal(t, ErrRemoteBranchNotExist, err)
+	})
+}
+
+func TestRepository_Upstream(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	remote, remoteBranch, err := r.Upstream("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "origin", remote)
+	assert.Equal(t, "master", remoteBranch)
+
+	// The name of the remote and the branch both contain slashes.
+	err = r.RemoteAdd("team/fork", "https://example.com/team/fork.git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewCommand("update-ref", "refs/remotes/team/fork/feature/x", "HEAD").RunInDir(r.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.SetUpstream("master", "team/fork", "feature/x")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remote, remoteBranch, err = r.Upstream("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "team/fork", remote)
+	assert.Equal(t, "feature/x", remoteBranch)
+
+	t.Run("no upstream", func(t *testing.T) {
+		err := r.Checkout("no-upstream", CheckoutOptions{BaseBranch: "master"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, _, err = r.Upstream("no-upstream")
+		assert.Equal(t, ErrNoUpstream, err)
+	})
 }
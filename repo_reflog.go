@@ -0,0 +1,130 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReflogEntry is an entry of the reflog of a reference.
+type ReflogEntry struct {
+	// The ID that the reference pointed to before the update, which is EmptyID
+	// when the reference was created or it is the oldest entry of the reflog.
+	OldID string
+	// The ID that the reference pointed to after the update.
+	NewID string
+	// The selector of the entry, e.g. "master@{0}" for the latest one.
+	Selector string
+	// The kind of the update, e.g. "commit", "commit (amend)" or "reset".
+	Action string
+	// The full message of the update, e.g. "reset: moving to HEAD~1".
+	Message string
+	// The identity that made the update, and when.
+	Committer *Signature
+}
+
+// ReflogOptions contains optional arguments for reading the reflog.
+//
+// Docs: https://git-scm.com/docs/git-reflog
+type ReflogOptions struct {
+	// The maximum number of entries to return, all entries are returned when not
+	// set.
+	MaxCount int
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// parseReflog parses the output of "git log -g --date=raw
+// --format=%H%x00%gd%x00%gn%x00%ge%x00%gs" in the newest first order, where
+// the selector is in the form of "<name>@{<timestamp> <timezone>}". The old ID
+// of each entry is the new ID of the next older entry, hence one more entry
+// than maxCount should be given to derive the old ID of the last returned
+// entry. The name is used to form the selectors of entries.
+func parseReflog(data []byte, name string, maxCount int) ([]*ReflogEntry, error) {
+	lines := bytesToStrings(data)
+	entries := make([]*ReflogEntry, 0, len(lines))
+	for _, line := range lines {
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("malformed reflog entry: %q", line)
+		}
+
+		i := strings.LastIndex(fields[1], "@{")
+		if i < 0 || !strings.HasSuffix(fields[1], "}") {
+			return nil, fmt.Errorf("malformed reflog selector: %q", line)
+		}
+		date := fields[1][i+2 : len(fields[1])-1]
+		committer, err := parseSignature([]byte(fields[2] + " <" + fields[3] + "> " + date))
+		if err != nil {
+			return nil, fmt.Errorf("parse committer of %q: %v", line, err)
+		}
+
+		message := fields[4]
+		action := message
+		if j := strings.Index(message, ": "); j >= 0 {
+			action = message[:j]
+		}
+		if len(entries) > 0 {
+			entries[len(entries)-1].OldID = fields[0]
+		}
+		entries = append(entries, &ReflogEntry{
+			OldID:     EmptyID,
+			NewID:     fields[0],
+			Selector:  fmt.Sprintf("%s@{%d}", name, len(entries)),
+			Action:    action,
+			Message:   message,
+			Committer: committer,
+		})
+	}
+	if maxCount > 0 && len(entries) > maxCount {
+		entries = entries[:maxCount]
+	}
+	return entries, nil
+}
+
+// Reflog returns the entries of the reflog of the reference (e.g. "HEAD",
+// "master" or "refs/heads/master") in the newest first order, and returns an
+// empty list if the reference has no reflog. It returns ErrReferenceNotExist if
+// the reference cannot be resolved.
+func (r *Repository) Reflog(ref string, opts ...ReflogOptions) ([]*ReflogEntry, error) {
+	var opt ReflogOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	// HEAD is per worktree and may be detached, which is not a symbolic name.
+	refname := ref
+	if ref != "HEAD" {
+		stdout, err := NewCommand("rev-parse", "--verify", "--symbolic-full-name").
+			AddOptions(opt.CommandOptions).
+			AddArgs(ref).
+			RunInDirWithTimeout(opt.Timeout, r.path)
+		refname = strings.TrimSpace(string(stdout))
+		if err != nil || !strings.HasPrefix(refname, "refs/") {
+			return nil, ErrReferenceNotExist
+		}
+	}
+
+	cmd := NewCommand("log", "-g", "--date=raw", "--format=%H%x00%gd%x00%gn%x00%ge%x00%gs").
+		AddOptions(opt.CommandOptions)
+	if opt.MaxCount > 0 {
+		// One more entry is needed to derive the old ID of the last entry.
+		cmd.AddArgs("--max-count=" + strconv.Itoa(opt.MaxCount+1))
+	}
+	// 🚨 SECURITY: Prevent including unintended options in the path to the Git command.
+	stdout, err := cmd.AddArgs("--end-of-options", refname, "--").RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		return nil, err
+	}
+	return parseReflog(stdout, ref, opt.MaxCount)
+}
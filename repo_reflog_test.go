@@ -0,0 +1,119 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseReflog(t *testing.T) {
+	const (
+		id1 = "755fd577edcfd9209d0ac072eed3b022cbe4d39b"
+		id2 = "978fb7f6388b49b532fbef8b856681cfa6fcaa0a"
+	)
+	data := []byte(id1 + "\x00master@{1600000200 +0800}\x00alice\x00alice@example.com\x00\n" +
+		id2 + "\x00master@{1600000100 +0000}\x00bob\x00bob@example.com\x00commit (amend): Fix typo\n" +
+		id1 + "\x00master@{1600000000 +0800}\x00alice\x00alice@example.com\x00branch: Created from HEAD\n")
+
+	entries, err := parseReflog(data, "master", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !assert.Len(t, entries, 3) {
+		return
+	}
+
+	assert.Equal(t, id2, entries[0].OldID)
+	assert.Equal(t, id1, entries[0].NewID)
+	assert.Equal(t, "master@{0}", entries[0].Selector)
+	assert.Equal(t, "", entries[0].Action)
+	assert.Equal(t, "", entries[0].Message)
+
+	assert.Equal(t, "master@{1}", entries[1].Selector)
+	assert.Equal(t, "commit (amend)", entries[1].Action)
+	assert.Equal(t, "commit (amend): Fix typo", entries[1].Message)
+	assert.Equal(t, "bob", entries[1].Committer.Name)
+	assert.Equal(t, int64(1600000100), entries[1].Committer.When.Unix())
+
+	assert.Equal(t, EmptyID, entries[2].OldID)
+	assert.Equal(t, "branch", entries[2].Action)
+
+	entries, err = parseReflog(data, "master", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, id1, entries[1].OldID)
+	}
+
+	for _, data := range []string{
+		"malformed\n",
+		id1 + "\x00master\x00alice\x00alice@example.com\x00message\n",
+	} {
+		_, err = parseReflog([]byte(data), "master", 0)
+		assert.Error(t, err, data)
+	}
+}
+
+func TestRepository_Reflog(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	before, err := r.RevParse("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = commitFile(r, "reflog.txt", "reflog", "Add reflog.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err := r.RevParse("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ref := range []string{"master", "refs/heads/master", "HEAD"} {
+		t.Run(ref, func(t *testing.T) {
+			entries, err := r.Reflog(ref, ReflogOptions{MaxCount: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !assert.Len(t, entries, 1) {
+				return
+			}
+			assert.Equal(t, before, entries[0].OldID)
+			assert.Equal(t, after, entries[0].NewID)
+			assert.Equal(t, ref+"@{0}", entries[0].Selector)
+			assert.Equal(t, "commit", entries[0].Action)
+			assert.Equal(t, "commit: Add reflog.txt", entries[0].Message)
+		})
+	}
+
+	// The first entry is the creation of the branch by the clone.
+	entries, err := r.Reflog("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, EmptyID, entries[1].OldID)
+		assert.Equal(t, before, entries[1].NewID)
+		assert.Equal(t, "clone", entries[1].Action)
+	}
+
+	// A reference without reflog
+	entries, err = r.Reflog("v1.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, entries)
+
+	_, err = r.Reflog("404")
+	assert.Equal(t, ErrReferenceNotExist, err)
+}
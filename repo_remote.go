@@ -32,7 +32,73 @@ type LsRemoteOptions struct {
 	CommandOptions
 }
 
-// LsRemote returns a list references in the remote repository.
+// remoteErrors maps the messages printed by Git and remote hosts to the typed
+// errors of accessing a remote repository. Authentication failures are matched
+// first because they are often followed by more general messages.
+var remoteErrors = []struct {
+	err      error
+	messages []string
+}{
+	{
+		err: ErrRemoteAuthRequired,
+		messages: []string{
+			"Authentication failed",
+			"could not read Username",
+			"could not read Password",
+			"terminal prompts disabled",
+			"Permission denied (publickey",
+			"HTTP Basic: Access denied",
+			"The requested URL returned error: 401",
+			"The requested URL returned error: 403",
+		},
+	},
+	{
+		err: ErrRemoteNotFound,
+		messages: []string{
+			"does not appear to be a git repository",
+			"Repository not found",
+			"could not be found",
+			"The requested URL returned error: 404",
+		},
+	},
+	{
+		err: ErrRemoteUnreachable,
+		messages: []string{
+			"Could not resolve host",
+			"Failed to connect to",
+			"Connection refused",
+			"Connection timed out",
+			"Operation timed out",
+			"Network is unreachable",
+			"No route to host",
+		},
+	},
+}
+
+// remoteError returns a *RemoteError for the error of accessing a remote
+// repository, or the error itself when it cannot be classified.
+func remoteError(err error) error {
+	msg := err.Error()
+	for _, e := range remoteErrors {
+		for _, m := range e.messages {
+			if strings.Contains(msg, m) {
+				return &RemoteError{Reason: e.err, Err: err}
+			}
+		}
+	}
+	// HTTP transport reports a missing repository as "repository '<url>' not
+	// found".
+	if strings.Contains(msg, "fatal: repository '") && strings.Contains(msg, "' not found") {
+		return &RemoteError{Reason: ErrRemoteNotFound, Err: err}
+	}
+	return err
+}
+
+// LsRemote returns a list references in the remote repository. It returns a
+// *RemoteError that unwraps to ErrRemoteAuthRequired if the remote requires
+// authentication, ErrRemoteNotFound if the remote repository does not exist, or
+// ErrRemoteUnreachable if the host of the remote cannot be reached. Other
+// errors are returned as is.
 func LsRemote(url string, opts ...LsRemoteOptions) ([]*Reference, error) {
 	var opt LsRemoteOptions
 	if len(opts) > 0 {
@@ -56,7 +122,7 @@ func LsRemote(url string, opts ...LsRemoteOptions) ([]*Reference, error) {
 
 	stdout, err := cmd.RunWithTimeout(opt.Timeout)
 	if err != nil {
-		return nil, err
+		return nil, remoteError(err)
 	}
 
 	lines := bytes.Split(stdout, []byte("\n"))
@@ -75,6 +141,49 @@ func LsRemote(url string, opts ...LsRemoteOptions) ([]*Reference, error) {
 	return refs, nil
 }
 
+// LsRemoteRefOptions contains arguments for getting a single reference in a
+// remote repository.
+//
+// Docs: https://git-scm.com/docs/git-ls-remote
+type LsRemoteRefOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// LsRemoteRef returns the object ID of given reference in the remote
+// repository. The reference must be given in full refspec (e.g.
+// "refs/heads/master") or "HEAD", and it is matched exactly. It returns an
+// ErrReferenceNotExist if the reference does not exist.
+func LsRemoteRef(url, ref string, opts ...LsRemoteRefOptions) (string, error) {
+	var opt LsRemoteRefOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	refs, err := LsRemote(url, LsRemoteOptions{
+		Patterns:       []string{ref},
+		Timeout:        opt.Timeout, //nolint
+		CommandOptions: opt.CommandOptions,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// Patterns are matched by the tail of references, e.g. "master" matches both
+	// "refs/heads/master" and "refs/remotes/origin/master".
+	for _, r := range refs {
+		if r.Refspec == ref {
+			return r.ID, nil
+		}
+	}
+	return "", ErrReferenceNotExist
+}
+
 // IsURLAccessible returns true if given remote URL is accessible via Git within
 // given timeout.
 func IsURLAccessible(timeout time.Duration, url string) bool {
@@ -132,11 +241,13 @@ func RepoAddRemote(repoPath, name, url string, opts ...RemoteAddOptions) error {
 
 // RemoteAdd adds a new remote to the repository.
 func (r *Repository) RemoteAdd(name, url string, opts ...RemoteAddOptions) error {
+	defer r.ClearReachabilityCache()
 	return RemoteAdd(r.path, name, url, opts...)
 }
 
 // Deprecated: Use RemoteAdd instead.
 func (r *Repository) AddRemote(name, url string, opts ...RemoteAddOptions) error {
+	defer r.ClearReachabilityCache()
 	return RemoteAdd(r.path, name, url, opts...)
 }
 
@@ -186,11 +297,13 @@ func RepoRemoveRemote(repoPath, name string, opts ...RemoteRemoveOptions) error
 
 // RemoteRemove removes a remote from the repository.
 func (r *Repository) RemoteRemove(name string, opts ...RemoteRemoveOptions) error {
+	defer r.ClearReachabilityCache()
 	return RemoteRemove(r.path, name, opts...)
 }
 
 // Deprecated: Use RemoteRemove instead.
 func (r *Repository) RemoveRemote(name string, opts ...RemoteRemoveOptions) error {
+	defer r.ClearReachabilityCache()
 	return RemoteRemove(r.path, name, opts...)
 }
 
@@ -5,6 +5,7 @@
 package git
 
 import (
+	"errors"
 	"os"
 	"testing"
 
@@ -67,6 +68,95 @@ func TestLsRemote(t *testing.T) {
 	}
 }
 
+func TestLsRemoteRef(t *testing.T) {
+	t.Run("reference does not exist", func(t *testing.T) {
+		id, err := LsRemoteRef(testrepo.Path(), "refs/heads/404")
+		assert.Equal(t, ErrReferenceNotExist, err)
+		assert.Empty(t, id)
+	})
+
+	t.Run("short name is not matched", func(t *testing.T) {
+		id, err := LsRemoteRef(testrepo.Path(), "master")
+		assert.Equal(t, ErrReferenceNotExist, err)
+		assert.Empty(t, id)
+	})
+
+	expID, err := testrepo.BranchCommitID("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := LsRemoteRef(testrepo.Path(), RefsHeads+"master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, expID, id)
+}
+
+func Test_remoteError(t *testing.T) {
+	rawErr := errors.New("exit status 128 - fatal: protocol error: bad line length character")
+	tests := []struct {
+		stderr string
+		expErr error
+	}{
+		{
+			stderr: "fatal: could not read Username for 'https://example.com': terminal prompts disabled",
+			expErr: ErrRemoteAuthRequired,
+		},
+		{
+			stderr: "remote: Invalid username or password.\nfatal: Authentication failed for 'https://example.com/repo.git/'",
+			expErr: ErrRemoteAuthRequired,
+		},
+		{
+			stderr: "git@example.com: Permission denied (publickey).\nfatal: Could not read from remote repository.",
+			expErr: ErrRemoteAuthRequired,
+		},
+		{
+			stderr: "ERROR: Repository not found.\nfatal: Could not read from remote repository.",
+			expErr: ErrRemoteNotFound,
+		},
+		{
+			stderr: "remote: Not Found\nfatal: repository 'https://example.com/repo.git/' not found",
+			expErr: ErrRemoteNotFound,
+		},
+		{
+			stderr: "fatal: '/tmp' does not appear to be a git repository",
+			expErr: ErrRemoteNotFound,
+		},
+		{
+			stderr: "fatal: unable to access 'https://example.com/repo.git/': Could not resolve host: example.com",
+			expErr: ErrRemoteUnreachable,
+		},
+		{
+			stderr: "ssh: connect to host example.com port 22: Connection refused",
+			expErr: ErrRemoteUnreachable,
+		},
+	}
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			err := &CommandError{Err: errors.New("exit status 128"), Stderr: test.stderr}
+			assert.Equal(t, &RemoteError{Reason: test.expErr, Err: err}, remoteError(err))
+			assert.True(t, errors.Is(remoteError(err), test.expErr))
+		})
+	}
+
+	assert.Equal(t, rawErr, remoteError(rawErr))
+}
+
+func TestLsRemote_errors(t *testing.T) {
+	_, err := LsRemote(os.TempDir())
+	assert.True(t, errors.Is(err, ErrRemoteNotFound))
+
+	_, err = LsRemote("http://127.0.0.1:1/repo.git")
+	assert.True(t, errors.Is(err, ErrRemoteUnreachable))
+
+	// The stderr of the command is kept.
+	var remoteErr *RemoteError
+	if assert.True(t, errors.As(err, &remoteErr)) {
+		assert.Contains(t, remoteErr.Err.Error(), "127.0.0.1")
+	}
+}
+
 func TestIsURLAccessible(t *testing.T) {
 	tests := []struct {
 		url    string
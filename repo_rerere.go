@@ -0,0 +1,74 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"time"
+)
+
+// RerereOptions contains optional arguments for managing recorded conflict
+// resolutions.
+//
+// Docs: https://git-scm.com/docs/git-rerere
+type RerereOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// RerereStatus returns the paths with conflicts of the merge in progress that
+// rerere tracks, i.e. whose resolutions are recorded once resolved, or are
+// already resolved using previously recorded resolutions. Rerere is only in
+// effect when the "rerere.enabled" configuration is true. It returns an empty
+// list when there is nothing tracked.
+func (r *Repository) RerereStatus(opts ...RerereOptions) ([]string, error) {
+	var opt RerereOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	stdout, err := NewCommand("rerere", "status").
+		AddOptions(opt.CommandOptions).
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		return nil, err
+	}
+	return bytesToStrings(stdout), nil
+}
+
+// RerereClear discards the metadata of conflicts that rerere tracks for the
+// merge in progress, e.g. when aborting the merge. Resolutions that have been
+// recorded are kept.
+func (r *Repository) RerereClear(opts ...RerereOptions) error {
+	var opt RerereOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	_, err := NewCommand("rerere", "clear").
+		AddOptions(opt.CommandOptions).
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	return err
+}
+
+// RerereForget removes the recorded resolutions for the conflicts in given path
+// of the merge in progress, e.g. when a recorded resolution turns out to be
+// wrong.
+func (r *Repository) RerereForget(path string, opts ...RerereOptions) error {
+	var opt RerereOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	_, err := NewCommand("rerere", "forget").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--", path).
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	return err
+}
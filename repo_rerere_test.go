@@ -0,0 +1,100 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_Rerere(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	_, err = NewCommand("config", "rerere.enabled", "true").RunInDir(r.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	branch, err := commitConflict(r, "rerere.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := r.RevParse("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	merge := func() {
+		_, err := NewCommand("merge", branch).RunInDir(r.Path())
+		assert.Error(t, err)
+	}
+	readFile := func() string {
+		p, err := ioutil.ReadFile(filepath.Join(r.Path(), "rerere.txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(p)
+	}
+	reset := func() {
+		_, err := NewCommand("reset", "--hard", head).RunInDir(r.Path())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("nothing tracked", func(t *testing.T) {
+		paths, err := r.RerereStatus()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Empty(t, paths)
+	})
+
+	// Record a resolution of the conflict.
+	merge()
+	paths, err := r.RerereStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"rerere.txt"}, paths)
+
+	err = commitFile(r, "rerere.txt", "resolved\n", "Merge "+branch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reset()
+
+	t.Run("reuse and forget", func(t *testing.T) {
+		merge()
+		defer reset()
+		assert.Equal(t, "resolved\n", readFile())
+
+		err := r.RerereForget("rerere.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = r.RerereClear()
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths, err := r.RerereStatus()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Empty(t, paths)
+	})
+
+	t.Run("forgotten", func(t *testing.T) {
+		merge()
+		defer reset()
+		assert.Contains(t, readFile(), "<<<<<<<")
+	})
+}
@@ -0,0 +1,160 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ShowBranchCommit is a commit in the result of comparing branches.
+type ShowBranchCommit struct {
+	// The ID of the commit.
+	ID *SHA1
+	// The subject of the commit message.
+	Subject string
+	// The names of the branches that contain the commit, in the same order as
+	// ShowBranchResult.Branches.
+	Branches []string
+	// Indicates whether the commit is a merge commit.
+	IsMerge bool
+}
+
+// ShowBranchResult is the result of comparing branches.
+type ShowBranchResult struct {
+	// The names of the compared branches.
+	Branches []string
+	// The commits down to the merge base of all branches, in the order shown by
+	// Git.
+	Commits []*ShowBranchCommit
+	// The merge base of all branches, which is nil if the branches have no common
+	// ancestor.
+	MergeBase *SHA1
+}
+
+// ShowBranchOptions contains optional arguments for comparing branches.
+//
+// Docs: https://git-scm.com/docs/git-show-branch
+type ShowBranchOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// parseShowBranch parses the output of "git show-branch --sha1-name" with full
+// commit IDs. The output starts with one header line for each branch, each is
+// indented by its column, e.g. " ! [develop] subject". It is followed by a
+// separator line of dashes, and then one line for each commit that starts with
+// a marker in each column, e.g. "+- [<commit>] subject", where a space means
+// the branch does not contain the commit, and "-" means a merge commit.
+func parseShowBranch(data []byte) (*ShowBranchResult, error) {
+	lines := bytesToStrings(data)
+
+	result := &ShowBranchResult{
+		Branches: []string{},
+		Commits:  []*ShowBranchCommit{},
+	}
+	i := 0
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if line != "" && strings.Trim(line, "-") == "" {
+			break
+		}
+
+		n := len(result.Branches)
+		if len(line) < n+2 || strings.TrimSpace(line[:n]) != "" {
+			return nil, fmt.Errorf("malformed header: %q", line)
+		}
+		name, _, err := parseShowBranchName(line[n+2:])
+		if err != nil {
+			return nil, err
+		}
+		result.Branches = append(result.Branches, name)
+	}
+	if i == len(lines) {
+		return nil, errors.New("no separator line")
+	}
+
+	n := len(result.Branches)
+	for _, line := range lines[i+1:] {
+		if len(line) < n+1 {
+			return nil, fmt.Errorf("malformed commit: %q", line)
+		}
+
+		name, subject, err := parseShowBranchName(line[n+1:])
+		if err != nil {
+			return nil, err
+		}
+		id, err := NewIDFromString(name)
+		if err != nil {
+			return nil, fmt.Errorf("parse commit ID %q: %v", name, err)
+		}
+
+		c := &ShowBranchCommit{
+			ID:       id,
+			Subject:  subject,
+			Branches: []string{},
+		}
+		for j, marker := range line[:n] {
+			switch marker {
+			case ' ':
+				continue
+			case '-':
+				c.IsMerge = true
+			}
+			c.Branches = append(c.Branches, result.Branches[j])
+		}
+		result.Commits = append(result.Commits, c)
+
+		if len(c.Branches) == n && result.MergeBase == nil {
+			result.MergeBase = c.ID
+		}
+	}
+	return result, nil
+}
+
+// parseShowBranchName parses the name in the brackets and the subject from the
+// text in the form of "[<name>] <subject>".
+func parseShowBranchName(text string) (name, subject string, _ error) {
+	end := strings.Index(text, "]")
+	if !strings.HasPrefix(text, "[") || end < 0 {
+		return "", "", fmt.Errorf("malformed name: %q", text)
+	}
+	return text[1:end], strings.TrimPrefix(text[end+1:], " "), nil
+}
+
+// ShowBranch compares the branches by showing which of the commits down to the
+// merge base of all branches are in which branches. It requires at least two
+// branches.
+func (r *Repository) ShowBranch(branches []string, opts ...ShowBranchOptions) (*ShowBranchResult, error) {
+	var opt ShowBranchOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	if len(branches) < 2 {
+		return nil, errors.New("at least two branches are required")
+	}
+
+	// Make Git print full commit IDs in place of names.
+	stdout, err := NewCommand("-c", "core.abbrev=40", "show-branch").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--no-color", "--sha1-name").
+		AddArgs(branches...).
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		if strings.Contains(err.Error(), "bad sha1 reference") {
+			return nil, ErrRevisionNotExist
+		}
+		return nil, err
+	}
+	return parseShowBranch(stdout)
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseShowBranch(t *testing.T) {
+	const (
+		id1 = "1111111111111111111111111111111111111111"
+		id2 = "2222222222222222222222222222222222222222"
+		id3 = "3333333333333333333333333333333333333333"
+		id4 = "4444444444444444444444444444444444444444"
+	)
+	data := `* [master] Merge branch 'fix'
+ ! [feature] Add feature.txt
+  ! [fix] Fix [bug]
+---
+-   [` + id4 + `] Merge branch 'fix'
+* + [` + id3 + `] Fix [bug]
+ +  [` + id2 + `] Add feature.txt
+*++ [` + id1 + `] Initial commit
+`
+	result, err := parseShowBranch([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := &ShowBranchResult{
+		Branches: []string{"master", "feature", "fix"},
+		Commits: []*ShowBranchCommit{
+			{
+				ID:       MustIDFromString(id4),
+				Subject:  "Merge branch 'fix'",
+				Branches: []string{"master"},
+				IsMerge:  true,
+			},
+			{
+				ID:       MustIDFromString(id3),
+				Subject:  "Fix [bug]",
+				Branches: []string{"master", "fix"},
+			},
+			{
+				ID:       MustIDFromString(id2),
+				Subject:  "Add feature.txt",
+				Branches: []string{"feature"},
+			},
+			{
+				ID:       MustIDFromString(id1),
+				Subject:  "Initial commit",
+				Branches: []string{"master", "feature", "fix"},
+			},
+		},
+		MergeBase: MustIDFromString(id1),
+	}
+	assert.Equal(t, exp, result)
+
+	for _, data := range []string{
+		"* [master] subject\n ! [feature] subject\n",
+		"* [master] subject\n! [feature] subject\n--\n",
+		"* [master] subject\n ! [feature] subject\n--\n+ \n",
+		"* [master] subject\n ! [feature] subject\n--\n++ [bad] subject\n",
+		"* [master] subject\n ! [feature] subject\n--\n++ bad subject\n",
+	} {
+		_, err = parseShowBranch([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestRepository_ShowBranch(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	base, err := r.RevParse("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = setupDivergedBranches(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := r.ShowBranch([]string{"master", "feature"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"master", "feature"}, result.Branches)
+	assert.Equal(t, base, result.MergeBase.String())
+
+	var subjects []string
+	for _, c := range result.Commits {
+		subjects = append(subjects, c.Subject)
+
+		switch c.Subject {
+		case "Add master.txt":
+			assert.Equal(t, []string{"master"}, c.Branches)
+		case "Add feature1.txt", "Add feature2.txt":
+			assert.Equal(t, []string{"feature"}, c.Branches)
+		default:
+			assert.Equal(t, []string{"master", "feature"}, c.Branches)
+		}
+	}
+	assert.ElementsMatch(t, []string{"Add master.txt", "Add feature2.txt", "Add feature1.txt"}, subjects[:3])
+	assert.Len(t, subjects, 4)
+
+	t.Run("not enough branches", func(t *testing.T) {
+		_, err := r.ShowBranch([]string{"master"})
+		assert.Error(t, err)
+	})
+
+	t.Run("branch does not exist", func(t *testing.T) {
+		_, err := r.ShowBranch([]string{"master", "404"})
+		assert.Equal(t, ErrRevisionNotExist, err)
+	})
+}
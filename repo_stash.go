@@ -0,0 +1,227 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Stash is an entry in the stash list.
+type Stash struct {
+	// The index of the entry, where 0 is the latest one.
+	Index int
+	// The name of the branch that the entry was created on, it is empty when
+	// HEAD was detached or the entry was stored with a custom message, e.g. by
+	// "git stash store -m".
+	Branch string
+	// The message of the entry, which defaults to the abbreviated ID and the
+	// subject of the HEAD commit when the entry was created.
+	Message string
+}
+
+// StashPushOptions contains optional arguments for stashing local changes.
+//
+// Docs: https://git-scm.com/docs/git-stash
+type StashPushOptions struct {
+	// The message of the stash entry.
+	Message string
+	// Indicates whether to also stash untracked files.
+	IncludeUntracked bool
+	// Indicates whether to keep the changes that are already added to the index
+	// intact.
+	KeepIndex bool
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// StashPush saves the local changes to a new stash entry and reverts the
+// working tree to match HEAD. It does nothing when there are no local changes.
+func (r *Repository) StashPush(opts ...StashPushOptions) error {
+	var opt StashPushOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	defer r.ClearReachabilityCache()
+
+	cmd := NewCommand("stash", "push").AddOptions(opt.CommandOptions)
+	if opt.Message != "" {
+		cmd.AddArgs("--message", opt.Message)
+	}
+	if opt.IncludeUntracked {
+		cmd.AddArgs("--include-untracked")
+	}
+	if opt.KeepIndex {
+		cmd.AddArgs("--keep-index")
+	}
+	_, err := cmd.RunInDirWithTimeout(opt.Timeout, r.path)
+	return err
+}
+
+// StashListOptions contains optional arguments for listing stash entries.
+//
+// Docs: https://git-scm.com/docs/git-stash
+type StashListOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// parseStashList parses the output of "git stash list --format=%gd%x00%gs",
+// i.e. lines like "stash@{0}\x00WIP on master: 2d3512d Subject" or
+// "stash@{1}\x00On master: message". Subjects in other forms, e.g. "autostash",
+// are taken as the message in whole.
+func parseStashList(data []byte) ([]*Stash, error) {
+	stashes := []*Stash{}
+	for _, line := range bytesToStrings(data) {
+		fields := strings.SplitN(line, "\x00", 2)
+		if len(fields) != 2 ||
+			!strings.HasPrefix(fields[0], "stash@{") ||
+			!strings.HasSuffix(fields[0], "}") {
+			return nil, fmt.Errorf("malformed stash entry: %q", line)
+		}
+		index, err := strconv.Atoi(fields[0][len("stash@{") : len(fields[0])-1])
+		if err != nil {
+			return nil, fmt.Errorf("parse index %q: %v", line, err)
+		}
+
+		stash := &Stash{Index: index}
+		subject := fields[1]
+		if strings.HasPrefix(subject, "WIP on ") {
+			subject = subject[len("WIP on "):]
+		} else if strings.HasPrefix(subject, "On ") {
+			subject = subject[len("On "):]
+		} else {
+			stash.Message = subject
+			stashes = append(stashes, stash)
+			continue
+		}
+
+		i := strings.Index(subject, ": ")
+		if i < 0 {
+			stash.Branch = subject
+		} else {
+			stash.Branch = subject[:i]
+			stash.Message = subject[i+2:]
+		}
+		if stash.Branch == "(no branch)" {
+			stash.Branch = ""
+		}
+		stashes = append(stashes, stash)
+	}
+	return stashes, nil
+}
+
+// StashList returns the list of stash entries, the latest one comes first. It
+// returns an empty list when there is no stash entry.
+func (r *Repository) StashList(opts ...StashListOptions) ([]*Stash, error) {
+	var opt StashListOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	stdout, err := NewCommand("stash", "list").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--format=%gd%x00%gs").
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		return nil, err
+	}
+	return parseStashList(stdout)
+}
+
+// stashError returns the typed error for the error of applying or dropping a
+// stash entry.
+func stashError(err error) error {
+	if strings.Contains(err.Error(), "No stash entries found") ||
+		strings.Contains(err.Error(), "is not a valid reference") {
+		return ErrNoStashEntries
+	} else if strings.Contains(err.Error(), "only has") {
+		return ErrRevisionNotExist
+	}
+	return err
+}
+
+// StashPopOptions contains optional arguments for applying and removing a stash
+// entry.
+//
+// Docs: https://git-scm.com/docs/git-stash
+type StashPopOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// StashPop applies the stash entry with given index to the working tree and
+// removes it from the stash list. The entry is kept when there are conflicts.
+// It returns ErrNoStashEntries if the stash list is empty, or
+// ErrRevisionNotExist if no entry has the index.
+func (r *Repository) StashPop(index int, opts ...StashPopOptions) error {
+	var opt StashPopOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	defer r.ClearReachabilityCache()
+
+	_, err := NewCommand("stash", "pop").
+		AddOptions(opt.CommandOptions).
+		AddArgs("stash@{"+strconv.Itoa(index)+"}").
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		return stashError(err)
+	}
+	return nil
+}
+
+// StashDropOptions contains optional arguments for removing a stash entry.
+//
+// Docs: https://git-scm.com/docs/git-stash
+type StashDropOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// StashDrop removes the stash entry with given index from the stash list. It
+// returns ErrNoStashEntries if the stash list is empty, or ErrRevisionNotExist
+// if no entry has the index.
+func (r *Repository) StashDrop(index int, opts ...StashDropOptions) error {
+	var opt StashDropOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	defer r.ClearReachabilityCache()
+
+	_, err := NewCommand("stash", "drop").
+		AddOptions(opt.CommandOptions).
+		AddArgs("stash@{"+strconv.Itoa(index)+"}").
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		return stashError(err)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseStashList(t *testing.T) {
+	data := "stash@{0}\x00On master: message: with colon\n" +
+		"stash@{1}\x00WIP on feature: 2d3512d Subject\n" +
+		"stash@{2}\x00WIP on (no branch): 2d3512d Subject\n" +
+		"stash@{3}\x00custom message\n" +
+		"stash@{4}\x00autostash\n"
+	stashes, err := parseStashList([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []*Stash{
+		{Index: 0, Branch: "master", Message: "message: with colon"},
+		{Index: 1, Branch: "feature", Message: "2d3512d Subject"},
+		{Index: 2, Branch: "", Message: "2d3512d Subject"},
+		{Index: 3, Branch: "", Message: "custom message"},
+		{Index: 4, Branch: "", Message: "autostash"},
+	}, stashes)
+
+	for _, data := range []string{
+		"stash@{0} On master: message\n",
+		"stash@{x}\x00On master: message\n",
+	} {
+		_, err = parseStashList([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestRepository_Stash(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	t.Run("no stash entries", func(t *testing.T) {
+		stashes, err := r.StashList()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Empty(t, stashes)
+
+		assert.Equal(t, ErrNoStashEntries, r.StashPop(0))
+		assert.Equal(t, ErrNoStashEntries, r.StashDrop(0))
+	})
+
+	readme := filepath.Join(r.Path(), "README.txt")
+	untracked := filepath.Join(r.Path(), "untracked.txt")
+	for _, content := range []string{"first", "second"} {
+		err = ioutil.WriteFile(readme, []byte(content), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(untracked, []byte(content), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = r.StashPush(StashPushOptions{
+			Message:          "Stash " + content,
+			IncludeUntracked: content == "second",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stashes, err := r.StashList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []*Stash{
+		{Index: 0, Branch: "master", Message: "Stash second"},
+		{Index: 1, Branch: "master", Message: "Stash first"},
+	}, stashes)
+
+	// Only the second entry included the untracked file.
+	assert.False(t, isExist(untracked))
+
+	t.Run("index does not exist", func(t *testing.T) {
+		assert.Equal(t, ErrRevisionNotExist, r.StashPop(2))
+		assert.Equal(t, ErrRevisionNotExist, r.StashDrop(2))
+	})
+
+	err = r.StashDrop(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.StashPop(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := ioutil.ReadFile(readme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "second", string(p))
+	assert.True(t, isExist(untracked))
+
+	stashes, err = r.StashList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, stashes)
+}
+
+func TestRepository_StashPush_keepIndex(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	readme := filepath.Join(r.Path(), "README.txt")
+	err = ioutil.WriteFile(readme, []byte("staged"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.Add(AddOptions{Pathspecs: []string{"README.txt"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.StashPush(StashPushOptions{KeepIndex: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := ioutil.ReadFile(readme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "staged", string(p))
+
+	stashes, err := r.StashList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, stashes, 1) {
+		assert.Equal(t, "master", stashes[0].Branch)
+		assert.NotEmpty(t, stashes[0].Message)
+	}
+}
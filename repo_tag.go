@@ -7,6 +7,7 @@ package git
 import (
 	"bytes"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -152,6 +153,9 @@ type TagsOptions struct {
 	SortKey string
 	// Pattern filters tags matching the specified pattern.
 	Pattern string
+	// The maximum number of tags to be returned, all tags are returned when it
+	// is zero.
+	MaxCount int
 	// The timeout duration before giving up for each shell command execution. The
 	// default timeout duration will be used when not supplied.
 	//
@@ -206,6 +210,9 @@ func RepoTags(repoPath string, opts ...TagsOptions) ([]string, error) {
 		}
 	}
 
+	if opt.MaxCount > 0 && len(tags) > opt.MaxCount {
+		tags = tags[:opt.MaxCount]
+	}
 	return tags, nil
 }
 
@@ -214,16 +221,158 @@ func (r *Repository) Tags(opts ...TagsOptions) ([]string, error) {
 	return RepoTags(r.path, opts...)
 }
 
+// tagsFormat is the format of "git for-each-ref" to list tags with their
+// metadata, each field is terminated by a NUL. The message is the last field
+// because it may contain newlines.
+const tagsFormat = "%(refname)%00%(objecttype)%00%(objectname)%00%(*objectname)%00%(tagger)%00%(creatordate:raw)%00%(contents)%00"
+
+// parseRawDate parses the date in the raw format of Git, i.e. the Unix
+// timestamp followed by the timezone, e.g. "1581602099 +0800".
+func parseRawDate(raw string) (time.Time, error) {
+	fields := strings.Fields(raw)
+	if len(fields) != 2 {
+		return time.Time{}, fmt.Errorf("malformed date: %q", raw)
+	}
+	sec, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse timestamp %q: %v", raw, err)
+	}
+	loc, err := parseTimezone(fields[1])
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, 0).In(loc), nil
+}
+
+// parseTags parses the output of "git for-each-ref" with tagsFormat.
+func (r *Repository) parseTags(data []byte) ([]*Tag, error) {
+	const numFields = 7
+
+	// Each line ends with a newline after the last NUL, which is then in front
+	// of the first field of the next tag.
+	fields := strings.Split(string(data), "\x00")
+	tags := make([]*Tag, 0, len(fields)/numFields)
+	for i := 0; i+numFields <= len(fields); i += numFields {
+		f := fields[i : i+numFields]
+		refspec := strings.TrimPrefix(f[0], "\n")
+		id, err := NewIDFromString(f[2])
+		if err != nil {
+			return nil, fmt.Errorf("parse ID of %q: %v", refspec, err)
+		}
+
+		date, err := parseRawDate(f[5])
+		if err != nil {
+			return nil, fmt.Errorf("parse date of %q: %v", refspec, err)
+		}
+
+		tag := &Tag{
+			typ:      ObjectType(f[1]),
+			id:       id,
+			commitID: id,
+			refspec:  refspec,
+			date:     date,
+			repo:     r,
+		}
+		if tag.typ == ObjectTag {
+			tag.commitID, err = NewIDFromString(f[3])
+			if err != nil {
+				return nil, fmt.Errorf("parse target of %q: %v", refspec, err)
+			}
+			if f[4] != "" {
+				tag.tagger, err = parseSignature([]byte(f[4]))
+				if err != nil {
+					return nil, fmt.Errorf("parse tagger of %q: %v", refspec, err)
+				}
+			}
+			tag.message = f[6]
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// ListTags returns a list of tags of the repository with their metadata,
+// including the tagger and the message of annotated tags, using a single Git
+// command. For a lightweight tag, the ID and the commit ID are both the ID of
+// the commit it points to. Tags are sorted by the creation date in descending
+// order unless TagsOptions.SortKey is set, e.g. "-v:refname" to get the latest
+// versions first.
+func (r *Repository) ListTags(opts ...TagsOptions) ([]*Tag, error) {
+	var opt TagsOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	cmd := NewCommand("for-each-ref", "--format="+tagsFormat).AddOptions(opt.CommandOptions)
+	if opt.SortKey != "" {
+		cmd.AddArgs("--sort=" + opt.SortKey)
+	} else {
+		cmd.AddArgs("--sort=-creatordate")
+	}
+	if opt.MaxCount > 0 {
+		cmd.AddArgs("--count=" + strconv.Itoa(opt.MaxCount))
+	}
+	cmd.AddArgs(RefsTags + opt.Pattern)
+
+	stdout, err := cmd.RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		return nil, err
+	}
+	return r.parseTags(stdout)
+}
+
+// TagCommit is a tag with the commit it points to.
+type TagCommit struct {
+	// The name of the tag, e.g. "v1.0.0".
+	Name string
+	// The ID of the object that the tag reference points to, which is the tag
+	// object for an annotated tag, or the commit for a lightweight tag.
+	ID *SHA1
+	// The ID of the commit that the tag points to.
+	CommitID *SHA1
+	// The date of the tag, which is the tagger date for an annotated tag, or the
+	// committer date of the commit for a lightweight tag.
+	Date time.Time
+}
+
+// TagsWithCommits returns a list of tags of the repository with the commits
+// they point to and their dates, using a single Git command. Tags are sorted by
+// the date in descending order unless TagsOptions.SortKey is set.
+func (r *Repository) TagsWithCommits(opts ...TagsOptions) ([]*TagCommit, error) {
+	tags, err := r.ListTags(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	tagCommits := make([]*TagCommit, 0, len(tags))
+	for _, tag := range tags {
+		tagCommits = append(tagCommits, &TagCommit{
+			Name:     tag.Name(),
+			ID:       tag.id,
+			CommitID: tag.commitID,
+			Date:     tag.date,
+		})
+	}
+	return tagCommits, nil
+}
+
 // CreateTagOptions contains optional arguments for creating a tag.
 //
 // Docs: https://git-scm.com/docs/git-tag
 type CreateTagOptions struct {
 	// Annotated marks a tag as annotated rather than lightweight.
 	Annotated bool
-	// Message specifies a tagging message for the annotated tag. It is ignored when tag is not annotated.
+	// Message specifies a tagging message for the annotated tag. The tag is
+	// annotated when it is set, even if Annotated is false.
 	Message string
 	// Author is the author of the tag. It is ignored when tag is not annotated.
 	Author *Signature
+	// Indicates whether to make a GPG-signed tag, which is always annotated.
+	Sign bool
+	// The key to sign the tag with when Sign is set. The default key of the
+	// tagger, or the one set by "user.signingKey" in config, is used when
+	// empty.
+	SigningKey string
 	// The timeout duration before giving up for each shell command execution. The
 	// default timeout duration will be used when not supplied.
 	//
@@ -233,29 +382,39 @@ type CreateTagOptions struct {
 	CommandOptions
 }
 
-// CreateTag creates a new tag on given revision.
+// CreateTag creates a new tag on given revision. It returns ErrTagExisted if
+// the tag already exists.
 func (r *Repository) CreateTag(name, rev string, opts ...CreateTagOptions) error {
+	defer r.ClearReachabilityCache()
+
 	var opt CreateTagOptions
 	if len(opts) > 0 {
 		opt = opts[0]
 	}
 
 	cmd := NewCommand("tag").AddOptions(opt.CommandOptions)
-	if opt.Annotated {
-		cmd.AddArgs("-a", name)
+	if opt.Annotated || opt.Message != "" || opt.Sign {
+		if !opt.Sign {
+			cmd.AddArgs("--annotate")
+		} else if opt.SigningKey != "" {
+			cmd.AddArgs("--local-user=" + opt.SigningKey)
+		} else {
+			cmd.AddArgs("--sign")
+		}
 		cmd.AddArgs("--message", opt.Message)
 		if opt.Author != nil {
 			cmd.AddCommitter(opt.Author)
 		}
-	} else {
-		// 🚨 SECURITY: Prevent including unintended options in the path to the Git command.
-		cmd.AddArgs("--end-of-options")
-		cmd.AddArgs(name)
 	}
 
-	cmd.AddArgs(rev)
+	// 🚨 SECURITY: Prevent including unintended options in the path to the Git command.
+	cmd.AddArgs("--end-of-options")
+	cmd.AddArgs(name, rev)
 
 	_, err := cmd.RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil && strings.Contains(err.Error(), "already exists") {
+		return ErrTagExisted
+	}
 	return err
 }
 
@@ -274,6 +433,8 @@ type DeleteTagOptions struct {
 
 // DeleteTag deletes a tag from the repository.
 func (r *Repository) DeleteTag(name string, opts ...DeleteTagOptions) error {
+	defer r.ClearReachabilityCache()
+
 	var opt DeleteTagOptions
 	if len(opts) > 0 {
 		opt = opts[0]
@@ -284,3 +445,82 @@ func (r *Repository) DeleteTag(name string, opts ...DeleteTagOptions) error {
 		RunInDirWithTimeout(opt.Timeout, r.path)
 	return err
 }
+
+// DescribeOptions contains optional arguments for describing a revision.
+//
+// Docs: https://git-scm.com/docs/git-describe
+type DescribeOptions struct {
+	// Indicates whether to use lightweight tags as well, only annotated tags are
+	// used otherwise.
+	Tags bool
+	// Indicates whether to use any reference, e.g. branches and remote-tracking
+	// branches.
+	All bool
+	// The number of hexadecimal digits of the abbreviated commit ID in the
+	// output. Git's default is used when not set.
+	Abbrev int
+	// Indicates whether to only output the closest tag without the number of
+	// commits on top of it and the abbreviated commit ID, which takes precedence
+	// over Abbrev.
+	TagOnly bool
+	// The glob pattern that tags must match to be used, e.g. "v*".
+	Match string
+	// Indicates whether to fall back to the abbreviated commit ID when no tag can
+	// describe the revision.
+	Always bool
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// Describe returns a human-readable name of the revision based on the closest
+// tag reachable from it, e.g. "v1.0.0-1-g83d2bc6" for a commit on top of the
+// tag "v1.0.0". HEAD is described when the revision is empty. It returns
+// ErrNoTagsFound if no tag can describe the revision and DescribeOptions.Always
+// is not set, or ErrRevisionNotExist if the revision does not exist.
+func (r *Repository) Describe(rev string, opts ...DescribeOptions) (string, error) {
+	var opt DescribeOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	cmd := NewCommand("describe").AddOptions(opt.CommandOptions)
+	if opt.Tags {
+		cmd.AddArgs("--tags")
+	}
+	if opt.All {
+		cmd.AddArgs("--all")
+	}
+	if opt.TagOnly {
+		cmd.AddArgs("--abbrev=0")
+	} else if opt.Abbrev > 0 {
+		cmd.AddArgs("--abbrev=" + strconv.Itoa(opt.Abbrev))
+	}
+	if opt.Match != "" {
+		cmd.AddArgs("--match", opt.Match)
+	}
+	if opt.Always {
+		cmd.AddArgs("--always")
+	}
+	if rev != "" {
+		// 🚨 SECURITY: Prevent including unintended options in the path to the Git command.
+		cmd.AddArgs("--end-of-options", rev)
+	}
+
+	stdout, err := cmd.RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "cannot describe anything"),
+			strings.Contains(err.Error(), "can describe"):
+			return "", ErrNoTagsFound
+		case strings.Contains(err.Error(), "Not a valid object name"):
+			return "", ErrRevisionNotExist
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(stdout)), nil
+}
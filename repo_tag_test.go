@@ -5,6 +5,8 @@
 package git
 
 import (
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -89,6 +91,206 @@ func TestRepository_Tags_VersionSort(t *testing.T) {
 	assert.Equal(t, "v2.999.0", tags[1])
 }
 
+func TestRepository_Tags_MaxCount(t *testing.T) {
+	tags, err := testrepo.Tags(TagsOptions{
+		SortKey:  "-version:refname",
+		MaxCount: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, tags, 1)
+}
+
+func TestRepository_ListTags(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	commitID, err := r.RevParse("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.CreateTag("v3.0.0", "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.CreateTag("v2.999.0", "master", CreateTagOptions{
+		Annotated: true,
+		Message:   "The version 2.999.0\n\nWith a body.",
+		Author: &Signature{
+			Name:  "alice",
+			Email: "alice@example.com",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tagID, err := r.ShowRefVerify(RefsTags + "v2.999.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tags, err := r.ListTags(TagsOptions{
+		SortKey:  "-version:refname",
+		Pattern:  "v*",
+		MaxCount: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 2 {
+		t.Fatalf("Should have two tags but got %d", len(tags))
+	}
+
+	assert.Equal(t, "v3.0.0", tags[0].Name())
+	assert.Equal(t, ObjectCommit, tags[0].Type())
+	assert.Equal(t, commitID, tags[0].ID().String())
+	assert.Equal(t, commitID, tags[0].CommitID().String())
+	assert.Nil(t, tags[0].Tagger())
+	assert.Empty(t, tags[0].Message())
+
+	assert.Equal(t, "v2.999.0", tags[1].Name())
+	assert.Equal(t, RefsTags+"v2.999.0", tags[1].Refspec())
+	assert.Equal(t, ObjectTag, tags[1].Type())
+	assert.Equal(t, tagID, tags[1].ID().String())
+	assert.Equal(t, commitID, tags[1].CommitID().String())
+	assert.Equal(t, "alice", tags[1].Tagger().Name)
+	assert.Equal(t, "alice@example.com", tags[1].Tagger().Email)
+	assert.Equal(t, "The version 2.999.0\n\nWith a body.\n", tags[1].Message())
+
+	// The tag is the same as the one returned by Tag.
+	tag, err := r.Tag("v2.999.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, tag.Tagger().When.Unix(), tags[1].Tagger().When.Unix())
+	assert.Equal(t, tag.Message(), tags[1].Message())
+
+	t.Run("all tags", func(t *testing.T) {
+		tags, err := testrepo.ListTags()
+		if err != nil {
+			t.Fatal(err)
+		}
+		names, err := testrepo.Tags()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Len(t, tags, len(names))
+	})
+}
+
+func Test_parseTags(t *testing.T) {
+	const (
+		id1 = "1111111111111111111111111111111111111111"
+		id2 = "2222222222222222222222222222222222222222"
+	)
+	data := "refs/tags/v1.0.0\x00commit\x00" + id1 + "\x00\x00\x001581602099 +0800\x00\x00\n" +
+		"refs/tags/v1.1.0\x00tag\x00" + id2 + "\x00" + id1 + "\x00alice <alice@example.com> 1581602199 -0130\x001581602199 -0130\x00The message\n\x00\n"
+	tags, err := testrepo.parseTags([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 2 {
+		t.Fatalf("Should have two tags but got %d", len(tags))
+	}
+
+	assert.Equal(t, "v1.0.0", tags[0].Name())
+	assert.Equal(t, ObjectCommit, tags[0].Type())
+	assert.Equal(t, id1, tags[0].ID().String())
+	assert.Equal(t, id1, tags[0].CommitID().String())
+	assert.Equal(t, int64(1581602099), tags[0].date.Unix())
+	_, offset := tags[0].date.Zone()
+	assert.Equal(t, 8*60*60, offset)
+
+	assert.Equal(t, "v1.1.0", tags[1].Name())
+	assert.Equal(t, ObjectTag, tags[1].Type())
+	assert.Equal(t, id2, tags[1].ID().String())
+	assert.Equal(t, id1, tags[1].CommitID().String())
+	assert.Equal(t, "alice", tags[1].Tagger().Name)
+	assert.Equal(t, "The message\n", tags[1].Message())
+	assert.Equal(t, int64(1581602199), tags[1].date.Unix())
+
+	for _, data := range []string{
+		"refs/tags/v1.0.0\x00commit\x00bad\x00\x00\x001581602099 +0800\x00\x00\n",
+		"refs/tags/v1.0.0\x00tag\x00" + id1 + "\x00bad\x00\x001581602099 +0800\x00\x00\n",
+		"refs/tags/v1.0.0\x00commit\x00" + id1 + "\x00\x00\x001581602099\x00\x00\n",
+		"refs/tags/v1.0.0\x00commit\x00" + id1 + "\x00\x00\x00bad +0800\x00\x00\n",
+		"refs/tags/v1.0.0\x00commit\x00" + id1 + "\x00\x00\x001581602099 bad\x00\x00\n",
+	} {
+		_, err = testrepo.parseTags([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestRepository_TagsWithCommits(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	commit, err := r.CatFileCommit("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.CreateTag("v3.0.0", "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.CreateTag("v2.999.0", "master", CreateTagOptions{
+		Message: "The version 2.999.0",
+		Author: &Signature{
+			Name:  "alice",
+			Email: "alice@example.com",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := r.Tag("v2.999.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tags, err := r.TagsWithCommits(TagsOptions{
+		SortKey:  "-version:refname",
+		Pattern:  "v*",
+		MaxCount: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 2 {
+		t.Fatalf("Should have two tags but got %d", len(tags))
+	}
+
+	assert.Equal(t, "v3.0.0", tags[0].Name)
+	assert.Equal(t, commit.ID.String(), tags[0].ID.String())
+	assert.Equal(t, commit.ID.String(), tags[0].CommitID.String())
+
+	assert.Equal(t, "v2.999.0", tags[1].Name)
+	assert.Equal(t, tag.ID().String(), tags[1].ID.String())
+	assert.Equal(t, commit.ID.String(), tags[1].CommitID.String())
+
+	assert.Equal(t, commit.Committer.When.Unix(), tags[0].Date.Unix())
+	assert.Equal(t, tag.Tagger().When.Unix(), tags[1].Date.Unix())
+
+	t.Run("all tags", func(t *testing.T) {
+		tags, err := testrepo.TagsWithCommits()
+		if err != nil {
+			t.Fatal(err)
+		}
+		names, err := testrepo.Tags()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Len(t, tags, len(names))
+	})
+}
+
 func TestRepository_CreateTag(t *testing.T) {
 	r, cleanup, err := setupTempRepo()
 	if err != nil {
@@ -138,6 +340,96 @@ func TestRepository_CreateAnnotatedTag(t *testing.T) {
 	assert.False(t, tag.tagger.When.IsZero())
 }
 
+func TestRepository_CreateTag_message(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	// A message makes the tag annotated.
+	err = r.CreateTag("v2.0.0", "master", CreateTagOptions{
+		Message: "The version 2.0.0",
+		Author: &Signature{
+			Name:  "alice",
+			Email: "alice@example.com",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tag, err := r.Tag("v2.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, ObjectTag, tag.Type())
+	assert.Equal(t, "The version 2.0.0\n", tag.Message())
+
+	t.Run("tag already exists", func(t *testing.T) {
+		err := r.CreateTag("v2.0.0", "master")
+		assert.Equal(t, ErrTagExisted, err)
+	})
+}
+
+func TestRepository_CreateTag_sign(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	// Use a fake GPG program that records its arguments and pretends to sign.
+	dir, err := filepath.Abs(r.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	argsPath := filepath.Join(dir, "gpg-args")
+	gpgPath := filepath.Join(dir, "gpg")
+	script := `#!/bin/sh
+echo "$@" > ` + argsPath + `
+cat > /dev/null
+printf "\\n[GNUPG:] SIG_CREATED \\n" >&2
+echo "-----BEGIN PGP SIGNATURE-----"
+echo "fake"
+echo "-----END PGP SIGNATURE-----"
+`
+	err = ioutil.WriteFile(gpgPath, []byte(script), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewCommand("config", "gpg.program", gpgPath).RunInDir(r.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.CreateTag("v2.0.0", "master", CreateTagOptions{
+		Message:    "The version 2.0.0",
+		Sign:       true,
+		SigningKey: "ABCDEF",
+		Author: &Signature{
+			Name:  "alice",
+			Email: "alice@example.com",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	args, err := ioutil.ReadFile(argsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, string(args), "ABCDEF")
+
+	tag, err := r.Tag("v2.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, ObjectTag, tag.Type())
+	assert.Contains(t, tag.Message(), "-----BEGIN PGP SIGNATURE-----")
+}
+
 func TestRepository_DeleteTag(t *testing.T) {
 	r, cleanup, err := setupTempRepo()
 	if err != nil {
@@ -154,3 +446,91 @@ func TestRepository_DeleteTag(t *testing.T) {
 
 	assert.False(t, r.HasReference(RefsTags+"v1.0.0"))
 }
+
+func TestRepository_Describe(t *testing.T) {
+	masterID, err := testrepo.RevParse("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		rev     string
+		opt     DescribeOptions
+		expName string
+	}{
+		{
+			name:    "annotated tag",
+			rev:     "master",
+			expName: "v1.1.0",
+		},
+		{
+			name:    "lightweight tag",
+			rev:     "master~1",
+			opt:     DescribeOptions{Tags: true},
+			expName: "v1.0.0",
+		},
+		{
+			name:    "match",
+			rev:     "master",
+			opt:     DescribeOptions{Tags: true, Match: "v1.0.*", Abbrev: 10},
+			expName: "v1.0.0-1-g" + masterID[:10],
+		},
+		{
+			name:    "tag only",
+			rev:     "master",
+			opt:     DescribeOptions{Tags: true, Match: "v1.0.*", Abbrev: 10, TagOnly: true},
+			expName: "v1.0.0",
+		},
+		{
+			name:    "all",
+			rev:     "master",
+			opt:     DescribeOptions{All: true},
+			expName: "tags/v1.1.0",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			name, err := testrepo.Describe(test.rev, test.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, test.expName, name)
+		})
+	}
+
+	t.Run("revision does not exist", func(t *testing.T) {
+		_, err := testrepo.Describe("404")
+		assert.Equal(t, ErrRevisionNotExist, err)
+	})
+
+	t.Run("no tags", func(t *testing.T) {
+		r, cleanup, err := setupTempRepo()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cleanup()
+
+		_, err = NewCommand("checkout", "--orphan", "orphan").RunInDir(r.Path())
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = commitFile(r, "orphan.txt", "orphan", "Add orphan.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = r.Describe("", DescribeOptions{Tags: true})
+		assert.Equal(t, ErrNoTagsFound, err)
+
+		headID, err := r.RevParse("HEAD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		name, err := r.Describe("", DescribeOptions{Always: true, Abbrev: 12})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, headID[:12], name)
+	})
+}
@@ -8,7 +8,10 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -58,6 +61,131 @@ func TestOpen(t *testing.T) {
 	assert.Equal(t, os.ErrNotExist, err)
 }
 
+func TestRepository_In(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	for _, name := range []string{"top.txt", "sub/sub.txt"} {
+		err = os.MkdirAll(filepath.Dir(filepath.Join(r.Path(), name)), os.ModePerm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(filepath.Join(r.Path(), name), []byte(name), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sub, err := r.In("sub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, filepath.Join(r.Path(), "sub"), sub.Path())
+	assert.Equal(t, r.cachedCommits, sub.cachedCommits)
+
+	// Only files in the subdirectory are added.
+	err = sub.Add(AddOptions{Pathspecs: []string{"."}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := NewCommand("diff", "--cached", "--name-only").RunInDir(r.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "sub/sub.txt\n", string(stdout))
+
+	gitDir, err := sub.gitDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, filepath.Join(r.Path(), ".git"), gitDir)
+
+	abs, err := r.In(filepath.Join(r.Path(), "sub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, sub.Path(), abs.Path())
+
+	_, err = r.In("404")
+	assert.Equal(t, os.ErrNotExist, err)
+}
+
+func TestRepository_In_reachabilityCache(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	err = setupDivergedBranches(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Check out "feature" in a linked worktree while HEAD of the main worktree
+	// stays on "master".
+	dir := tempPath()
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	_, err = NewCommand("worktree", "add", dir, "feature").RunInDir(r.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.EnableReachabilityCache()
+	wt, err := r.In(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Same(t, r.cachedReachability, wt.cachedReachability)
+
+	isAncestor, err := r.IsAncestor("feature~1", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, isAncestor)
+
+	isAncestor, err = wt.IsAncestor("feature~1", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, isAncestor)
+
+	ahead, behind, err := r.AheadBehind("feature", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, [2]int64{1, 2}, [2]int64{ahead, behind})
+
+	ahead, behind, err = wt.AheadBehind("feature", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, [2]int64{0, 0}, [2]int64{ahead, behind})
+
+	// Updating references through the worktree invalidates the cache of the
+	// original handle.
+	isAncestor, err = r.IsAncestor("feature", "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, isAncestor)
+
+	err = wt.UpdateRef(RefsHeads+"master", "feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	isAncestor, err = r.IsAncestor("feature", "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, isAncestor)
+}
+
 func TestClone(t *testing.T) {
 	tests := []struct {
 		opt CloneOptions
@@ -104,6 +232,80 @@ func TestClone(t *testing.T) {
 	}
 }
 
+func TestClone_singleBranch(t *testing.T) {
+	path := tempPath()
+	defer func() {
+		_ = os.RemoveAll(path)
+	}()
+
+	err := Clone(testrepo.Path(), path, CloneOptions{
+		Branch:       "develop",
+		SingleBranch: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, err := NewCommand("for-each-ref", "--format=%(refname)", "refs/remotes/").RunInDir(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"refs/remotes/origin/develop"}, bytesToStrings(stdout))
+}
+
+func TestCloneShared(t *testing.T) {
+	masterID, err := testrepo.RevParse("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		opt           CloneSharedOptions
+		expAlternates bool
+	}{
+		{
+			name:          "shared",
+			opt:           CloneSharedOptions{Bare: true},
+			expAlternates: true,
+		},
+		{
+			name:          "dissociate",
+			opt:           CloneSharedOptions{Bare: true, Dissociate: true},
+			expAlternates: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := tempPath()
+			defer func() {
+				_ = os.RemoveAll(path)
+			}()
+
+			err := CloneShared(testrepo.Path(), path, test.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, test.expAlternates, isFile(filepath.Join(path, "objects", "info", "alternates")))
+
+			r, err := Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			id, err := r.RevParse("master")
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, masterID, id)
+
+			err = r.Fsck()
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
 func setupTempRepo() (_ *Repository, cleanup func(), err error) {
 	path := tempPath()
 	cleanup = func() {
@@ -126,6 +328,61 @@ func setupTempRepo() (_ *Repository, cleanup func(), err error) {
 	return r, cleanup, nil
 }
 
+// commitFile writes the content to the file with given name in the working
+// tree of the repository, then stages and commits it with given message.
+func commitFile(r *Repository, name, content, message string) error {
+	fpath := filepath.Join(r.Path(), name)
+	err := os.MkdirAll(filepath.Dir(fpath), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(fpath, []byte(content), 0600)
+	if err != nil {
+		return err
+	}
+
+	err = r.Add(AddOptions{All: true})
+	if err != nil {
+		return err
+	}
+
+	return r.Commit(&Signature{
+		Name:  "alice",
+		Email: "alice@example.com",
+	}, message)
+}
+
+// commitConflict commits different content of the same file to a new branch
+// and the current branch of the repository respectively, and returns the name
+// of the new branch.
+func commitConflict(r *Repository, name string) (string, error) {
+	current, err := r.CurrentBranch()
+	if err != nil {
+		return "", err
+	}
+
+	branch := "conflict-" + name
+	err = r.Checkout(branch, CheckoutOptions{BaseBranch: current})
+	if err != nil {
+		return "", err
+	}
+	err = commitFile(r, name, "theirs\n", "Change "+name+" on "+branch)
+	if err != nil {
+		return "", err
+	}
+
+	err = r.Checkout(current)
+	if err != nil {
+		return "", err
+	}
+	err = commitFile(r, name, "ours\n", "Change "+name+" on "+current)
+	if err != nil {
+		return "", err
+	}
+	return branch, nil
+}
+
 func TestRepository_Fetch(t *testing.T) {
 	r, cleanup, err := setupTempRepo()
 	if err != nil {
@@ -155,6 +412,250 @@ func TestRepository_Fetch(t *testing.T) {
 	}
 }
 
+func TestRepository_Fetch_options(t *testing.T) {
+	upstream, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	path := tempPath()
+	defer func() {
+		_ = os.RemoveAll(path)
+	}()
+
+	// A local path ignores the depth, use the file protocol instead.
+	err = Clone("file://"+upstream.Path(), path, CloneOptions{Depth: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	countCommits := func() string {
+		stdout, err := NewCommand("rev-list", "--count", "HEAD").RunInDir(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(string(stdout))
+	}
+	assert.Equal(t, "1", countCommits())
+
+	t.Run("depth", func(t *testing.T) {
+		err := r.Fetch(FetchOptions{Depth: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "2", countCommits())
+	})
+
+	t.Run("refspecs", func(t *testing.T) {
+		err := r.Fetch(FetchOptions{
+			Remote:   "origin",
+			Refspecs: []string{"+refs/heads/master:refs/custom/master"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, r.HasReference("refs/custom/master"))
+
+		err = r.Fetch(FetchOptions{Refspecs: []string{"refs/heads/master"}})
+		assert.Error(t, err)
+	})
+
+	t.Run("tags", func(t *testing.T) {
+		err := upstream.CreateTag("v9.9.9", "master~1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.False(t, r.HasTag("v9.9.9"))
+
+		err = r.Fetch(FetchOptions{Remote: "origin", Tags: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, r.HasTag("v9.9.9"))
+	})
+}
+
+func TestRepository_FetchMirror(t *testing.T) {
+	upstream, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	mirrorPath := tempPath()
+	defer func() {
+		_ = os.RemoveAll(mirrorPath)
+	}()
+
+	err = Init(mirrorPath, InitOptions{Bare: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mirror, err := Open(mirrorPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = mirror.RemoteAdd("origin", upstream.Path(), RemoteAddOptions{MirrorFetch: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = NewCommand("branch", "feature").RunInDir(upstream.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = mirror.FetchMirror("origin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, mirror.HasBranch("master"))
+	assert.True(t, mirror.HasBranch("feature"))
+
+	// Branches deleted from upstream should be pruned
+	err = upstream.DeleteBranch("feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = mirror.FetchMirror("origin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, mirror.HasBranch("master"))
+	assert.False(t, mirror.HasBranch("feature"))
+}
+
+func TestRepository_Prefetch(t *testing.T) {
+	upstream, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	r, cleanup2, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup2()
+
+	err = r.RemoteAdd("upstream", upstream.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = commitFile(upstream, "prefetch.txt", "prefetch", "Add prefetch.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewCommand("tag", "prefetch-tag").RunInDir(upstream.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := upstream.RevParse("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.Prefetch("upstream")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := r.ShowRefVerify("refs/prefetch/upstream/master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, head, got)
+	typ, err := r.CatFileType(head)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, ObjectCommit, typ)
+
+	// Remote-tracking references and tags are not touched.
+	assert.False(t, r.HasReference("refs/remotes/upstream/master"))
+	assert.False(t, r.HasTag("prefetch-tag"))
+
+	t.Run("remote does not exist", func(t *testing.T) {
+		err := r.Prefetch("404")
+		assert.Error(t, err)
+	})
+}
+
+func Test_parseFetchPruned(t *testing.T) {
+	output := `From ../upstream
+ - [deleted]         (none)     -> origin/feature
+ - [deleted]         (none)     -> origin/with/slash
+   1a2b3c4..5d6e7f8  master     -> origin/master
+ * [would prune] origin/old
+`
+	assert.Equal(t,
+		[]string{"origin/feature", "origin/with/slash", "origin/old"},
+		parseFetchPruned([]byte(output)),
+	)
+}
+
+func TestRepository_FetchPrune(t *testing.T) {
+	upstream, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	_, err = NewCommand("branch", "feature").RunInDir(upstream.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := tempPath()
+	defer func() {
+		_ = os.RemoveAll(path)
+	}()
+
+	err = Clone(upstream.Path(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, r.HasReference("refs/remotes/origin/feature"))
+
+	// Nothing to prune
+	pruned, err := r.FetchPrune("origin", FetchPruneOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, pruned)
+
+	err = upstream.DeleteBranch("feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pruned, err = r.FetchPrune("origin", FetchPruneOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"origin/feature"}, pruned)
+	assert.True(t, r.HasReference("refs/remotes/origin/feature"))
+
+	pruned, err = r.FetchPrune("origin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"origin/feature"}, pruned)
+	assert.False(t, r.HasReference("refs/remotes/origin/feature"))
+}
+
 func TestRepository_Pull(t *testing.T) {
 	r, cleanup, err := setupTempRepo()
 	if err != nil {
@@ -223,6 +724,198 @@ func TestRepository_Push(t *testing.T) {
 	}
 }
 
+func TestRepository_ForcePushWithLease(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	remotePath := tempPath()
+	defer func() {
+		_ = os.RemoveAll(remotePath)
+	}()
+
+	err = Init(remotePath, InitOptions{Bare: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.RemoteAdd("lease", remotePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.Checkout("feature", CheckoutOptions{BaseBranch: "master"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = commitFile(r, "feature.txt", "1", "Add feature.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := r.RevParse("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The branch does not exist on the remote yet
+	err = r.ForcePushWithLease("lease", "feature", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rewrite the history and force push
+	err = r.Reset("HEAD~1", ResetOptions{Hard: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = commitFile(r, "feature.txt", "2", "Add feature.txt again")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := r.RevParse("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.ForcePushWithLease("lease", "feature", first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remoteID, err := NewCommand("rev-parse", "feature").RunInDir(remotePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, second, strings.TrimSpace(string(remoteID)))
+
+	// The remote has moved since the expected commit
+	err = commitFile(r, "feature.txt", "3", "Change feature.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.ForcePushWithLease("lease", "feature", first)
+	assert.Equal(t, ErrStaleInfo, err)
+
+	// Someone else moved the branch on the remote, which is not yet seen by the
+	// remote-tracking branch.
+	_, err = NewCommand("update-ref", RefsHeads+"feature", first).RunInDir(remotePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.Push("lease", "feature", PushOptions{ForceWithLease: true})
+	assert.Equal(t, ErrStaleInfo, err)
+}
+
+func TestRepository_DeleteRemoteBranch(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	remotePath := tempPath()
+	defer func() {
+		_ = os.RemoveAll(remotePath)
+	}()
+
+	err = Init(remotePath, InitOptions{Bare: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.RemoteAdd("origin2", remotePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.Push("origin2", "master:feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewCommand("fetch", "origin2").RunInDir(r.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, r.HasReference("refs/remotes/origin2/feature"))
+
+	err = r.DeleteRemoteBranch("origin2", "feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remote, err := Open(remotePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, remote.HasBranch("feature"))
+	assert.False(t, r.HasReference("refs/remotes/origin2/feature"))
+
+	t.Run("branch does not exist", func(t *testing.T) {
+		err := r.DeleteRemoteBranch("origin2", "feature")
+		assert.Equal(t, ErrRemoteBranchNotExist, err)
+	})
+
+	t.Run("remote does not exist", func(t *testing.T) {
+		err := r.DeleteRemoteBranch("404", "feature")
+		assert.Equal(t, ErrRemoteNotExist, err)
+	})
+}
+
+func TestRepository_PushMirror(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	mirrorPath := tempPath()
+	defer func() {
+		_ = os.RemoveAll(mirrorPath)
+	}()
+
+	err = Init(mirrorPath, InitOptions{Bare: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mirror, err := Open(mirrorPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.RemoteAdd("mirror", mirrorPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = NewCommand("branch", "feature").RunInDir(r.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.PushMirror("mirror")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, mirror.HasBranch("master"))
+	assert.True(t, mirror.HasBranch("feature"))
+
+	// Deleted branches should only be deleted from the mirror when not dry run
+	err = r.DeleteBranch("feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.PushMirror("mirror", PushMirrorOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, mirror.HasBranch("feature"))
+
+	err = r.PushMirror("mirror")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, mirror.HasBranch("feature"))
+}
+
 func TestRepository_Checkout(t *testing.T) {
 	r, cleanup, err := setupTempRepo()
 	if err != nil {
@@ -309,6 +1002,35 @@ func TestRepository_Move(t *testing.T) {
 			}
 		})
 	}
+
+	t.Run("file is not tracked", func(t *testing.T) {
+		err := ioutil.WriteFile(filepath.Join(r.Path(), "untracked.txt"), []byte("untracked"), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = r.Move("untracked.txt", "moved.txt")
+		assert.Equal(t, ErrFileNotTracked, err)
+
+		err = r.Move("404.txt", "moved.txt")
+		assert.Equal(t, ErrFileNotTracked, err)
+	})
+
+	t.Run("destination exists", func(t *testing.T) {
+		err := r.Move("README.txt", "runme.sh")
+		assert.Equal(t, ErrFileExisted, err)
+
+		err = r.Move("README.txt", "runme.sh", MoveOptions{Force: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		stdout, err := NewCommand("status", "--porcelain", "--", "README.txt", "runme.sh").RunInDir(r.Path())
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Contains(t, string(stdout), "README.txt -> runme.sh")
+	})
 }
 
 func TestRepository_Add(t *testing.T) {
@@ -352,11 +1074,10 @@ func TestRepository_Commit(t *testing.T) {
 	message := "Add a file"
 
 	t.Run("nothing to commit", func(t *testing.T) {
-		if err = r.Commit(committer, message, CommitOptions{
+		err = r.Commit(committer, message, CommitOptions{
 			Author: author,
-		}); err != nil {
-			t.Fatal(err)
-		}
+		})
+		assert.Equal(t, ErrNothingToCommit, err)
 	})
 
 	t.Run("committer is also the author", func(t *testing.T) {
@@ -422,6 +1143,41 @@ func TestRepository_Commit(t *testing.T) {
 		assert.Equal(t, author.Email, c.Author.Email)
 		assert.Equal(t, message+"\n", c.Message)
 	})
+
+	t.Run("allow empty", func(t *testing.T) {
+		if err = r.Commit(committer, "Empty commit", CommitOptions{AllowEmpty: true}); err != nil {
+			t.Fatal(err)
+		}
+
+		c, err := r.CatFileCommit("master")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "Empty commit\n", c.Message)
+	})
+
+	t.Run("amend", func(t *testing.T) {
+		// The tip commit is empty, which has to be allowed to be amended.
+		parentID, err := r.RevParse("master~1")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err = r.Commit(committer, "Amended commit", CommitOptions{AllowEmpty: true, Amend: true}); err != nil {
+			t.Fatal(err)
+		}
+
+		c, err := r.CatFileCommit("master")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "Amended commit\n", c.Message)
+		id, err := c.ParentID(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, parentID, id.String())
+	})
 }
 
 func TestRepository_RevParse(t *testing.T) {
@@ -471,6 +1227,171 @@ func TestRepository_RevParse(t *testing.T) {
 	}
 }
 
+func TestRepository_RevParse_options(t *testing.T) {
+	masterID, err := testrepo.RevParse("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("relative revision", func(t *testing.T) {
+		parentID, err := testrepo.RevParse("master~1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := testrepo.CatFileCommit(masterID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expID, err := c.ParentID(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, expID.String(), parentID)
+	})
+
+	t.Run("short", func(t *testing.T) {
+		id, err := testrepo.RevParse("master", RevParseOptions{Short: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Less(t, len(id), len(masterID))
+		assert.True(t, strings.HasPrefix(masterID, id))
+	})
+
+	t.Run("abbrev ref", func(t *testing.T) {
+		name, err := testrepo.RevParse("refs/heads/release-1.0", RevParseOptions{AbbrevRef: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "release-1.0", name)
+	})
+
+	t.Run("multiple revisions", func(t *testing.T) {
+		_, err := testrepo.RevParse("master release-1.0")
+		assert.Equal(t, ErrRevisionNotExist, err)
+	})
+}
+
+func TestRepository_RevParseMany(t *testing.T) {
+	masterID, err := testrepo.RevParse("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	releaseID, err := testrepo.RevParse("refs/heads/release-1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("nothing to resolve", func(t *testing.T) {
+		resolved, unresolved, err := testrepo.RevParseMany(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Empty(t, resolved)
+		assert.Empty(t, unresolved)
+	})
+
+	resolved, unresolved, err := testrepo.RevParseMany([]string{
+		"master",
+		"refs/tags/404",
+		"refs/heads/release-1.0",
+		"",
+		"multiple\nlines",
+		"master",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t,
+		map[string]string{
+			"master":                 masterID,
+			"refs/heads/release-1.0": releaseID,
+		},
+		resolved,
+	)
+	assert.ElementsMatch(t, []string{"refs/tags/404", "", "multiple\nlines"}, unresolved)
+}
+
+func TestRepository_ObjectInfos(t *testing.T) {
+	t.Run("nothing to check", func(t *testing.T) {
+		infos, missing, err := testrepo.ObjectInfos(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Empty(t, infos)
+		assert.Empty(t, missing)
+	})
+
+	var ids []string
+	expInfos := make(map[string]*ObjectInfo)
+	for _, rev := range []string{"master", "master^{tree}", "v1.0.0"} {
+		id, err := testrepo.RevParse(rev)
+		if err != nil {
+			t.Fatal(err)
+		}
+		typ, err := testrepo.CatFileType(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		size, err := NewCommand("cat-file", "-s", id).RunInDir(testrepo.Path())
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(string(size)), 10, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids = append(ids, id)
+		expInfos[id] = &ObjectInfo{Type: typ, Size: n}
+	}
+
+	infos, missing, err := testrepo.ObjectInfos(append(ids, EmptyID, "", "multiple\nlines"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, expInfos, infos)
+	assert.ElementsMatch(t, []string{EmptyID, "", "multiple\nlines"}, missing)
+
+	t.Run("timeout from command options", func(t *testing.T) {
+		_, _, err := testrepo.ObjectInfos(ids, ObjectInfosOptions{
+			CommandOptions: CommandOptions{Timeout: time.Nanosecond},
+		})
+		assert.Equal(t, ErrExecTimeout, err)
+	})
+}
+
+func TestRepository_ObjectsExist(t *testing.T) {
+	t.Run("nothing to check", func(t *testing.T) {
+		exists, err := testrepo.ObjectsExist(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Empty(t, exists)
+	})
+
+	commitID, err := testrepo.RevParse("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	treeID, err := testrepo.RevParse("master^{tree}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exists, err := testrepo.ObjectsExist([]string{commitID, treeID, EmptyID, ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, map[string]bool{
+		commitID: true,
+		treeID:   true,
+		EmptyID:  false,
+		"":       false,
+	}, exists)
+}
+
 func TestRepository_CountObjects(t *testing.T) {
 	// Make sure it does not blow up
 	_, err := testrepo.CountObjects(CountObjectsOptions{})
@@ -486,3 +1407,205 @@ func TestRepository_Fsck(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestRepository_Repack(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	packDir := filepath.Join(r.Path(), ".git", "objects", "pack")
+	countPacks := func() int {
+		packs, err := filepath.Glob(filepath.Join(packDir, "*.pack"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(packs)
+	}
+
+	// Create a few small packs in addition to the existing ones.
+	n := countPacks()
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		err = commitFile(r, name, name, "Add "+name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = r.Repack()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	assert.Equal(t, n+3, countPacks())
+
+	t.Run("geometric", func(t *testing.T) {
+		err := r.Repack(RepackOptions{
+			DeleteRedundant: true,
+			Geometric:       2,
+			WriteMidx:       true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Less(t, countPacks(), n+3)
+		assert.True(t, isFile(filepath.Join(packDir, "multi-pack-index")))
+	})
+
+	t.Run("all", func(t *testing.T) {
+		err := r.Repack(RepackOptions{
+			All:             true,
+			DeleteRedundant: true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, 1, countPacks())
+	})
+
+	err = r.Fsck()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRepository_GC(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	for _, name := range []string{"a.txt", "b.txt"} {
+		err = commitFile(r, name, name, "Add "+name)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	before, err := r.CountObjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotZero(t, before.Count)
+
+	// Not enough loose objects to be worthwhile.
+	err = r.GC(GCOptions{Auto: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err := r.CountObjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, before.Count, after.Count)
+
+	err = r.GC(GCOptions{Prune: 24 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err = r.CountObjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Zero(t, after.Count)
+	assert.Equal(t, int64(1), after.Packs)
+
+	err = r.GC(GCOptions{Aggressive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.Fsck()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRepository_FsckWithReport(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	report, err := r.FsckWithReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, report.IsHealthy())
+
+	blobID, err := r.HashObject(strings.NewReader("dangling\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, opt := range []FsckOptions{{}, {ConnectivityOnly: true}} {
+		report, err = r.FsckWithReport(opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, report.IsHealthy())
+		assert.Equal(t, []FsckObject{{Type: ObjectBlob, ID: blobID}}, report.Dangling)
+	}
+
+	// Remove the loose object of a blob that is reachable.
+	err = commitFile(r, "missing.txt", "missing\n", "Add missing.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	missingID, err := r.RevParse("master:missing.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Remove(filepath.Join(r.Path(), ".git", "objects", missingID[:2], missingID[2:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err = r.FsckWithReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, report.IsHealthy())
+	assert.Equal(t, []FsckObject{{Type: ObjectBlob, ID: missingID}}, report.Missing)
+
+	t.Run("empty repository", func(t *testing.T) {
+		path := tempPath()
+		defer func() { _ = os.RemoveAll(path) }()
+
+		err := Init(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		empty, err := Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		report, err := empty.FsckWithReport()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, report.IsHealthy())
+		assert.Empty(t, report.Errors)
+	})
+}
+
+func Test_parseFsckReport(t *testing.T) {
+	stdout := []byte(`dangling commit 3878e966952a429677344ecc0eea09162b37380b
+missing blob 975fbec8256d3e8a3797e7a3611380f27c49f4ac
+broken link from    tree a71f48f8af8c211cfd6059140b372a3c03f64529
+`)
+	stderr := []byte(`notice: HEAD points to an unborn branch (master)
+warning in tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904: zeroPaddedFilemode: contains zero-padded file modes
+error: a71f48f8af8c211cfd6059140b372a3c03f64529: object corrupt or missing
+`)
+	assert.Equal(t, &FsckReport{
+		Dangling: []FsckObject{{Type: ObjectCommit, ID: "3878e966952a429677344ecc0eea09162b37380b"}},
+		Missing:  []FsckObject{{Type: ObjectBlob, ID: "975fbec8256d3e8a3797e7a3611380f27c49f4ac"}},
+		Errors: []string{
+			"broken link from    tree a71f48f8af8c211cfd6059140b372a3c03f64529",
+			"error: a71f48f8af8c211cfd6059140b372a3c03f64529: object corrupt or missing",
+		},
+		Warnings: []string{
+			"warning in tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904: zeroPaddedFilemode: contains zero-padded file modes",
+		},
+	}, parseFsckReport(stdout, stderr))
+}
@@ -7,6 +7,8 @@ package git
 import (
 	"bytes"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -137,3 +139,187 @@ func (r *Repository) LsTree(treeID string, opts ...LsTreeOptions) (*Tree, error)
 	r.cachedTrees.Set(treeID, t)
 	return t, nil
 }
+
+// TreeEntriesOptions contains optional arguments for listing tree entries.
+//
+// Docs: https://git-scm.com/docs/git-ls-tree
+type TreeEntriesOptions struct {
+	// Indicates whether to list the entries of subtrees recursively, where only
+	// blobs and submodules are listed with their paths relative to the tree.
+	Recursive bool
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// parseEntryMode parses the mode of a tree entry into its entry mode and object
+// type.
+func parseEntryMode(mode string) (EntryMode, ObjectType, error) {
+	switch mode {
+	case "100644", "100664":
+		return EntryBlob, ObjectBlob, nil
+	case "100755":
+		return EntryExec, ObjectBlob, nil
+	case "120000":
+		return EntrySymlink, ObjectBlob, nil
+	case "160000":
+		return EntryCommit, ObjectCommit, nil
+	case "040000":
+		return EntryTree, ObjectTree, nil
+	}
+	return 0, "", fmt.Errorf("unknown mode: %q", mode)
+}
+
+// parseTreeEntries parses the output of "git ls-tree -l -z", where each entry
+// is in the form of "<mode> <type> <object> <size>\t<name>" and terminated by
+// a NUL byte. The size is right-aligned, and it is "-" for trees and
+// submodules.
+func parseTreeEntries(t *Tree, data []byte) (Entries, error) {
+	entries := make(Entries, 0, 10)
+	for _, record := range bytes.Split(data, []byte{0}) {
+		if len(record) == 0 {
+			continue
+		}
+
+		i := bytes.IndexByte(record, '\t')
+		if i < 0 {
+			return nil, fmt.Errorf("malformed entry: %q", record)
+		}
+		fields := strings.Fields(string(record[:i]))
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("malformed entry: %q", record)
+		}
+
+		mode, typ, err := parseEntryMode(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		id, err := NewIDFromString(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("parse object ID %q: %v", fields[2], err)
+		}
+
+		e := &TreeEntry{
+			mode:   mode,
+			typ:    typ,
+			id:     id,
+			name:   string(record[i+1:]),
+			parent: t,
+		}
+		if fields[3] != "-" {
+			e.size, err = strconv.ParseInt(fields[3], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parse size %q: %v", fields[3], err)
+			}
+		}
+		// The size is already known, do not look it up again.
+		e.sizeOnce.Do(func() {})
+
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// TreeEntries returns the entries of the tree in the subpath (e.g. "src/app")
+// of given revision, or the root tree when the subpath is empty. Sizes of the
+// entries are loaded in the same command. It returns ErrRevisionNotExist if the
+// revision or the subpath does not exist, or the subpath is not a directory.
+func (r *Repository) TreeEntries(rev, subpath string, opts ...TreeEntriesOptions) (Entries, error) {
+	var opt TreeEntriesOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	treeish := rev + "^{tree}"
+	if subpath != "" {
+		treeish = rev + ":" + strings.Trim(subpath, "/")
+	}
+	treeID, err := r.RevParse(treeish, RevParseOptions{Timeout: opt.Timeout}) //nolint
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := NewCommand("ls-tree", "-l", "-z").AddOptions(opt.CommandOptions)
+	if opt.Recursive {
+		cmd.AddArgs("-r")
+	}
+	stdout, err := cmd.AddArgs(treeID).RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		if strings.Contains(err.Error(), "not a tree object") {
+			return nil, ErrRevisionNotExist
+		}
+		return nil, err
+	}
+
+	t := &Tree{
+		id:   MustIDFromString(treeID),
+		repo: r,
+	}
+	return parseTreeEntries(t, stdout)
+}
+
+// MkTreeEntry is an entry of a tree to be created.
+type MkTreeEntry struct {
+	// The mode of the entry, which also determines the type of the object.
+	Mode EntryMode
+	// The ID of the object that the entry points to.
+	ID string
+	// The name of the entry, which must not contain any slash.
+	Name string
+}
+
+// MkTreeOptions contains optional arguments for creating a tree.
+//
+// Docs: https://git-scm.com/docs/git-mktree
+type MkTreeOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// MkTree writes a tree with given entries to the object database and returns
+// the ID of the tree. Entries do not need to be sorted, and objects they point
+// to must exist except for submodules.
+func (r *Repository) MkTree(entries []MkTreeEntry, opts ...MkTreeOptions) (string, error) {
+	var opt MkTreeOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	stdin := new(bytes.Buffer)
+	for _, e := range entries {
+		typ := ObjectBlob
+		switch e.Mode {
+		case EntryTree:
+			typ = ObjectTree
+		case EntryCommit:
+			typ = ObjectCommit
+		}
+		_, _ = fmt.Fprintf(stdin, "%06o %s %s\t%s\x00", e.Mode, typ, e.ID, e.Name)
+	}
+
+	stdout := new(bytes.Buffer)
+	stderr := newTailBuffer(stderrLimit)
+	cmd := NewCommand("mktree", "-z").
+		AddOptions(opt.CommandOptions)
+	if opt.Timeout != 0 {
+		cmd = cmd.WithTimeout(opt.Timeout)
+	}
+	err := cmd.RunInDirWithOptions(r.path, RunInDirOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+	if err != nil {
+		return "", concatenateError(err, stderr.String())
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -5,6 +5,7 @@
 package git
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -25,3 +26,161 @@ func TestRepository_LsTree(t *testing.T) {
 	}
 	assert.NotNil(t, tree)
 }
+
+func Test_parseTreeEntries(t *testing.T) {
+	const (
+		id1 = "1111111111111111111111111111111111111111"
+		id2 = "2222222222222222222222222222222222222222"
+	)
+	data := "040000 tree " + id1 + "       -\tdir\x00" +
+		"100644 blob " + id2 + "      11\tfile name.txt\x00" +
+		"100755 blob " + id2 + "      11\trun.sh\x00" +
+		"120000 blob " + id2 + "       6\tlink\x00" +
+		"160000 commit " + id1 + "       -\tsubmodule\x00"
+	entries, err := parseTreeEntries(nil, []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type entry struct {
+		name string
+		mode EntryMode
+		typ  ObjectType
+		id   string
+		size int64
+	}
+	var got []entry
+	for _, e := range entries {
+		got = append(got, entry{e.Name(), e.Mode(), e.Type(), e.ID().String(), e.Size()})
+	}
+	assert.Equal(t, []entry{
+		{"dir", EntryTree, ObjectTree, id1, 0},
+		{"file name.txt", EntryBlob, ObjectBlob, id2, 11},
+		{"run.sh", EntryExec, ObjectBlob, id2, 11},
+		{"link", EntrySymlink, ObjectBlob, id2, 6},
+		{"submodule", EntryCommit, ObjectCommit, id1, 0},
+	}, got)
+
+	for _, data := range []string{
+		"100644 blob " + id2 + " 11 file.txt\x00",
+		"100644 blob " + id2 + "\tfile.txt\x00",
+		"100600 blob " + id2 + " 11\tfile.txt\x00",
+		"100644 blob bad 11\tfile.txt\x00",
+		"100644 blob " + id2 + " bad\tfile.txt\x00",
+	} {
+		_, err = parseTreeEntries(nil, []byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestRepository_TreeEntries(t *testing.T) {
+	tests := []struct {
+		name     string
+		subpath  string
+		opt      TreeEntriesOptions
+		expNames []string
+	}{
+		{
+			name:     "root",
+			expNames: []string{"README.txt", "run.sh", "src"},
+		},
+		{
+			name:     "subpath",
+			subpath:  "src/",
+			expNames: []string{"main.go"},
+		},
+		{
+			name:     "recursive",
+			opt:      TreeEntriesOptions{Recursive: true},
+			expNames: []string{"README.txt", "run.sh", "src/main.go"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entries, err := testrepo.TreeEntries("master", test.subpath, test.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			for _, e := range entries {
+				names = append(names, e.Name())
+			}
+			assert.Equal(t, test.expNames, names)
+		})
+	}
+
+	t.Run("size", func(t *testing.T) {
+		entries, err := testrepo.TreeEntries("master", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		blob, err := testrepo.CatFileBlob("master:README.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := blob.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, int64(len(p)), entries[0].Size())
+
+		// The content can be read from the entry.
+		content, err := entries[0].Blob().Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, p, content)
+	})
+
+	t.Run("path does not exist", func(t *testing.T) {
+		for _, subpath := range []string{"404", "README.txt"} {
+			_, err := testrepo.TreeEntries("master", subpath)
+			assert.Equal(t, ErrRevisionNotExist, err)
+		}
+	})
+}
+
+func TestRepository_MkTree(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	blobID, err := r.RevParse("master:README.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	subtreeID, err := r.RevParse("master:src")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Entries are given out of order.
+	treeID, err := r.MkTree([]MkTreeEntry{
+		{Mode: EntryTree, ID: subtreeID, Name: "src"},
+		{Mode: EntryExec, ID: blobID, Name: "run.sh"},
+		{Mode: EntryBlob, ID: blobID, Name: "README.txt"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := r.TreeEntries(treeID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, fmt.Sprintf("%06o %s %s", e.Mode(), e.ID(), e.Name()))
+	}
+	assert.Equal(t, []string{
+		"100644 " + blobID + " README.txt",
+		"100755 " + blobID + " run.sh",
+		"040000 " + subtreeID + " src",
+	}, got)
+
+	_, err = r.MkTree([]MkTreeEntry{{Mode: EntryBlob, ID: EmptyID, Name: "404.txt"}})
+	assert.Error(t, err)
+}
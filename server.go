@@ -5,6 +5,8 @@
 package git
 
 import (
+	"fmt"
+	"io"
 	"time"
 )
 
@@ -37,6 +39,14 @@ func UpdateServerInfo(path string, opts ...UpdateServerInfoOptions) error {
 	return err
 }
 
+// UpdateServerInfo updates the auxiliary info file on the server side for the
+// repository, i.e. "info/refs" and "objects/info/packs" that are required for
+// serving the repository over the dumb HTTP protocol. It should be called
+// whenever references or packs have been changed.
+func (r *Repository) UpdateServerInfo(opts ...UpdateServerInfoOptions) error {
+	return UpdateServerInfo(r.path, opts...)
+}
+
 // ReceivePackOptions contains optional arguments for receiving the info pushed
 // to the repository.
 //
@@ -111,3 +121,59 @@ func UploadPack(path string, opts ...UploadPackOptions) ([]byte, error) {
 	cmd.AddArgs(".")
 	return cmd.RunInDirWithTimeout(opt.Timeout, path)
 }
+
+// AdvertiseRefsOptions contains optional arguments for advertising references
+// to the client of the smart HTTP protocol.
+//
+// Docs: https://git-scm.com/docs/http-protocol#_smart_clients
+type AdvertiseRefsOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// AdvertiseRefs writes the reference advertisement of given service (either
+// "upload-pack" or "receive-pack") for the repository in given path to w, in the
+// form of a response body of the "info/refs" endpoint of the smart HTTP
+// protocol. That is, a pkt-line service header and a flush-pkt followed by the
+// output of the service.
+func AdvertiseRefs(path, service string, w io.Writer, opts ...AdvertiseRefsOptions) error {
+	var opt AdvertiseRefsOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	switch service {
+	case "upload-pack", "receive-pack":
+	default:
+		return fmt.Errorf("unsupported service: %s", service)
+	}
+
+	// A pkt-line is prefixed by its total length, including the 4 bytes of the
+	// length itself, in hexadecimal.
+	header := "# service=git-" + service + "\n"
+	_, err := fmt.Fprintf(w, "%04x%s0000", len(header)+4, header)
+	if err != nil {
+		return err
+	}
+
+	stderr := newTailBuffer(stderrLimit)
+	cmd := NewCommand(service).
+		AddOptions(opt.CommandOptions).
+		AddArgs("--stateless-rpc", "--advertise-refs", ".")
+	if err = cmd.RunInDirPipelineWithTimeout(opt.Timeout, w, stderr, path); err != nil {
+		return concatenateError(err, stderr.String())
+	}
+	return nil
+}
+
+// AdvertiseRefs writes the reference advertisement of given service (either
+// "upload-pack" or "receive-pack") for the repository to w. See AdvertiseRefs
+// for details.
+func (r *Repository) AdvertiseRefs(service string, w io.Writer, opts ...AdvertiseRefsOptions) error {
+	return AdvertiseRefs(r.path, service, w, opts...)
+}
@@ -5,8 +5,10 @@
 package git
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -21,6 +23,15 @@ func TestUpdateServerInfo(t *testing.T) {
 	assert.True(t, isFile(filepath.Join(repoPath, "info", "refs")))
 }
 
+func TestRepository_UpdateServerInfo(t *testing.T) {
+	err := os.RemoveAll(filepath.Join(repoPath, "info", "refs"))
+	require.NoError(t, err)
+	err = testrepo.UpdateServerInfo()
+	require.NoError(t, err)
+	assert.True(t, isFile(filepath.Join(repoPath, "info", "refs")))
+	assert.True(t, isFile(filepath.Join(repoPath, "objects", "info", "packs")))
+}
+
 func TestReceivePack(t *testing.T) {
 	got, err := ReceivePack(repoPath, ReceivePackOptions{HTTPBackendInfoRefs: true})
 	require.NoError(t, err)
@@ -40,3 +51,36 @@ func TestUploadPack(t *testing.T) {
 	const contains = "multi_ack thin-pack side-band side-band-64k ofs-delta shallow deepen-since deepen-not deepen-relative no-progress include-tag multi_ack_detailed no-done symref=HEAD:refs/heads/master object-format=sha1 agent=git/"
 	assert.Contains(t, string(got), contains)
 }
+
+func TestAdvertiseRefs(t *testing.T) {
+	t.Run("unsupported service", func(t *testing.T) {
+		err := AdvertiseRefs(repoPath, "bad-service", new(bytes.Buffer))
+		assert.Error(t, err)
+	})
+
+	tests := []struct {
+		service   string
+		expPrefix string
+	}{
+		{
+			service:   "upload-pack",
+			expPrefix: "001e# service=git-upload-pack\n0000",
+		},
+		{
+			service:   "receive-pack",
+			expPrefix: "001f# service=git-receive-pack\n0000",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.service, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := testrepo.AdvertiseRefs(test.service, &buf)
+			require.NoError(t, err)
+
+			got := buf.String()
+			assert.True(t, strings.HasPrefix(got, test.expPrefix), got)
+			assert.Contains(t, got, "refs/heads/master")
+			assert.True(t, strings.HasSuffix(got, "0000"), got)
+		})
+	}
+}
@@ -14,6 +14,10 @@ import (
 // EmptyID is an ID with empty SHA-1 hash.
 const EmptyID = "0000000000000000000000000000000000000000"
 
+// EmptyTreeID is the ID of the empty tree object, which is known to every
+// repository even if it does not exist on disk.
+const EmptyTreeID = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
 // SHA1 is the SHA-1 hash of a Git object.
 type SHA1 struct {
 	bytes [20]byte
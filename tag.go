@@ -4,6 +4,11 @@
 
 package git
 
+import (
+	"strings"
+	"time"
+)
+
 // Tag contains information of a Git tag.
 type Tag struct {
 	typ      ObjectType
@@ -12,6 +17,9 @@ type Tag struct {
 	refspec  string
 	tagger   *Signature
 	message  string
+	// The tagger date for an annotated tag, or the committer date of the commit
+	// for a lightweight tag. It is only set by Repository.ListTags.
+	date time.Time
 
 	repo *Repository
 }
@@ -36,6 +44,11 @@ func (t *Tag) Refspec() string {
 	return t.refspec
 }
 
+// Name returns the name of the tag, e.g. "v1.0.0".
+func (t *Tag) Name() string {
+	return strings.TrimPrefix(t.refspec, RefsTags)
+}
+
 // Tagger returns the tagger of the tag.
 func (t *Tag) Tagger() *Signature {
 	return t.tagger
@@ -20,6 +20,7 @@ func TestTag(t *testing.T) {
 	assert.Equal(t, "b39c8508bbc4b00ad2e24d358012ea123bcafd8d", tag.ID().String())
 	assert.Equal(t, "0eedd79eba4394bbef888c804e899731644367fe", tag.CommitID().String())
 	assert.Equal(t, "refs/tags/v1.1.0", tag.Refspec())
+	assert.Equal(t, "v1.1.0", tag.Name())
 
 	t.Run("Tagger", func(t *testing.T) {
 		assert.Equal(t, "Joe Chen", tag.Tagger().Name)
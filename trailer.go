@@ -0,0 +1,59 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bytes"
+	"strings"
+	"time"
+)
+
+// Well-known trailer tokens.
+const (
+	TrailerSignedOffBy  = "Signed-off-by"
+	TrailerCoAuthoredBy = "Co-authored-by"
+)
+
+// AppendTrailerOptions contains optional arguments for appending a trailer.
+//
+// Docs: https://git-scm.com/docs/git-interpret-trailers
+type AppendTrailerOptions struct {
+	// The timeout duration before giving up for each shell command execution. The
+	// default timeout duration will be used when not supplied.
+	//
+	// Deprecated: Use CommandOptions.Timeout instead.
+	Timeout time.Duration
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// AppendTrailer appends the trailer with given token and value, e.g.
+// TrailerSignedOffBy and "Alice <alice@example.com>", to the commit message
+// following the placement rules of Git. The trailer is not appended again if
+// the message already has the same trailer.
+func AppendTrailer(message, token, value string, opts ...AppendTrailerOptions) (string, error) {
+	var opt AppendTrailerOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	stdout := new(bytes.Buffer)
+	stderr := newTailBuffer(stderrLimit)
+	cmd := NewCommand("interpret-trailers").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--if-exists", "addIfDifferent", "--trailer", token+": "+value)
+	if opt.Timeout != 0 {
+		cmd = cmd.WithTimeout(opt.Timeout)
+	}
+	err := cmd.RunInDirWithOptions("", RunInDirOptions{
+		Stdin:  strings.NewReader(message),
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+	if err != nil {
+		return "", concatenateError(err, stderr.String())
+	}
+	return stdout.String(), nil
+}
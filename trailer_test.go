@@ -0,0 +1,55 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendTrailer(t *testing.T) {
+	const signoff = "Alice <alice@example.com>"
+	tests := []struct {
+		name       string
+		message    string
+		token      string
+		expMessage string
+	}{
+		{
+			name:       "subject only",
+			message:    "Fix bug\n",
+			token:      TrailerSignedOffBy,
+			expMessage: "Fix bug\n\nSigned-off-by: " + signoff + "\n",
+		},
+		{
+			name:       "with body",
+			message:    "Fix bug\n\nThe bug is fixed.\n",
+			token:      TrailerSignedOffBy,
+			expMessage: "Fix bug\n\nThe bug is fixed.\n\nSigned-off-by: " + signoff + "\n",
+		},
+		{
+			name:       "existing trailers",
+			message:    "Fix bug\n\nSigned-off-by: Bob <bob@example.com>\n",
+			token:      TrailerCoAuthoredBy,
+			expMessage: "Fix bug\n\nSigned-off-by: Bob <bob@example.com>\nCo-authored-by: " + signoff + "\n",
+		},
+		{
+			name:       "duplicated",
+			message:    "Fix bug\n\nSigned-off-by: " + signoff + "\n",
+			token:      TrailerSignedOffBy,
+			expMessage: "Fix bug\n\nSigned-off-by: " + signoff + "\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			message, err := AppendTrailer(test.message, test.token, signoff)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, test.expMessage, message)
+		})
+	}
+}
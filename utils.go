@@ -5,8 +5,9 @@
 package git
 
 import (
-	"fmt"
+	"errors"
 	"os"
+	"os/exec"
 	"strings"
 	"sync"
 )
@@ -39,6 +40,13 @@ func (oc *objectCache) Get(id string) (interface{}, bool) {
 	return obj, has
 }
 
+func (oc *objectCache) Clear() {
+	oc.lock.Lock()
+	defer oc.lock.Unlock()
+
+	oc.cache = make(map[string]interface{})
+}
+
 // isDir returns true if given path is a directory, or returns false when it's a
 // file or does not exist.
 func isDir(dir string) bool {
@@ -70,7 +78,52 @@ func concatenateError(err error, stderr string) error {
 	if len(stderr) == 0 {
 		return err
 	}
-	return fmt.Errorf("%v - %s", err, stderr)
+	return &CommandError{
+		Err:    err,
+		Stderr: stderr,
+	}
+}
+
+// isExitCode returns true if the command failed with given exit code and
+// without any error output, which Git uses to report a negative result rather
+// than a failure, e.g. "git merge-base --is-ancestor".
+func isExitCode(err error, code int) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != code {
+		return false
+	}
+	var cmdErr *CommandError
+	return !errors.As(err, &cmdErr)
+}
+
+// tailBuffer is a buffer that only keeps the last bytes written to it up to the
+// limit. A non-positive limit means no limit.
+type tailBuffer struct {
+	limit int
+	buf   []byte
+}
+
+func newTailBuffer(limit int) *tailBuffer {
+	return &tailBuffer{limit: limit}
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if b.limit > 0 && len(p) >= b.limit {
+		b.buf = append(b.buf[:0], p[len(p)-b.limit:]...)
+		return n, nil
+	}
+
+	b.buf = append(b.buf, p...)
+	if over := len(b.buf) - b.limit; b.limit > 0 && over > 0 {
+		copy(b.buf, b.buf[over:])
+		b.buf = b.buf[:b.limit]
+	}
+	return n, nil
+}
+
+func (b *tailBuffer) String() string {
+	return string(b.buf)
 }
 
 // bytesToStrings splits given bytes into strings by line separator ("\n"). It